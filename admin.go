@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"dealer/internal/models"
+	"dealer/internal/service"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const adminUsage = `usage:
+  dealer dlq list [-limit n]              print the dead-lettered messages
+  dealer dlq replay [-limit n]            publish the dead-lettered messages to the order queue again
+  dealer candles backfill [-symbol name]  rebuild the candles from the deals of the symbol or of all symbols
+  dealer users create -name name [-admin] create a user and print its API key and secret
+  dealer balances deposit -user id -asset name -amount n
+                                          credit an amount of an asset to the available funds of a user`
+
+func runAdmin(args []string, deadLetter service.DeadLetterInterface, candleBackfill service.CandleBackfillInterface, users service.UserServiceInterface, ledger service.LedgerInterface) error {
+	if len(args) < 2 {
+		return errors.New(adminUsage)
+	}
+
+	switch args[0] {
+	case "dlq":
+		return runDeadLetter(args[1:], deadLetter)
+	case "candles":
+		return runCandles(args[1:], candleBackfill)
+	case "users":
+		return runUsers(args[1:], users)
+	case "balances":
+		return runBalances(args[1:], ledger)
+	default:
+		return errors.New(adminUsage)
+	}
+}
+
+func runDeadLetter(args []string, deadLetter service.DeadLetterInterface) error {
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	limit := flags.Int("limit", 100, "maximum number of messages")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "list":
+		deadLetters, err := deadLetter.List(ctx, *limit)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		for _, d := range deadLetters {
+			if err := encoder.Encode(d); err != nil {
+				return err
+			}
+		}
+	case "replay":
+		n, err := deadLetter.Replay(ctx, *limit)
+		fmt.Printf("replayed %d messages\n", n)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New(adminUsage)
+	}
+
+	return nil
+}
+
+func runCandles(args []string, candleBackfill service.CandleBackfillInterface) error {
+	if args[0] != "backfill" {
+		return errors.New(adminUsage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	symbol := flags.String("symbol", "", "symbol to rebuild, all symbols if empty")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	n, err := candleBackfill.Backfill(context.Background(), *symbol)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("aggregated %d deals\n", n)
+	return nil
+}
+
+func runUsers(args []string, users service.UserServiceInterface) error {
+	if args[0] != "create" {
+		return errors.New(adminUsage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	name := flags.String("name", "", "name of the user")
+	admin := flags.Bool("admin", false, "allow the user to call the admin API")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New(adminUsage)
+	}
+
+	user, err := users.Create(context.Background(), *name, *admin)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id: %d\napi key: %s\napi secret: %s\n", user.ID, user.APIKey, user.APISecret)
+	return nil
+}
+
+func runBalances(args []string, ledger service.LedgerInterface) error {
+	if args[0] != "deposit" {
+		return errors.New(adminUsage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	userID := flags.Int64("user", 0, "ID of the user")
+	asset := flags.String("asset", "", "asset to deposit")
+	amount := flags.String("amount", "", "amount to deposit")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *userID == models.SystemUserID || *asset == "" {
+		return errors.New(adminUsage)
+	}
+
+	value, err := models.ParseDecimal(*amount)
+	if err != nil {
+		return err
+	}
+
+	if err := ledger.Deposit(context.Background(), *userID, *asset, value); err != nil {
+		return err
+	}
+
+	fmt.Printf("deposited %s %s to user %d\n", value, *asset, *userID)
+	return nil
+}
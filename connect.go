@@ -19,7 +19,11 @@ func newMySQL(config configmanager.DatabaseConfig) (*gorm.DB, error) {
 	}), &gorm.Config{})
 }
 
-func newMessageQueue(config configmanager.MessageQueueConfig) (*amqp.Channel, error) {
+// newMessageQueue connects to RabbitMQ and declares the order queue with its
+// dead letter queue. The publishers and the consumer open their own channels
+// on the connection, so the acks of the consumer never share a channel with
+// the publisher confirms.
+func newMessageQueue(config configmanager.MessageQueueConfig) (*amqp.Connection, error) {
 	conn, err := amqp.Dial(config.URL)
 	if err != nil {
 		return nil, err
@@ -29,8 +33,36 @@ func newMessageQueue(config configmanager.MessageQueueConfig) (*amqp.Channel, er
 	if err != nil {
 		return nil, err
 	}
+	defer ch.Close()
 
-	if _, err := ch.QueueDeclare(config.QueueName, false, false, false, false, nil); err != nil {
+	if err := ch.ExchangeDeclare(config.DeadLetterExchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		return nil, err
+	}
+
+	if _, err := ch.QueueDeclare(config.DeadLetterQueue, true, false, false, false, nil); err != nil {
+		return nil, err
+	}
+
+	if err := ch.QueueBind(config.DeadLetterQueue, config.QueueName, config.DeadLetterExchange, false, nil); err != nil {
+		return nil, err
+	}
+
+	args := amqp.Table{"x-dead-letter-exchange": config.DeadLetterExchange}
+	if _, err := ch.QueueDeclare(config.QueueName, true, false, false, false, args); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// newPublishChannel opens a channel in confirm mode for a publisher.
+func newPublishChannel(conn *amqp.Connection) (*amqp.Channel, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
 		return nil, err
 	}
 
@@ -1,10 +1,22 @@
 CREATE TABLE deal.`deal` (
 	id INT auto_increment NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
     taker_order_id INT NOT NULL,
     maker_order_id INT NOT NULL,
+    taker_user_id INT NOT NULL,
+    maker_user_id INT NOT NULL,
     quantity INT UNSIGNED NOT NULL,
-    price FLOAT NOT NULL,
-	CONSTRAINT deal_PK PRIMARY KEY (id)
+    price BIGINT NOT NULL COMMENT 'scaled by 1e8',
+    taker_fee BIGINT NOT NULL DEFAULT 0 COMMENT 'scaled by 1e8',
+    taker_fee_asset VARCHAR(16) NOT NULL DEFAULT '',
+    maker_fee BIGINT NOT NULL DEFAULT 0 COMMENT 'scaled by 1e8',
+    maker_fee_asset VARCHAR(16) NOT NULL DEFAULT '',
+	created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	CONSTRAINT deal_PK PRIMARY KEY (id),
+	INDEX deal_symbol_IDX (symbol, created_at),
+	INDEX deal_created_at_IDX (created_at),
+	INDEX deal_taker_order_id_IDX (taker_order_id),
+	INDEX deal_maker_order_id_IDX (maker_order_id)
 )
 ENGINE=InnoDB
 DEFAULT CHARSET=utf8mb4
@@ -12,13 +24,151 @@ COLLATE=utf8mb4_0900_ai_ci;
 
 CREATE TABLE deal.`order` (
 	id INT auto_increment NOT NULL,
+	user_id INT NOT NULL,
+	symbol VARCHAR(32) NOT NULL,
 	order_type INT NOT NULL COMMENT '1: buy, 2: sell',
 	quantity INT UNSIGNED NOT NULL,
 	remain_quantity INT UNSIGNED NOT NULL,
-	price_type INT NOT NULL COMMENT '1: market, 2: limit',
-	price FLOAT NOT NULL,
+	price_type INT NOT NULL COMMENT '1: limit, 2: market, 3: stop limit, 4: stop market',
+	price BIGINT NOT NULL COMMENT 'scaled by 1e8',
 	is_cancel BOOL NOT NULL DEFAULT FALSE,
-	CONSTRAINT order_PK PRIMARY KEY (id)
+	status INT NOT NULL DEFAULT 1 COMMENT '1: new, 2: partially filled, 3: filled, 4: cancelled, 5: rejected',
+	time_in_force INT NOT NULL DEFAULT 1 COMMENT '1: GTC, 2: IOC, 3: FOK, 4: GTD',
+	expire_at DATETIME NULL,
+	stop_price BIGINT NOT NULL DEFAULT 0 COMMENT 'scaled by 1e8',
+	triggered_at DATETIME NULL,
+	budget BIGINT NOT NULL DEFAULT 0 COMMENT 'scaled by 1e8, quote funds still locked for market buy',
+	self_trade_prevention INT NOT NULL DEFAULT 1 COMMENT '1: cancel newest, 2: cancel oldest, 3: cancel both, 4: decrement and cancel',
+	display_quantity INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'shown slice of iceberg order, 0: not iceberg',
+	post_only INT NOT NULL DEFAULT 0 COMMENT '1: reject, 2: reprice, 0: not post-only',
+	min_quantity INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'quantity filled when the order enters the book, 0: none',
+	priority BIGINT NOT NULL DEFAULT 0 COMMENT 'queue position the dealer assigns when the order enters the book, 0: not queued yet',
+	sequence BIGINT NOT NULL DEFAULT 0 COMMENT 'sequence of the last message applied to the order',
+	created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	CONSTRAINT order_PK PRIMARY KEY (id),
+	INDEX order_user_id_IDX (user_id, id),
+	INDEX order_symbol_IDX (symbol),
+	INDEX order_status_IDX (status),
+	INDEX order_created_at_IDX (created_at)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`order_amendment` (
+	id INT auto_increment NOT NULL,
+	order_id INT NOT NULL,
+	symbol VARCHAR(32) NOT NULL,
+	old_price BIGINT NOT NULL COMMENT 'scaled by 1e8',
+	price BIGINT NOT NULL COMMENT 'scaled by 1e8',
+	old_quantity INT UNSIGNED NOT NULL,
+	quantity INT UNSIGNED NOT NULL,
+	reject_reason VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'why the consumer rejected the amendment, empty when applied',
+	created_at DATETIME(3) NOT NULL,
+	CONSTRAINT order_amendment_PK PRIMARY KEY (id),
+	INDEX order_amendment_order_id_IDX (order_id)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`order_cancellation` (
+	id INT auto_increment NOT NULL,
+	order_id INT NOT NULL,
+	symbol VARCHAR(32) NOT NULL,
+	user_id INT NOT NULL,
+	quantity INT UNSIGNED NOT NULL,
+	reason VARCHAR(32) NOT NULL COMMENT 'user, expired, ioc, fok, insufficient_funds, self_trade, post_only, min_quantity, unfilled, slippage or admin',
+	created_at DATETIME(3) NOT NULL,
+	CONSTRAINT order_cancellation_PK PRIMARY KEY (id),
+	INDEX order_cancellation_order_id_IDX (order_id)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`market_state` (
+	symbol VARCHAR(32) NOT NULL,
+	phase VARCHAR(32) NOT NULL COMMENT 'pre_open, auction, continuous, closing_auction, closed, halted or volatility_auction',
+	resume_at DATETIME(3) NULL COMMENT 'end of the cooldown of a halted market',
+	resume_phase VARCHAR(32) NOT NULL DEFAULT '' COMMENT 'phase a market halted by an operator resumes in, continuous when empty',
+	sequence BIGINT NOT NULL DEFAULT 0 COMMENT 'sequence of the last market message applied to the market',
+	reference_price BIGINT NOT NULL DEFAULT 0 COMMENT 'price of the last auction, scaled by 1e8, 0 before the first auction',
+	updated_at DATETIME(3) NOT NULL,
+	CONSTRAINT market_state_PK PRIMARY KEY (symbol)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`outbox` (
+	id BIGINT auto_increment NOT NULL,
+	sequence BIGINT NOT NULL DEFAULT 0 COMMENT 'assigned by the relay in the order the rows become visible, 0 until then',
+	payload BLOB NOT NULL COMMENT 'json message published to the order queue',
+	attempts INT NOT NULL DEFAULT 0,
+	sent_at DATETIME(3) NULL,
+	created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	CONSTRAINT outbox_PK PRIMARY KEY (id),
+	INDEX outbox_sent_at_IDX (sent_at),
+	INDEX outbox_sequence_IDX (sequence)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`candle` (
+	symbol VARCHAR(32) NOT NULL,
+	`interval` VARCHAR(8) NOT NULL COMMENT '1m, 5m, 1h or 1d',
+	open_time DATETIME NOT NULL,
+	open BIGINT NOT NULL COMMENT 'scaled by 1e8',
+	high BIGINT NOT NULL COMMENT 'scaled by 1e8',
+	low BIGINT NOT NULL COMMENT 'scaled by 1e8',
+	close BIGINT NOT NULL COMMENT 'scaled by 1e8',
+	volume BIGINT UNSIGNED NOT NULL,
+	CONSTRAINT candle_PK PRIMARY KEY (symbol, `interval`, open_time)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`user` (
+	id INT auto_increment NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	api_key VARCHAR(64) NOT NULL,
+	api_secret VARCHAR(128) NOT NULL,
+	is_admin BOOL NOT NULL DEFAULT FALSE,
+	created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	CONSTRAINT user_PK PRIMARY KEY (id),
+	CONSTRAINT user_api_key_UN UNIQUE KEY (api_key)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`balance` (
+	user_id INT NOT NULL COMMENT '0: system account',
+	asset VARCHAR(16) NOT NULL,
+	available BIGINT NOT NULL DEFAULT 0 COMMENT 'scaled by 1e8',
+	locked BIGINT NOT NULL DEFAULT 0 COMMENT 'scaled by 1e8',
+	CONSTRAINT balance_PK PRIMARY KEY (user_id, asset)
+)
+ENGINE=InnoDB
+DEFAULT CHARSET=utf8mb4
+COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE deal.`ledger` (
+	id INT auto_increment NOT NULL,
+	user_id INT NOT NULL,
+	asset VARCHAR(16) NOT NULL,
+	bucket VARCHAR(16) NOT NULL COMMENT 'available or locked',
+	amount BIGINT NOT NULL COMMENT 'scaled by 1e8, negative when moved out of the bucket',
+	reason VARCHAR(16) NOT NULL COMMENT 'deposit, lock, release, trade or fee',
+	order_id INT NOT NULL DEFAULT 0,
+	deal_id INT NOT NULL DEFAULT 0,
+	created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	CONSTRAINT ledger_PK PRIMARY KEY (id),
+	INDEX ledger_user_id_IDX (user_id, asset, id),
+	INDEX ledger_order_id_IDX (order_id)
 )
 ENGINE=InnoDB
 DEFAULT CHARSET=utf8mb4
@@ -2,6 +2,7 @@ package configmanager
 
 import (
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap/zapcore"
@@ -13,7 +14,16 @@ type Config struct {
 	HTTPServer   HTTPServerConfig
 	Database     DatabaseConfig
 	MessageQueue MessageQueueConfig
+	Consumer     ConsumerConfig
 	Logger       LoggerConfig
+	Sweeper      SweeperConfig
+	Outbox       OutboxConfig
+	MarketData   MarketDataConfig
+	Candle       CandleConfig
+	Auth         AuthConfig
+	Fee          FeeConfig
+	Schedule     ScheduleConfig
+	Instruments  []InstrumentConfig
 }
 
 type HTTPServerConfig struct {
@@ -26,14 +36,89 @@ type DatabaseConfig struct {
 }
 
 type MessageQueueConfig struct {
-	URL       string
-	QueueName string
+	URL                string
+	QueueName          string
+	DeadLetterExchange string
+	DeadLetterQueue    string
+}
+
+type ConsumerConfig struct {
+	Prefetch    int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
 }
 
 type LoggerConfig struct {
 	Level zapcore.Level
 }
 
+type SweeperConfig struct {
+	Interval time.Duration
+}
+
+type OutboxConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+type MarketDataConfig struct {
+	Depth      int
+	BufferSize int
+}
+
+type CandleConfig struct {
+	BackfillBatchSize int
+}
+
+type AuthConfig struct {
+	Window time.Duration
+}
+
+type FeeConfig struct {
+	MakerRate       string
+	TakerRate       string
+	RefreshInterval time.Duration
+	Tiers           []FeeTierConfig
+}
+
+type FeeTierConfig struct {
+	Volume    string
+	MakerRate string
+	TakerRate string
+}
+
+type ScheduleConfig struct {
+	Enabled        bool
+	Interval       time.Duration
+	Location       string
+	PreOpen        string
+	Auction        string
+	Continuous     string
+	ClosingAuction string
+	Close          string
+}
+
+type InstrumentConfig struct {
+	Symbol         string
+	BaseAsset      string
+	QuoteAsset     string
+	TickSize       string
+	LotSize        uint
+	ReferencePrice string
+	MaxSlippage    string
+	MaxPrice       string
+	MaxQuantity    uint
+	PriceBand      PriceBandConfig
+}
+
+type PriceBandConfig struct {
+	Static   string
+	Dynamic  string
+	Breaker  string
+	Cooldown time.Duration
+}
+
 func Get() (*Config, error) {
 	if config == nil {
 		c, err := get()
@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"dealer/internal/models"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type AmendmentInterface interface {
+	Insert(context.Context, *gorm.DB, []*models.Amendment) error
+	List(context.Context, *gorm.DB, int64) ([]*models.Amendment, error)
+}
+
+type Amendment struct{}
+
+var _ AmendmentInterface = (*Amendment)(nil)
+
+func NewAmendment() *Amendment {
+	return &Amendment{}
+}
+
+func (a *Amendment) Insert(ctx context.Context, tx *gorm.DB, amendments []*models.Amendment) error {
+	if len(amendments) == 0 {
+		return nil
+	}
+
+	return tx.WithContext(ctx).Create(&amendments).Error
+}
+
+func (a *Amendment) List(ctx context.Context, tx *gorm.DB, orderID int64) ([]*models.Amendment, error) {
+	var amendments []*models.Amendment
+	if err := tx.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&amendments).Error; err != nil {
+		return nil, err
+	}
+
+	return amendments, nil
+}
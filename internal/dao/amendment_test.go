@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type AmendmentTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *AmendmentTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *AmendmentTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestAmendmentTestSuite(t *testing.T) {
+	suite.Run(t, new(AmendmentTestSuite))
+}
+
+func (t *AmendmentTestSuite) TestInsert() {
+	tests := []struct {
+		name       string
+		amendments []*models.Amendment
+		fn         func()
+		hasError   bool
+	}{
+		{
+			name: "Insert amendments success",
+			amendments: []*models.Amendment{
+				{OrderID: 1, Symbol: "BTCUSD", OldPrice: 10, Price: 11, OldQuantity: 2, Quantity: 2},
+			},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order_amendment` (`order_id`,`symbol`,`old_price`,`price`,`old_quantity`,`quantity`,`reject_reason`,`created_at`) VALUES (?,?,?,?,?,?,?,?)")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name:       "Insert amendments no amendment",
+			amendments: nil,
+			fn:         func() {},
+			hasError:   false,
+		},
+		{
+			name: "Insert amendments failed",
+			amendments: []*models.Amendment{
+				{OrderID: 1, Symbol: "BTCUSD", OldPrice: 10, Price: 11, OldQuantity: 2, Quantity: 2},
+			},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order_amendment` (`order_id`,`symbol`,`old_price`,`price`,`old_quantity`,`quantity`,`reject_reason`,`created_at`) VALUES (?,?,?,?,?,?,?,?)")).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewAmendment().Insert(context.Background(), t.mockGormDB, test.amendments)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *AmendmentTestSuite) TestList() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Amendment
+		hasError bool
+	}{
+		{
+			name: "List amendments success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_amendment` WHERE order_id = ? ORDER BY id")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "symbol", "old_price", "price", "old_quantity", "quantity"}).
+						AddRow(1, 1, "BTCUSD", 10, 11, 2, 2))
+			},
+			expected: []*models.Amendment{
+				{ID: 1, OrderID: 1, Symbol: "BTCUSD", OldPrice: 10, Price: 11, OldQuantity: 2, Quantity: 2},
+			},
+			hasError: false,
+		},
+		{
+			name: "List amendments failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_amendment` WHERE order_id = ? ORDER BY id")).
+					WithArgs(1).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			amendments, err := NewAmendment().List(context.Background(), t.mockGormDB, 1)
+			t.Equal(test.expected, amendments)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"dealer/internal/models"
+	"errors"
+	"sort"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BalanceInterface interface {
+	Get(context.Context, *gorm.DB, int64, string) (*models.Balance, error)
+	List(context.Context, *gorm.DB, int64) ([]*models.Balance, error)
+	Lock(context.Context, *gorm.DB, int64, string, models.Decimal) (bool, error)
+	Add(context.Context, *gorm.DB, []*models.Balance) error
+}
+
+type Balance struct{}
+
+var _ BalanceInterface = (*Balance)(nil)
+
+func NewBalance() *Balance {
+	return &Balance{}
+}
+
+func (b *Balance) Get(ctx context.Context, tx *gorm.DB, userID int64, asset string) (*models.Balance, error) {
+	var balance *models.Balance
+	if err := tx.WithContext(ctx).Where("user_id = ? AND asset = ?", userID, asset).Take(&balance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+func (b *Balance) List(ctx context.Context, tx *gorm.DB, userID int64) ([]*models.Balance, error) {
+	var balances []*models.Balance
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("asset").Find(&balances).Error; err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// Lock moves amount from the available to the locked funds of the balance,
+// and reports false without any change when the available funds are less
+// than amount.
+func (b *Balance) Lock(ctx context.Context, tx *gorm.DB, userID int64, asset string, amount models.Decimal) (bool, error) {
+	result := tx.WithContext(ctx).
+		Model(&models.Balance{}).
+		Where("user_id = ? AND asset = ? AND available >= ?", userID, asset, amount).
+		Updates(map[string]interface{}{
+			"available": gorm.Expr("available - ?", amount),
+			"locked":    gorm.Expr("locked + ?", amount),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Add adds the available and locked amounts of the deltas to the balances,
+// creating the missing ones. The rows are written in key order so concurrent
+// transactions do not deadlock.
+func (b *Balance) Add(ctx context.Context, tx *gorm.DB, deltas []*models.Balance) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].UserID != deltas[j].UserID {
+			return deltas[i].UserID < deltas[j].UserID
+		}
+		return deltas[i].Asset < deltas[j].Asset
+	})
+
+	return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"available": gorm.Expr("available + VALUES(available)"),
+			"locked":    gorm.Expr("locked + VALUES(locked)"),
+		}),
+	}).Create(&deltas).Error
+}
@@ -0,0 +1,223 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type BalanceTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *BalanceTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *BalanceTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestBalanceTestSuite(t *testing.T) {
+	suite.Run(t, new(BalanceTestSuite))
+}
+
+func (t *BalanceTestSuite) TestGet() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.Balance
+		hasError bool
+	}{
+		{
+			name: "Get balance success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `balance` WHERE user_id = ? AND asset = ? LIMIT 1")).
+					WithArgs(1, "USD").
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "asset", "available", "locked"}).AddRow(1, "USD", 10, 5))
+			},
+			expected: &models.Balance{UserID: 1, Asset: "USD", Available: 10, Locked: 5},
+			hasError: false,
+		},
+		{
+			name: "Get balance not found",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `balance` WHERE user_id = ? AND asset = ? LIMIT 1")).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+			},
+			expected: nil,
+			hasError: false,
+		},
+		{
+			name: "Get balance failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `balance` WHERE user_id = ? AND asset = ? LIMIT 1")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewBalance().Get(context.Background(), t.mockGormDB, 1, "USD")
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *BalanceTestSuite) TestList() {
+	t.mockDB.
+		ExpectQuery(regexp.QuoteMeta("SELECT * FROM `balance` WHERE user_id = ? ORDER BY asset")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "asset", "available", "locked"}).AddRow(1, "BTC", 1, 0).AddRow(1, "USD", 10, 5))
+
+	actual, err := NewBalance().List(context.Background(), t.mockGormDB, 1)
+	t.NoError(err)
+	t.Equal([]*models.Balance{
+		{UserID: 1, Asset: "BTC", Available: 1},
+		{UserID: 1, Asset: "USD", Available: 10, Locked: 5},
+	}, actual)
+}
+
+func (t *BalanceTestSuite) TestLock() {
+	lockSQL := "UPDATE `balance` SET `available`=available - ?,`locked`=locked + ? WHERE user_id = ? AND asset = ? AND available >= ?"
+	tests := []struct {
+		name     string
+		fn       func()
+		expected bool
+		hasError bool
+	}{
+		{
+			name: "Lock balance success",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(lockSQL)).
+					WithArgs(10, 10, 1, "USD", 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				t.mockDB.ExpectCommit()
+			},
+			expected: true,
+			hasError: false,
+		},
+		{
+			name: "Lock balance insufficient",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(lockSQL)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				t.mockDB.ExpectCommit()
+			},
+			expected: false,
+			hasError: false,
+		},
+		{
+			name: "Lock balance failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(lockSQL)).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			expected: false,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewBalance().Lock(context.Background(), t.mockGormDB, 1, "USD", 10)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *BalanceTestSuite) TestAdd() {
+	addSQL := "INSERT INTO `balance` (`user_id`,`asset`,`available`,`locked`) VALUES (?,?,?,?),(?,?,?,?) ON DUPLICATE KEY UPDATE `available`=available + VALUES(available),`locked`=locked + VALUES(locked)"
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Add balances in key order",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(addSQL)).
+					WithArgs(1, "USD", 10, 0, 2, "USD", -10, 0).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Add balances failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(addSQL)).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewBalance().Add(context.Background(), t.mockGormDB, []*models.Balance{
+				{UserID: 2, Asset: "USD", Available: -10},
+				{UserID: 1, Asset: "USD", Available: 10},
+			})
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
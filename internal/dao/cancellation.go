@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"dealer/internal/models"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type CancellationInterface interface {
+	Insert(context.Context, *gorm.DB, []*models.Cancellation) error
+	List(context.Context, *gorm.DB, int64) ([]*models.Cancellation, error)
+}
+
+type Cancellation struct{}
+
+var _ CancellationInterface = (*Cancellation)(nil)
+
+func NewCancellation() *Cancellation {
+	return &Cancellation{}
+}
+
+func (c *Cancellation) Insert(ctx context.Context, tx *gorm.DB, cancellations []*models.Cancellation) error {
+	if len(cancellations) == 0 {
+		return nil
+	}
+
+	return tx.WithContext(ctx).Create(&cancellations).Error
+}
+
+func (c *Cancellation) List(ctx context.Context, tx *gorm.DB, orderID int64) ([]*models.Cancellation, error) {
+	var cancellations []*models.Cancellation
+	if err := tx.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&cancellations).Error; err != nil {
+		return nil, err
+	}
+
+	return cancellations, nil
+}
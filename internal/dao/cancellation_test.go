@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type CancellationTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *CancellationTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *CancellationTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestCancellationTestSuite(t *testing.T) {
+	suite.Run(t, new(CancellationTestSuite))
+}
+
+func (t *CancellationTestSuite) TestInsert() {
+	tests := []struct {
+		name          string
+		cancellations []*models.Cancellation
+		fn            func()
+		hasError      bool
+	}{
+		{
+			name: "Insert cancellations success",
+			cancellations: []*models.Cancellation{
+				{OrderID: 1, Symbol: "BTCUSD", UserID: 2, Quantity: 3, Reason: models.CancelReasonSelfTrade},
+			},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order_cancellation` (`order_id`,`symbol`,`user_id`,`quantity`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name:          "Insert cancellations no cancellation",
+			cancellations: nil,
+			fn:            func() {},
+			hasError:      false,
+		},
+		{
+			name: "Insert cancellations failed",
+			cancellations: []*models.Cancellation{
+				{OrderID: 1, Symbol: "BTCUSD", UserID: 2, Quantity: 3, Reason: models.CancelReasonSelfTrade},
+			},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order_cancellation` (`order_id`,`symbol`,`user_id`,`quantity`,`reason`,`created_at`) VALUES (?,?,?,?,?,?)")).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewCancellation().Insert(context.Background(), t.mockGormDB, test.cancellations)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *CancellationTestSuite) TestList() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Cancellation
+		hasError bool
+	}{
+		{
+			name: "List cancellations success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_cancellation` WHERE order_id = ? ORDER BY id")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "symbol", "user_id", "quantity", "reason"}).
+						AddRow(1, 1, "BTCUSD", 2, 3, "self_trade"))
+			},
+			expected: []*models.Cancellation{
+				{ID: 1, OrderID: 1, Symbol: "BTCUSD", UserID: 2, Quantity: 3, Reason: models.CancelReasonSelfTrade},
+			},
+			hasError: false,
+		},
+		{
+			name: "List cancellations failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_cancellation` WHERE order_id = ? ORDER BY id")).
+					WithArgs(1).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			cancellations, err := NewCancellation().List(context.Background(), t.mockGormDB, 1)
+			t.Equal(test.expected, cancellations)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package dao
+
+import (
+	"dealer/internal/models"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CandleInterface interface {
+	Upsert(context.Context, *gorm.DB, []*models.Candle) error
+	List(context.Context, *gorm.DB, *models.CandleQuery) ([]*models.Candle, error)
+	Delete(context.Context, *gorm.DB, string) error
+}
+
+type Candle struct{}
+
+var _ CandleInterface = (*Candle)(nil)
+
+func NewCandle() *Candle {
+	return &Candle{}
+}
+
+// Upsert inserts the candles or merges them into the stored candles of the
+// same period. The given candles must be of deals executed after the deals of
+// the stored ones, as the stored open is kept and the close is replaced.
+func (c *Candle) Upsert(ctx context.Context, tx *gorm.DB, candles []*models.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"high":   gorm.Expr("GREATEST(high, VALUES(high))"),
+			"low":    gorm.Expr("LEAST(low, VALUES(low))"),
+			"close":  gorm.Expr("VALUES(close)"),
+			"volume": gorm.Expr("volume + VALUES(volume)"),
+		}),
+	}).Create(&candles).Error
+}
+
+func (c *Candle) List(ctx context.Context, tx *gorm.DB, query *models.CandleQuery) ([]*models.Candle, error) {
+	db := tx.WithContext(ctx).Where("symbol = ? AND `interval` = ?", query.Symbol, query.Interval)
+	if query.From != nil {
+		db = db.Where("open_time >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("open_time < ?", *query.To)
+	}
+
+	var candles []*models.Candle
+	if err := db.Order("open_time").Limit(query.Limit).Find(&candles).Error; err != nil {
+		return nil, err
+	}
+
+	return candles, nil
+}
+
+// Delete removes the candles of the symbol, or of all symbols when it is
+// empty.
+func (c *Candle) Delete(ctx context.Context, tx *gorm.DB, symbol string) error {
+	db := tx.WithContext(ctx)
+	if symbol == "" {
+		db = db.Where("1 = 1")
+	} else {
+		db = db.Where("symbol = ?", symbol)
+	}
+
+	return db.Delete(&models.Candle{}).Error
+}
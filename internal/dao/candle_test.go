@@ -0,0 +1,219 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type CandleTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *CandleTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *CandleTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestCandleTestSuite(t *testing.T) {
+	suite.Run(t, new(CandleTestSuite))
+}
+
+func (t *CandleTestSuite) TestUpsert() {
+	upsertSQL := "INSERT INTO `candle` (`symbol`,`interval`,`open_time`,`open`,`high`,`low`,`close`,`volume`) VALUES (?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `close`=VALUES(close),`high`=GREATEST(high, VALUES(high)),`low`=LEAST(low, VALUES(low)),`volume`=volume + VALUES(volume)"
+	tests := []struct {
+		name     string
+		candles  []*models.Candle
+		fn       func()
+		hasError bool
+	}{
+		{
+			name:    "Upsert candles success",
+			candles: []*models.Candle{{Symbol: "BTCUSD", Interval: models.CandleInterval1m}},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.ExpectExec(regexp.QuoteMeta(upsertSQL)).WillReturnResult(sqlmock.NewResult(0, 1))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name:     "Upsert candles no candle",
+			candles:  nil,
+			fn:       func() {},
+			hasError: false,
+		},
+		{
+			name:    "Upsert candles failed",
+			candles: []*models.Candle{{Symbol: "BTCUSD", Interval: models.CandleInterval1m}},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.ExpectExec(regexp.QuoteMeta(upsertSQL)).WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewCandle().Upsert(context.Background(), t.mockGormDB, test.candles)
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *CandleTestSuite) TestList() {
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	tests := []struct {
+		name     string
+		query    *models.CandleQuery
+		fn       func()
+		expected []*models.Candle
+		hasError bool
+	}{
+		{
+			name:  "List candles success",
+			query: &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1m, From: &from, To: &to, Limit: 10},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `candle` WHERE (symbol = ? AND `interval` = ?) AND open_time >= ? AND open_time < ? ORDER BY open_time LIMIT 10")).
+					WithArgs("BTCUSD", models.CandleInterval1m, from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"symbol", "interval", "open_time", "open", "high", "low", "close", "volume"}).
+						AddRow("BTCUSD", "1m", from, 10, 12, 9, 11, 5))
+			},
+			expected: []*models.Candle{
+				{Symbol: "BTCUSD", Interval: models.CandleInterval1m, OpenTime: from, Open: 10, High: 12, Low: 9, Close: 11, Volume: 5},
+			},
+			hasError: false,
+		},
+		{
+			name:  "List candles without time range",
+			query: &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1h, Limit: 10},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `candle` WHERE symbol = ? AND `interval` = ? ORDER BY open_time LIMIT 10")).
+					WithArgs("BTCUSD", models.CandleInterval1h).
+					WillReturnRows(sqlmock.NewRows([]string{"symbol"}))
+			},
+			expected: []*models.Candle{},
+			hasError: false,
+		},
+		{
+			name:  "List candles failed",
+			query: &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1h, Limit: 10},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `candle` WHERE symbol = ? AND `interval` = ? ORDER BY open_time LIMIT 10")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewCandle().List(context.Background(), t.mockGormDB, test.query)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *CandleTestSuite) TestDelete() {
+	tests := []struct {
+		name     string
+		symbol   string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name:   "Delete candles of symbol",
+			symbol: "BTCUSD",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("DELETE FROM `candle` WHERE symbol = ?")).
+					WithArgs("BTCUSD").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name:   "Delete candles of all symbols",
+			symbol: "",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("DELETE FROM `candle` WHERE 1 = 1")).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name:   "Delete candles failed",
+			symbol: "BTCUSD",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("DELETE FROM `candle` WHERE symbol = ?")).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewCandle().Delete(context.Background(), t.mockGormDB, test.symbol)
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,6 +2,8 @@ package dao
 
 import (
 	"dealer/internal/models"
+	"errors"
+	"time"
 
 	"golang.org/x/net/context"
 	"gorm.io/gorm"
@@ -10,6 +12,10 @@ import (
 type DealInterface interface {
 	Insert(context.Context, *gorm.DB, []*models.Deal) error
 	List(context.Context, *gorm.DB, *models.Deal) ([]*models.Deal, error)
+	ListAfter(context.Context, *gorm.DB, string, int64, int) ([]*models.Deal, error)
+	Last(context.Context, *gorm.DB, string) (*models.Deal, error)
+	Stats(context.Context, *gorm.DB, string, time.Time) (*models.DealStats, error)
+	AccountVolumes(context.Context, *gorm.DB, time.Time) ([]*models.AccountVolume, error)
 }
 
 type Deal struct {
@@ -37,3 +43,61 @@ func (d *Deal) List(ctx context.Context, tx *gorm.DB, cond *models.Deal) ([]*mod
 
 	return deals, nil
 }
+
+// ListAfter returns up to limit deals with ID after the cursor in ID order.
+// An empty symbol matches all symbols.
+func (d *Deal) ListAfter(ctx context.Context, tx *gorm.DB, symbol string, cursor int64, limit int) ([]*models.Deal, error) {
+	db := tx.WithContext(ctx).Where("id > ?", cursor)
+	if symbol != "" {
+		db = db.Where("symbol = ?", symbol)
+	}
+
+	var deals []*models.Deal
+	if err := db.Order("id").Limit(limit).Find(&deals).Error; err != nil {
+		return nil, err
+	}
+
+	return deals, nil
+}
+
+func (d *Deal) Last(ctx context.Context, tx *gorm.DB, symbol string) (*models.Deal, error) {
+	var deal *models.Deal
+	if err := tx.WithContext(ctx).Where("symbol = ?", symbol).Order("id DESC").Take(&deal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return deal, nil
+}
+
+// Stats aggregates the deals of the symbol created at or after from.
+func (d *Deal) Stats(ctx context.Context, tx *gorm.DB, symbol string, from time.Time) (*models.DealStats, error) {
+	var stats *models.DealStats
+	if err := tx.WithContext(ctx).
+		Model(&models.Deal{}).
+		Select("COALESCE(SUM(quantity), 0) AS volume, COALESCE(MAX(price), 0) AS high, COALESCE(MIN(price), 0) AS low").
+		Where("symbol = ? AND created_at >= ?", symbol, from).
+		Take(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// AccountVolumes sums the quote asset traded by every account in every symbol
+// in the deals created at or after from, as the taker or as the maker.
+func (d *Deal) AccountVolumes(ctx context.Context, tx *gorm.DB, from time.Time) ([]*models.AccountVolume, error) {
+	var volumes []*models.AccountVolume
+	if err := tx.WithContext(ctx).
+		Raw("SELECT user_id, symbol, SUM(price * quantity) AS volume FROM ("+
+			"SELECT taker_user_id AS user_id, symbol, price, quantity FROM deal WHERE created_at >= ? "+
+			"UNION ALL SELECT maker_user_id AS user_id, symbol, price, quantity FROM deal WHERE created_at >= ?"+
+			") AS side GROUP BY user_id, symbol", from, from).
+		Scan(&volumes).Error; err != nil {
+		return nil, err
+	}
+
+	return volumes, nil
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
@@ -71,7 +72,7 @@ func (t *DealTestSuite) TestInsert() {
 			fn: func() {
 				t.mockDB.ExpectBegin()
 				t.mockDB.
-					ExpectExec(regexp.QuoteMeta("INSERT INTO `deal` (`taker_order_id`,`maker_order_id`,`quantity`,`price`,`id`) VALUES (?,?,?,?,?),(?,?,?,?,?)")).
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `deal` (`symbol`,`taker_order_id`,`maker_order_id`,`taker_user_id`,`maker_user_id`,`quantity`,`price`,`taker_fee`,`taker_fee_asset`,`maker_fee`,`maker_fee_asset`,`created_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 					WillReturnResult(sqlmock.NewResult(2, 2))
 				t.mockDB.ExpectCommit()
 			},
@@ -92,7 +93,7 @@ func (t *DealTestSuite) TestInsert() {
 			fn: func() {
 				t.mockDB.ExpectBegin()
 				t.mockDB.
-					ExpectExec(regexp.QuoteMeta("INSERT INTO `deal` (`taker_order_id`,`maker_order_id`,`quantity`,`price`,`id`) VALUES (?,?,?,?,?),(?,?,?,?,?)")).
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `deal` (`symbol`,`taker_order_id`,`maker_order_id`,`taker_user_id`,`maker_user_id`,`quantity`,`price`,`taker_fee`,`taker_fee_asset`,`maker_fee`,`maker_fee_asset`,`created_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 					WillReturnError(errors.New(""))
 				t.mockDB.ExpectCommit()
 			},
@@ -156,3 +157,206 @@ func (t *DealTestSuite) TestList() {
 		})
 	}
 }
+
+func (t *DealTestSuite) TestLast() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.Deal
+		hasError bool
+	}{
+		{
+			name: "Last deal success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `deal` WHERE symbol = ? ORDER BY id DESC LIMIT 1")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "taker_order_id", "maker_order_id", "quantity", "price"}).
+						AddRow(1, 2, 3, 4, 5))
+			},
+			expected: &models.Deal{
+				ID:           1,
+				TakerOrderID: 2,
+				MakerOrderID: 3,
+				Quantity:     4,
+				Price:        5,
+			},
+			hasError: false,
+		},
+		{
+			name: "Last deal not found",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `deal` WHERE symbol = ? ORDER BY id DESC LIMIT 1")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "taker_order_id", "maker_order_id", "quantity", "price"}))
+			},
+			expected: nil,
+			hasError: false,
+		},
+		{
+			name: "Last deal failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `deal` WHERE symbol = ? ORDER BY id DESC LIMIT 1")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewDeal().Last(context.Background(), t.mockGormDB, "BTCUSD")
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *DealTestSuite) TestStats() {
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.DealStats
+		hasError bool
+	}{
+		{
+			name: "Stats success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) AS volume, COALESCE(MAX(price), 0) AS high, COALESCE(MIN(price), 0) AS low FROM `deal` WHERE symbol = ? AND created_at >= ? LIMIT 1")).
+					WithArgs("BTCUSD", from).
+					WillReturnRows(sqlmock.NewRows([]string{"volume", "high", "low"}).AddRow(10, 12, 8))
+			},
+			expected: &models.DealStats{Volume: 10, High: 12, Low: 8},
+			hasError: false,
+		},
+		{
+			name: "Stats failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(quantity), 0) AS volume, COALESCE(MAX(price), 0) AS high, COALESCE(MIN(price), 0) AS low FROM `deal` WHERE symbol = ? AND created_at >= ? LIMIT 1")).
+					WithArgs("BTCUSD", from).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewDeal().Stats(context.Background(), t.mockGormDB, "BTCUSD", from)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *DealTestSuite) TestListAfter() {
+	tests := []struct {
+		name     string
+		symbol   string
+		fn       func()
+		expected []*models.Deal
+		hasError bool
+	}{
+		{
+			name:   "List deals after cursor success",
+			symbol: "BTCUSD",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `deal` WHERE id > ? AND symbol = ? ORDER BY id LIMIT 2")).
+					WithArgs(1, "BTCUSD").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "symbol", "quantity", "price"}).
+						AddRow(2, "BTCUSD", 1, 10).
+						AddRow(3, "BTCUSD", 2, 11))
+			},
+			expected: []*models.Deal{
+				{ID: 2, Symbol: "BTCUSD", Quantity: 1, Price: 10},
+				{ID: 3, Symbol: "BTCUSD", Quantity: 2, Price: 11},
+			},
+			hasError: false,
+		},
+		{
+			name:   "List deals after cursor of all symbols",
+			symbol: "",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `deal` WHERE id > ? ORDER BY id LIMIT 2")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expected: []*models.Deal{},
+			hasError: false,
+		},
+		{
+			name:   "List deals after cursor failed",
+			symbol: "BTCUSD",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `deal` WHERE id > ? AND symbol = ? ORDER BY id LIMIT 2")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewDeal().ListAfter(context.Background(), t.mockGormDB, test.symbol, 1, 2)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *DealTestSuite) TestAccountVolumes() {
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	volumeSQL := "SELECT user_id, symbol, SUM(price * quantity) AS volume FROM (" +
+		"SELECT taker_user_id AS user_id, symbol, price, quantity FROM deal WHERE created_at >= ? " +
+		"UNION ALL SELECT maker_user_id AS user_id, symbol, price, quantity FROM deal WHERE created_at >= ?" +
+		") AS side GROUP BY user_id, symbol"
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.AccountVolume
+		hasError bool
+	}{
+		{
+			name: "Account volumes success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta(volumeSQL)).
+					WithArgs(from, from).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "symbol", "volume"}).AddRow(1, "BTCUSD", 100).AddRow(2, "ETHUSD", 30))
+			},
+			expected: []*models.AccountVolume{{UserID: 1, Symbol: "BTCUSD", Volume: 100}, {UserID: 2, Symbol: "ETHUSD", Volume: 30}},
+			hasError: false,
+		},
+		{
+			name: "Account volumes failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta(volumeSQL)).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewDeal().AccountVolumes(context.Background(), t.mockGormDB, from)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package dao
+
+import (
+	"dealer/internal/models"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type LedgerInterface interface {
+	Insert(context.Context, *gorm.DB, []*models.LedgerEntry) error
+}
+
+type Ledger struct{}
+
+var _ LedgerInterface = (*Ledger)(nil)
+
+func NewLedger() *Ledger {
+	return &Ledger{}
+}
+
+func (l *Ledger) Insert(ctx context.Context, tx *gorm.DB, entries []*models.LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return tx.WithContext(ctx).Create(&entries).Error
+}
@@ -0,0 +1,100 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type LedgerTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *LedgerTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *LedgerTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestLedgerTestSuite(t *testing.T) {
+	suite.Run(t, new(LedgerTestSuite))
+}
+
+func (t *LedgerTestSuite) TestInsert() {
+	insertSQL := "INSERT INTO `ledger` (`user_id`,`asset`,`bucket`,`amount`,`reason`,`order_id`,`deal_id`,`created_at`) VALUES (?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?)"
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Insert ledger entries success",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(insertSQL)).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Insert ledger entries failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta(insertSQL)).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewLedger().Insert(context.Background(), t.mockGormDB, []*models.LedgerEntry{
+				{UserID: 1, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 10, Reason: models.LedgerReasonDeposit},
+				{UserID: models.SystemUserID, Asset: "USD", Bucket: models.BalanceAvailable, Amount: -10, Reason: models.LedgerReasonDeposit},
+			})
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"dealer/internal/models"
+	"time"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MarketStateInterface interface {
+	List(context.Context, *gorm.DB) ([]*models.MarketState, error)
+	ListResumable(context.Context, *gorm.DB, time.Time) ([]*models.MarketState, error)
+	Upsert(context.Context, *gorm.DB, *models.MarketState) error
+}
+
+type MarketState struct{}
+
+var _ MarketStateInterface = (*MarketState)(nil)
+
+func NewMarketState() *MarketState {
+	return &MarketState{}
+}
+
+func (s *MarketState) List(ctx context.Context, tx *gorm.DB) ([]*models.MarketState, error) {
+	var states []*models.MarketState
+	if err := tx.WithContext(ctx).Order("symbol").Find(&states).Error; err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// ListResumable returns the halted markets whose cooldown has ended by now.
+func (s *MarketState) ListResumable(ctx context.Context, tx *gorm.DB, now time.Time) ([]*models.MarketState, error) {
+	var states []*models.MarketState
+	if err := tx.WithContext(ctx).Where("resume_at <= ?", now).Order("symbol").Find(&states).Error; err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+func (s *MarketState) Upsert(ctx context.Context, tx *gorm.DB, state *models.MarketState) error {
+	return tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"phase", "resume_at", "resume_phase", "sequence", "reference_price", "updated_at"}),
+	}).Create(state).Error
+}
@@ -0,0 +1,175 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type MarketStateTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *MarketStateTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *MarketStateTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestMarketStateTestSuite(t *testing.T) {
+	suite.Run(t, new(MarketStateTestSuite))
+}
+
+func (t *MarketStateTestSuite) TestList() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.MarketState
+		hasError bool
+	}{
+		{
+			name: "List market states success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `market_state` ORDER BY symbol")).
+					WillReturnRows(sqlmock.NewRows([]string{"symbol", "phase"}).AddRow("BTCUSD", "auction"))
+			},
+			expected: []*models.MarketState{{Symbol: "BTCUSD", Phase: models.TradingPhaseAuction}},
+			hasError: false,
+		},
+		{
+			name: "List market states failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `market_state` ORDER BY symbol")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			states, err := NewMarketState().List(context.Background(), t.mockGormDB)
+			t.Equal(test.expected, states)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *MarketStateTestSuite) TestListResumable() {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.MarketState
+		hasError bool
+	}{
+		{
+			name: "List resumable market states success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `market_state` WHERE resume_at <= ? ORDER BY symbol")).
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"symbol", "phase", "resume_at"}).AddRow("BTCUSD", "halted", now))
+			},
+			expected: []*models.MarketState{{Symbol: "BTCUSD", Phase: models.TradingPhaseHalted, ResumeAt: &now}},
+			hasError: false,
+		},
+		{
+			name: "List resumable market states failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `market_state` WHERE resume_at <= ? ORDER BY symbol")).
+					WithArgs(now).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			states, err := NewMarketState().ListResumable(context.Background(), t.mockGormDB, now)
+			t.Equal(test.expected, states)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *MarketStateTestSuite) TestUpsert() {
+	upsertSQL := "INSERT INTO `market_state` (`symbol`,`phase`,`resume_at`,`resume_phase`,`sequence`,`reference_price`,`updated_at`) VALUES (?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `phase`=VALUES(`phase`),`resume_at`=VALUES(`resume_at`),`resume_phase`=VALUES(`resume_phase`),`sequence`=VALUES(`sequence`),`reference_price`=VALUES(`reference_price`),`updated_at`=VALUES(`updated_at`)"
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Upsert market state success",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.ExpectExec(regexp.QuoteMeta(upsertSQL)).WillReturnResult(sqlmock.NewResult(0, 1))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Upsert market state failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.ExpectExec(regexp.QuoteMeta(upsertSQL)).WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewMarketState().Upsert(context.Background(), t.mockGormDB, &models.MarketState{Symbol: "BTCUSD", Phase: models.TradingPhaseAuction})
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,6 +2,8 @@ package dao
 
 import (
 	"dealer/internal/models"
+	"errors"
+	"time"
 
 	"golang.org/x/net/context"
 	"gorm.io/gorm"
@@ -9,9 +11,15 @@ import (
 )
 
 type OrderInterface interface {
+	Get(context.Context, *gorm.DB, int64) (*models.Order, error)
 	Insert(context.Context, *gorm.DB, *models.Order) error
 	Update(context.Context, *gorm.DB, *models.Order) error
 	BulkUpdate(context.Context, *gorm.DB, []*models.Order) error
+	ListOpen(context.Context, *gorm.DB) ([]*models.Order, error)
+	ListOpenBySymbol(context.Context, *gorm.DB, string) ([]*models.Order, error)
+	ListExpired(context.Context, *gorm.DB, time.Time) ([]*models.Order, error)
+	List(context.Context, *gorm.DB, *models.OrderQuery) ([]*models.Order, error)
+	MaxSequence(context.Context, *gorm.DB) (int64, error)
 }
 
 type Order struct{}
@@ -22,6 +30,18 @@ func NewOrder() *Order {
 	return &Order{}
 }
 
+func (o *Order) Get(ctx context.Context, tx *gorm.DB, orderID int64) (*models.Order, error) {
+	var order *models.Order
+	if err := tx.WithContext(ctx).Take(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
 func (o *Order) Insert(ctx context.Context, tx *gorm.DB, order *models.Order) error {
 	if order == nil {
 		return nil
@@ -38,7 +58,96 @@ func (d *Order) BulkUpdate(ctx context.Context, tx *gorm.DB, orders []*models.Or
 	return tx.WithContext(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "id"}},
-			DoUpdates: clause.AssignmentColumns([]string{"remain_quantity"}),
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "remain_quantity", "price", "is_cancel", "status", "triggered_at", "budget", "priority", "sequence"}),
 		}).Create(&orders).
 		Error
 }
+
+// openStatuses are the statuses of the orders that may still rest in the
+// books. A rejected order keeps its remain quantity, so the status is
+// filtered along with it.
+var openStatuses = []models.OrderStatus{models.OrderStatusNew, models.OrderStatusPartiallyFilled}
+
+func (d *Order) ListOpen(ctx context.Context, tx *gorm.DB) ([]*models.Order, error) {
+	var orders []*models.Order
+	if err := tx.WithContext(ctx).
+		Where("is_cancel = ? AND status IN ? AND remain_quantity > ?", false, openStatuses, 0).
+		Order("id").
+		Find(&orders).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (d *Order) ListOpenBySymbol(ctx context.Context, tx *gorm.DB, symbol string) ([]*models.Order, error) {
+	var orders []*models.Order
+	if err := tx.WithContext(ctx).
+		Where("symbol = ? AND is_cancel = ? AND status IN ? AND remain_quantity > ?", symbol, false, openStatuses, 0).
+		Order("id").
+		Find(&orders).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (d *Order) ListExpired(ctx context.Context, tx *gorm.DB, now time.Time) ([]*models.Order, error) {
+	var orders []*models.Order
+	if err := tx.WithContext(ctx).
+		Where("time_in_force = ? AND is_cancel = ? AND status IN ? AND remain_quantity > ? AND expire_at <= ?", models.TimeInForceGTD, false, openStatuses, 0, now).
+		Order("id").
+		Find(&orders).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (d *Order) List(ctx context.Context, tx *gorm.DB, query *models.OrderQuery) ([]*models.Order, error) {
+	db := tx.WithContext(ctx).Where("id > ?", query.Cursor)
+	if query.UserID != 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Symbol != "" {
+		db = db.Where("symbol = ?", query.Symbol)
+	}
+	if query.OrderType != 0 {
+		db = db.Where("order_type = ?", query.OrderType)
+	}
+	if query.PriceType != 0 {
+		db = db.Where("price_type = ?", query.PriceType)
+	}
+	if query.Status != 0 {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		db = db.Where("created_at < ?", *query.CreatedTo)
+	}
+
+	var orders []*models.Order
+	if err := db.Order("id").Limit(query.Limit).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (d *Order) MaxSequence(ctx context.Context, tx *gorm.DB) (int64, error) {
+	var sequence int64
+	if err := tx.WithContext(ctx).
+		Model(&models.Order{}).
+		Select("COALESCE(MAX(sequence), 0)").
+		Scan(&sequence).
+		Error; err != nil {
+		return 0, err
+	}
+
+	return sequence, nil
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
@@ -74,7 +75,7 @@ func (t *OrderTestSuite) TestInsert() {
 			fn: func() {
 				t.mockDB.ExpectBegin()
 				t.mockDB.
-					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`) VALUES (?,?,?,?,?,?)")).
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`user_id`,`symbol`,`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`,`status`,`time_in_force`,`expire_at`,`stop_price`,`triggered_at`,`budget`,`self_trade_prevention`,`display_quantity`,`post_only`,`min_quantity`,`priority`,`sequence`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				t.mockDB.ExpectCommit()
 			},
@@ -98,7 +99,7 @@ func (t *OrderTestSuite) TestInsert() {
 			fn: func() {
 				t.mockDB.ExpectBegin()
 				t.mockDB.
-					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`) VALUES (?,?,?,?,?,?)")).
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`user_id`,`symbol`,`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`,`status`,`time_in_force`,`expire_at`,`stop_price`,`triggered_at`,`budget`,`self_trade_prevention`,`display_quantity`,`post_only`,`min_quantity`,`priority`,`sequence`,`created_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")).
 					WillReturnError(errors.New(""))
 				t.mockDB.ExpectCommit()
 			},
@@ -165,7 +166,7 @@ func (t *OrderTestSuite) TestBulkUpdate() {
 			fn: func() {
 				t.mockDB.ExpectBegin()
 				t.mockDB.
-					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`,`id`) VALUES (?,?,?,?,?,?,?),(?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `remain_quantity`=VALUES(`remain_quantity`)")).
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`user_id`,`symbol`,`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`,`status`,`time_in_force`,`expire_at`,`stop_price`,`triggered_at`,`budget`,`self_trade_prevention`,`display_quantity`,`post_only`,`min_quantity`,`priority`,`sequence`,`created_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `quantity`=VALUES(`quantity`),`remain_quantity`=VALUES(`remain_quantity`),`price`=VALUES(`price`),`is_cancel`=VALUES(`is_cancel`),`status`=VALUES(`status`),`triggered_at`=VALUES(`triggered_at`),`budget`=VALUES(`budget`),`priority`=VALUES(`priority`),`sequence`=VALUES(`sequence`)")).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				t.mockDB.ExpectCommit()
 			},
@@ -176,7 +177,7 @@ func (t *OrderTestSuite) TestBulkUpdate() {
 			fn: func() {
 				t.mockDB.ExpectBegin()
 				t.mockDB.
-					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`,`id`) VALUES (?,?,?,?,?,?,?),(?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `remain_quantity`=VALUES(`remain_quantity`)")).
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `order` (`user_id`,`symbol`,`order_type`,`quantity`,`remain_quantity`,`price_type`,`price`,`is_cancel`,`status`,`time_in_force`,`expire_at`,`stop_price`,`triggered_at`,`budget`,`self_trade_prevention`,`display_quantity`,`post_only`,`min_quantity`,`priority`,`sequence`,`created_at`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?),(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `quantity`=VALUES(`quantity`),`remain_quantity`=VALUES(`remain_quantity`),`price`=VALUES(`price`),`is_cancel`=VALUES(`is_cancel`),`status`=VALUES(`status`),`triggered_at`=VALUES(`triggered_at`),`budget`=VALUES(`budget`),`priority`=VALUES(`priority`),`sequence`=VALUES(`sequence`)")).
 					WillReturnError(errors.New(""))
 				t.mockDB.ExpectCommit()
 			},
@@ -202,3 +203,341 @@ func (t *OrderTestSuite) TestBulkUpdate() {
 		})
 	}
 }
+
+func (t *OrderTestSuite) TestListOpen() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Order
+		hasError bool
+	}{
+		{
+			name: "List open orders success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE is_cancel = ? AND status IN (?,?) AND remain_quantity > ? ORDER BY id")).
+					WithArgs(false, models.OrderStatusNew, models.OrderStatusPartiallyFilled, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "order_type", "quantity", "remain_quantity", "price_type", "price", "is_cancel"}).
+						AddRow(1, 1, 5, 3, 1, 10, false).
+						AddRow(2, 2, 4, 4, 2, 0, false))
+			},
+			expected: []*models.Order{
+				{
+					ID:             1,
+					OrderType:      models.OrderTypeBuy,
+					Quantity:       5,
+					RemainQuantity: 3,
+					PriceType:      models.PriceTypeLimit,
+					Price:          10,
+				},
+				{
+					ID:             2,
+					OrderType:      models.OrderTypeSell,
+					Quantity:       4,
+					RemainQuantity: 4,
+					PriceType:      models.PriceTypeMarket,
+				},
+			},
+			hasError: false,
+		},
+		{
+			name: "List open orders failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE is_cancel = ? AND status IN (?,?) AND remain_quantity > ? ORDER BY id")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOrder().ListOpen(context.Background(), t.mockGormDB)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OrderTestSuite) TestListOpenBySymbol() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Order
+		hasError bool
+	}{
+		{
+			name: "List open orders of symbol success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE symbol = ? AND is_cancel = ? AND status IN (?,?) AND remain_quantity > ? ORDER BY id")).
+					WithArgs("BTCUSD", false, models.OrderStatusNew, models.OrderStatusPartiallyFilled, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "symbol", "order_type", "quantity", "remain_quantity", "price_type", "price", "is_cancel"}).
+						AddRow(1, "BTCUSD", 1, 5, 3, 1, 10, false))
+			},
+			expected: []*models.Order{
+				{
+					ID:             1,
+					Symbol:         "BTCUSD",
+					OrderType:      models.OrderTypeBuy,
+					Quantity:       5,
+					RemainQuantity: 3,
+					PriceType:      models.PriceTypeLimit,
+					Price:          10,
+				},
+			},
+			hasError: false,
+		},
+		{
+			name: "List open orders of symbol failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE symbol = ? AND is_cancel = ? AND status IN (?,?) AND remain_quantity > ? ORDER BY id")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOrder().ListOpenBySymbol(context.Background(), t.mockGormDB, "BTCUSD")
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OrderTestSuite) TestListExpired() {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Order
+		hasError bool
+	}{
+		{
+			name: "List expired orders success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE time_in_force = ? AND is_cancel = ? AND status IN (?,?) AND remain_quantity > ? AND expire_at <= ? ORDER BY id")).
+					WithArgs(models.TimeInForceGTD, false, models.OrderStatusNew, models.OrderStatusPartiallyFilled, 0, now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "remain_quantity", "time_in_force", "expire_at"}).
+						AddRow(1, 3, 4, now))
+			},
+			expected: []*models.Order{
+				{
+					ID:             1,
+					RemainQuantity: 3,
+					TimeInForce:    models.TimeInForceGTD,
+					ExpireAt:       &now,
+				},
+			},
+			hasError: false,
+		},
+		{
+			name: "List expired orders failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE time_in_force = ? AND is_cancel = ? AND status IN (?,?) AND remain_quantity > ? AND expire_at <= ? ORDER BY id")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOrder().ListExpired(context.Background(), t.mockGormDB, now)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OrderTestSuite) TestGet() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.Order
+		hasError bool
+	}{
+		{
+			name: "Get order success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE `order`.`id` = ? LIMIT 1")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "symbol", "order_type", "quantity", "remain_quantity", "price_type", "price", "is_cancel"}).
+						AddRow(1, "BTCUSD", 1, 5, 3, 1, 10, false))
+			},
+			expected: &models.Order{
+				ID:             1,
+				Symbol:         "BTCUSD",
+				OrderType:      models.OrderTypeBuy,
+				Quantity:       5,
+				RemainQuantity: 3,
+				PriceType:      models.PriceTypeLimit,
+				Price:          10,
+			},
+			hasError: false,
+		},
+		{
+			name: "Get order not found",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE `order`.`id` = ? LIMIT 1")).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expected: nil,
+			hasError: false,
+		},
+		{
+			name: "Get order failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE `order`.`id` = ? LIMIT 1")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOrder().Get(context.Background(), t.mockGormDB, 1)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OrderTestSuite) TestList() {
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	tests := []struct {
+		name     string
+		query    *models.OrderQuery
+		fn       func()
+		expected []*models.Order
+		hasError bool
+	}{
+		{
+			name:  "List orders without filter",
+			query: &models.OrderQuery{Limit: 10},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE id > ? ORDER BY id LIMIT 10")).
+					WithArgs(0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "symbol"}).
+						AddRow(1, "BTCUSD"))
+			},
+			expected: []*models.Order{{ID: 1, Symbol: "BTCUSD"}},
+			hasError: false,
+		},
+		{
+			name: "List orders with filters",
+			query: &models.OrderQuery{
+				UserID:      3,
+				Symbol:      "BTCUSD",
+				OrderType:   models.OrderTypeBuy,
+				Status:      models.OrderStatusNew,
+				PriceType:   models.PriceTypeLimit,
+				CreatedFrom: &from,
+				CreatedTo:   &to,
+				Cursor:      5,
+				Limit:       10,
+			},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE id > ? AND user_id = ? AND symbol = ? AND order_type = ? AND price_type = ? AND status = ? AND created_at >= ? AND created_at < ? ORDER BY id LIMIT 10")).
+					WithArgs(5, 3, "BTCUSD", models.OrderTypeBuy, models.PriceTypeLimit, models.OrderStatusNew, from, to).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "symbol"}).
+						AddRow(6, "BTCUSD"))
+			},
+			expected: []*models.Order{{ID: 6, Symbol: "BTCUSD"}},
+			hasError: false,
+		},
+		{
+			name:  "List orders by status",
+			query: &models.OrderQuery{Status: models.OrderStatusCancelled, Limit: 10},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE id > ? AND status = ? ORDER BY id LIMIT 10")).
+					WithArgs(0, models.OrderStatusCancelled).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "is_cancel", "status"}).
+						AddRow(2, true, models.OrderStatusCancelled))
+			},
+			expected: []*models.Order{{ID: 2, IsCancel: true, Status: models.OrderStatusCancelled}},
+			hasError: false,
+		},
+		{
+			name:  "List orders failed",
+			query: &models.OrderQuery{Limit: 10},
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order` WHERE id > ? ORDER BY id LIMIT 10")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOrder().List(context.Background(), t.mockGormDB, test.query)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OrderTestSuite) TestMaxSequence() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected int64
+		hasError bool
+	}{
+		{
+			name: "Max sequence success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(sequence), 0) FROM `order`")).
+					WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(12))
+			},
+			expected: 12,
+			hasError: false,
+		},
+		{
+			name: "Max sequence failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(sequence), 0) FROM `order`")).
+					WillReturnError(errors.New(""))
+			},
+			expected: 0,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOrder().MaxSequence(context.Background(), t.mockGormDB)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
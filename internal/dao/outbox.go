@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"dealer/internal/models"
+	"time"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type OutboxInterface interface {
+	Insert(context.Context, *gorm.DB, *models.Outbox) error
+	ListPending(context.Context, *gorm.DB, int) ([]*models.Outbox, error)
+	ListUnsequenced(context.Context, *gorm.DB, int) ([]*models.Outbox, error)
+	LastSequence(context.Context, *gorm.DB) (int64, error)
+	SetSequence(context.Context, *gorm.DB, int64, int64) error
+	MarkSent(context.Context, *gorm.DB, int64, time.Time) error
+	IncreaseAttempts(context.Context, *gorm.DB, int64) error
+}
+
+type Outbox struct{}
+
+var _ OutboxInterface = (*Outbox)(nil)
+
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+func (o *Outbox) Insert(ctx context.Context, tx *gorm.DB, outbox *models.Outbox) error {
+	return tx.WithContext(ctx).Create(outbox).Error
+}
+
+func (o *Outbox) ListPending(ctx context.Context, tx *gorm.DB, limit int) ([]*models.Outbox, error) {
+	var outboxes []*models.Outbox
+	if err := tx.WithContext(ctx).
+		Where("sent_at IS NULL AND sequence > ?", 0).
+		Order("sequence").
+		Limit(limit).
+		Find(&outboxes).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return outboxes, nil
+}
+
+func (o *Outbox) ListUnsequenced(ctx context.Context, tx *gorm.DB, limit int) ([]*models.Outbox, error) {
+	var outboxes []*models.Outbox
+	if err := tx.WithContext(ctx).
+		Select("id").
+		Where("sequence = ?", 0).
+		Order("id").
+		Limit(limit).
+		Find(&outboxes).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return outboxes, nil
+}
+
+func (o *Outbox) LastSequence(ctx context.Context, tx *gorm.DB) (int64, error) {
+	var sequence int64
+	if err := tx.WithContext(ctx).
+		Model(&models.Outbox{}).
+		Select("COALESCE(MAX(sequence), 0)").
+		Scan(&sequence).
+		Error; err != nil {
+		return 0, err
+	}
+
+	return sequence, nil
+}
+
+func (o *Outbox) SetSequence(ctx context.Context, tx *gorm.DB, id, sequence int64) error {
+	return tx.WithContext(ctx).Model(&models.Outbox{ID: id}).Update("sequence", sequence).Error
+}
+
+func (o *Outbox) MarkSent(ctx context.Context, tx *gorm.DB, id int64, sentAt time.Time) error {
+	return tx.WithContext(ctx).Model(&models.Outbox{ID: id}).Update("sent_at", sentAt).Error
+}
+
+func (o *Outbox) IncreaseAttempts(ctx context.Context, tx *gorm.DB, id int64) error {
+	return tx.WithContext(ctx).Model(&models.Outbox{ID: id}).Update("attempts", gorm.Expr("attempts + ?", 1)).Error
+}
@@ -0,0 +1,221 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type OutboxTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *OutboxTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *OutboxTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxTestSuite))
+}
+
+func (t *OutboxTestSuite) TestInsert() {
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Insert outbox success",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox` (`sequence`,`payload`,`attempts`,`sent_at`,`created_at`) VALUES (?,?,?,?,?)")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Insert outbox failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox` (`sequence`,`payload`,`attempts`,`sent_at`,`created_at`) VALUES (?,?,?,?,?)")).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewOutbox().Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: []byte("{}")})
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *OutboxTestSuite) TestListPending() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Outbox
+		hasError bool
+	}{
+		{
+			name: "List pending outboxes success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `outbox` WHERE sent_at IS NULL AND sequence > ? ORDER BY sequence LIMIT 10")).
+					WithArgs(0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "payload", "attempts"}).
+						AddRow(1, []byte("{}"), 2))
+			},
+			expected: []*models.Outbox{{ID: 1, Payload: []byte("{}"), Attempts: 2}},
+			hasError: false,
+		},
+		{
+			name: "List pending outboxes failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `outbox` WHERE sent_at IS NULL AND sequence > ? ORDER BY sequence LIMIT 10")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOutbox().ListPending(context.Background(), t.mockGormDB, 10)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OutboxTestSuite) TestListUnsequenced() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Outbox
+		hasError bool
+	}{
+		{
+			name: "List unsequenced outboxes success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `outbox` WHERE sequence = ? ORDER BY id LIMIT 10")).
+					WithArgs(0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+			},
+			expected: []*models.Outbox{{ID: 1}, {ID: 2}},
+			hasError: false,
+		},
+		{
+			name: "List unsequenced outboxes failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `outbox` WHERE sequence = ? ORDER BY id LIMIT 10")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewOutbox().ListUnsequenced(context.Background(), t.mockGormDB, 10)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *OutboxTestSuite) TestLastSequence() {
+	t.mockDB.
+		ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(sequence), 0) FROM `outbox`")).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence"}).AddRow(6))
+
+	actual, err := NewOutbox().LastSequence(context.Background(), t.mockGormDB)
+	t.NoError(err)
+	t.Equal(int64(6), actual)
+}
+
+func (t *OutboxTestSuite) TestSetSequence() {
+	t.mockDB.ExpectBegin()
+	t.mockDB.
+		ExpectExec(regexp.QuoteMeta("UPDATE `outbox` SET `sequence`=? WHERE `id` = ?")).
+		WithArgs(7, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	t.mockDB.ExpectCommit()
+
+	t.NoError(NewOutbox().SetSequence(context.Background(), t.mockGormDB, 5, 7))
+}
+
+func (t *OutboxTestSuite) TestMarkSent() {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	t.mockDB.ExpectBegin()
+	t.mockDB.
+		ExpectExec(regexp.QuoteMeta("UPDATE `outbox` SET `sent_at`=? WHERE `id` = ?")).
+		WithArgs(now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	t.mockDB.ExpectCommit()
+
+	t.NoError(NewOutbox().MarkSent(context.Background(), t.mockGormDB, 1, now))
+}
+
+func (t *OutboxTestSuite) TestIncreaseAttempts() {
+	t.mockDB.ExpectBegin()
+	t.mockDB.
+		ExpectExec(regexp.QuoteMeta("UPDATE `outbox` SET `attempts`=attempts + ? WHERE `id` = ?")).
+		WithArgs(1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	t.mockDB.ExpectCommit()
+
+	t.NoError(NewOutbox().IncreaseAttempts(context.Background(), t.mockGormDB, 1))
+}
@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"dealer/internal/models"
+	"errors"
+
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+)
+
+type UserInterface interface {
+	Insert(context.Context, *gorm.DB, *models.User) error
+	GetByAPIKey(context.Context, *gorm.DB, string) (*models.User, error)
+}
+
+type User struct{}
+
+var _ UserInterface = (*User)(nil)
+
+func NewUser() *User {
+	return &User{}
+}
+
+func (u *User) Insert(ctx context.Context, tx *gorm.DB, user *models.User) error {
+	return tx.WithContext(ctx).Create(user).Error
+}
+
+func (u *User) GetByAPIKey(ctx context.Context, tx *gorm.DB, apiKey string) (*models.User, error) {
+	var user *models.User
+	if err := tx.WithContext(ctx).Where("api_key = ?", apiKey).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,146 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"dealer/internal/models"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type UserTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	db         *sql.DB
+	mockDB     sqlmock.Sqlmock
+	mockGormDB *gorm.DB
+}
+
+func (t *UserTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+}
+
+func (t *UserTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestUserTestSuite(t *testing.T) {
+	suite.Run(t, new(UserTestSuite))
+}
+
+func (t *UserTestSuite) TestInsert() {
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Insert user success",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `user` (`name`,`api_key`,`api_secret`,`is_admin`,`created_at`) VALUES (?,?,?,?,?)")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Insert user failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockDB.
+					ExpectExec(regexp.QuoteMeta("INSERT INTO `user` (`name`,`api_key`,`api_secret`,`is_admin`,`created_at`) VALUES (?,?,?,?,?)")).
+					WillReturnError(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := NewUser().Insert(context.Background(), t.mockGormDB, &models.User{Name: "alice", APIKey: "key", APISecret: "secret"})
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *UserTestSuite) TestGetByAPIKey() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.User
+		hasError bool
+	}{
+		{
+			name: "Get user success",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `user` WHERE api_key = ? LIMIT 1")).
+					WithArgs("key").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "api_key", "api_secret"}).AddRow(1, "alice", "key", "secret"))
+			},
+			expected: &models.User{ID: 1, Name: "alice", APIKey: "key", APISecret: "secret"},
+			hasError: false,
+		},
+		{
+			name: "Get user not found",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `user` WHERE api_key = ? LIMIT 1")).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expected: nil,
+			hasError: false,
+		},
+		{
+			name: "Get user failed",
+			fn: func() {
+				t.mockDB.
+					ExpectQuery(regexp.QuoteMeta("SELECT * FROM `user` WHERE api_key = ? LIMIT 1")).
+					WillReturnError(errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := NewUser().GetByAPIKey(context.Background(), t.mockGormDB, "key")
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"dealer/internal/models"
+	"dealer/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) HaltMarket(ctx *gin.Context) {
+	var req *models.MarketRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.orderProcessor.HaltMarket(ctx, req.Symbol)
+	if errors.Is(err, service.ErrUnknownSymbol) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (h *Handler) ResumeMarket(ctx *gin.Context) {
+	var req *models.MarketRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.orderProcessor.ResumeMarket(ctx, req.Symbol, nil)
+	if errors.Is(err, service.ErrUnknownSymbol) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (h *Handler) CancelAll(ctx *gin.Context) {
+	var req *models.CancelAllRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	massCancels, err := h.orderProcessor.CancelAll(ctx, &models.MassCancel{
+		Symbol:    req.Symbol,
+		OrderType: req.OrderType,
+		UserID:    req.UserID,
+	})
+	if errors.Is(err, service.ErrUnknownSymbol) || errors.Is(err, service.ErrInvalidOrderType) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, massCancels)
+}
+
+func (h *Handler) Book(ctx *gin.Context) {
+	var req *models.MarketRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	book, err := h.query.Book(ctx, req.Symbol)
+	if errors.Is(err, service.ErrUnknownSymbol) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, book)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"dealer/internal/models"
+	"dealer/internal/service"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	APIKeyHeader    = "X-API-Key"
+	TimestampHeader = "X-Timestamp"
+	SignatureHeader = "X-Signature"
+	userIDKey       = "user_id"
+	adminKey        = "admin"
+	maxBodySize     = 1 << 20
+)
+
+// Authenticate verifies the API key and the signature of the request, and
+// keeps the ID of the user and whether it is an admin in the context for the
+// handlers.
+func (h *Handler) Authenticate(ctx *gin.Context) {
+	timestamp, err := strconv.ParseInt(ctx.GetHeader(TimestampHeader), 10, 64)
+	if err != nil {
+		ctx.String(http.StatusUnauthorized, service.ErrUnauthorized.Error())
+		ctx.Abort()
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodySize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ctx.String(http.StatusRequestEntityTooLarge, err.Error())
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		ctx.Abort()
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	user, err := h.users.Authenticate(ctx, &models.SignedRequest{
+		APIKey:    ctx.GetHeader(APIKeyHeader),
+		Timestamp: timestamp,
+		Signature: ctx.GetHeader(SignatureHeader),
+		Method:    ctx.Request.Method,
+		Path:      ctx.Request.URL.RequestURI(),
+		Body:      body,
+	}, time.Now())
+	if errors.Is(err, service.ErrUnauthorized) || errors.Is(err, service.ErrRequestExpired) {
+		ctx.String(http.StatusUnauthorized, err.Error())
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set(userIDKey, user.ID)
+	ctx.Set(adminKey, user.IsAdmin)
+	ctx.Next()
+}
+
+// AuthenticateIfSigned authenticates the requests that carry an API key and
+// lets the others through anonymously.
+func (h *Handler) AuthenticateIfSigned(ctx *gin.Context) {
+	if ctx.GetHeader(APIKeyHeader) == "" {
+		ctx.Next()
+		return
+	}
+
+	h.Authenticate(ctx)
+}
+
+// RequireAdmin refuses the requests of an authenticated user that is not an
+// admin.
+func (h *Handler) RequireAdmin(ctx *gin.Context) {
+	if !ctx.GetBool(adminKey) {
+		ctx.String(http.StatusForbidden, service.ErrForbidden.Error())
+		ctx.Abort()
+		return
+	}
+
+	ctx.Next()
+}
+
+func userID(ctx *gin.Context) int64 {
+	return ctx.GetInt64(userIDKey)
+}
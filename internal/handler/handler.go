@@ -3,18 +3,31 @@ package handler
 import (
 	"dealer/internal/models"
 	"dealer/internal/service"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	orderProcessor service.OrderProcessorInterface
+	orderProcessor   service.OrderProcessorInterface
+	query            service.QueryInterface
+	registry         service.InstrumentRegistryInterface
+	marketData       service.MarketDataInterface
+	users            service.UserServiceInterface
+	streamBufferSize int
 }
 
-func NewHandler(orderProcessor service.OrderProcessorInterface) *Handler {
+func NewHandler(orderProcessor service.OrderProcessorInterface, query service.QueryInterface, registry service.InstrumentRegistryInterface, marketData service.MarketDataInterface, users service.UserServiceInterface, streamBufferSize int) *Handler {
 	return &Handler{
-		orderProcessor: orderProcessor,
+		orderProcessor:   orderProcessor,
+		query:            query,
+		registry:         registry,
+		marketData:       marketData,
+		users:            users,
+		streamBufferSize: streamBufferSize,
 	}
 }
 
@@ -25,14 +38,52 @@ func (h *Handler) NewOrder(ctx *gin.Context) {
 		return
 	}
 
+	instrument, ok := h.registry.Get(req.Symbol)
+	if !ok {
+		ctx.String(http.StatusBadRequest, service.ErrUnknownSymbol.Error())
+		return
+	}
+
+	if req.TimeInForce == 0 {
+		req.TimeInForce = models.TimeInForceGTC
+	}
+
+	if req.SelfTradePrevention == 0 {
+		req.SelfTradePrevention = models.SelfTradePreventionCancelNewest
+	}
+
+	if req.TimeInForce != models.TimeInForceGTD {
+		req.ExpireAt = nil
+	}
+
 	order := &models.Order{
-		OrderType:      req.OrderType,
-		Quantity:       req.Quantity,
-		RemainQuantity: req.Quantity,
-		PriceType:      req.PriceType,
-		Price:          req.Price,
+		UserID:              userID(ctx),
+		Symbol:              req.Symbol,
+		OrderType:           req.OrderType,
+		Quantity:            req.Quantity,
+		RemainQuantity:      req.Quantity,
+		Status:              models.OrderStatusNew,
+		PriceType:           req.PriceType,
+		Price:               req.Price,
+		TimeInForce:         req.TimeInForce,
+		ExpireAt:            req.ExpireAt,
+		StopPrice:           req.StopPrice,
+		SelfTradePrevention: req.SelfTradePrevention,
+		DisplayQuantity:     req.DisplayQuantity,
+		PostOnly:            req.PostOnly,
+		MinQuantity:         req.MinQuantity,
+		Budget:              req.Budget,
+	}
+	if err := service.ValidateOrder(instrument, order, time.Now()); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
 	}
+
 	err := h.orderProcessor.NewOrder(ctx, order)
+	if errors.Is(err, service.ErrInsufficientFunds) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -48,7 +99,25 @@ func (h *Handler) CancelOrder(ctx *gin.Context) {
 		return
 	}
 
-	err := h.orderProcessor.CancelOrder(ctx, req.ID)
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.registry.Get(req.Symbol); !ok {
+		ctx.String(http.StatusBadRequest, service.ErrUnknownSymbol.Error())
+		return
+	}
+
+	err := h.orderProcessor.CancelOrder(ctx, userID(ctx), req.Symbol, req.ID)
+	if errors.Is(err, service.ErrOrderNotFound) {
+		ctx.String(http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrOrderClosed) {
+		ctx.String(http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -56,3 +125,220 @@ func (h *Handler) CancelOrder(ctx *gin.Context) {
 
 	ctx.Status(http.StatusOK)
 }
+
+func (h *Handler) AmendOrder(ctx *gin.Context) {
+	var req *models.AmendOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	instrument, ok := h.registry.Get(req.Symbol)
+	if !ok {
+		ctx.String(http.StatusBadRequest, service.ErrUnknownSymbol.Error())
+		return
+	}
+
+	amendment := &models.Amendment{
+		OrderID:  req.ID,
+		Symbol:   req.Symbol,
+		Price:    req.Price,
+		Quantity: req.Quantity,
+	}
+	if err := service.ValidateAmendment(instrument, amendment); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.orderProcessor.AmendOrder(ctx, userID(ctx), amendment)
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		ctx.String(http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, service.ErrOrderClosed):
+		ctx.String(http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, service.ErrPriceNotAmendable), errors.Is(err, service.ErrQuantityFilled):
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, amendment)
+}
+
+func (h *Handler) GetOrder(ctx *gin.Context) {
+	orderID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, err := h.query.GetOrder(ctx, userID(ctx), orderID)
+	if errors.Is(err, service.ErrOrderNotFound) {
+		ctx.String(http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (h *Handler) ListOrders(ctx *gin.Context) {
+	var req *models.ListOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	orders, nextCursor, err := h.query.ListOrders(ctx, &models.OrderQuery{
+		UserID:      userID(ctx),
+		Symbol:      req.Symbol,
+		OrderType:   req.OrderType,
+		Status:      req.Status,
+		PriceType:   req.PriceType,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Cursor:      req.Cursor,
+		Limit:       req.Limit,
+	})
+	if errors.Is(err, service.ErrInvalidStatus) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if orders == nil {
+		orders = []*models.Order{}
+	}
+	ctx.JSON(http.StatusOK, &models.ListOrdersResponse{Orders: orders, NextCursor: nextCursor})
+}
+
+func (h *Handler) ListOrderDeals(ctx *gin.Context) {
+	orderID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deals, err := h.query.ListDeals(ctx, userID(ctx), orderID)
+	if errors.Is(err, service.ErrOrderNotFound) {
+		ctx.String(http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if deals == nil {
+		deals = []*models.Deal{}
+	}
+	ctx.JSON(http.StatusOK, deals)
+}
+
+func (h *Handler) Depth(ctx *gin.Context) {
+	var req *models.DepthRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	depth, err := h.query.Depth(ctx, req.Symbol, req.Levels)
+	if errors.Is(err, service.ErrUnknownSymbol) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if depth.Bids == nil {
+		depth.Bids = []*models.PriceLevel{}
+	}
+	if depth.Asks == nil {
+		depth.Asks = []*models.PriceLevel{}
+	}
+	ctx.JSON(http.StatusOK, depth)
+}
+
+func (h *Handler) Ticker(ctx *gin.Context) {
+	var req *models.TickerRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ticker, err := h.query.Ticker(ctx, req.Symbol, time.Now())
+	if errors.Is(err, service.ErrUnknownSymbol) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ticker)
+}
+
+func (h *Handler) Candles(ctx *gin.Context) {
+	var req *models.CandlesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.registry.Get(req.Symbol); !ok {
+		ctx.String(http.StatusBadRequest, service.ErrUnknownSymbol.Error())
+		return
+	}
+
+	candles, err := h.query.ListCandles(ctx, &models.CandleQuery{
+		Symbol:   req.Symbol,
+		Interval: req.Interval,
+		From:     req.From,
+		To:       req.To,
+		Limit:    req.Limit,
+	})
+	if errors.Is(err, service.ErrInvalidInterval) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if candles == nil {
+		candles = []*models.Candle{}
+	}
+	ctx.JSON(http.StatusOK, candles)
+}
+
+func (h *Handler) Balances(ctx *gin.Context) {
+	balances, err := h.query.ListBalances(ctx, userID(ctx))
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if balances == nil {
+		balances = []*models.Balance{}
+	}
+	ctx.JSON(http.StatusOK, balances)
+}
@@ -9,9 +9,25 @@ import (
 func RegisterRoutes(router gin.IRouter, handler *Handler) {
 	router.GET("status", status)
 	v1Group := router.Group("v1")
+	v1Group.GET("stream", handler.AuthenticateIfSigned, handler.Stream)
+	v1Group.GET("depth", handler.Depth)
+	v1Group.GET("ticker", handler.Ticker)
+	v1Group.GET("candles", handler.Candles)
+	v1Group.Use(handler.Authenticate)
+	v1Group.GET("orders", handler.ListOrders)
+	v1Group.GET("balances", handler.Balances)
 	order := v1Group.Group("order")
 	order.POST("", handler.NewOrder)
+	order.GET(":id", handler.GetOrder)
+	order.GET(":id/deals", handler.ListOrderDeals)
 	order.DELETE(":id", handler.CancelOrder)
+	order.PATCH(":id", handler.AmendOrder)
+	admin := v1Group.Group("admin")
+	admin.Use(handler.RequireAdmin)
+	admin.POST("markets/:symbol/halt", handler.HaltMarket)
+	admin.POST("markets/:symbol/resume", handler.ResumeMarket)
+	admin.GET("markets/:symbol/book", handler.Book)
+	admin.POST("cancel-all", handler.CancelAll)
 }
 
 func status(ctx *gin.Context) {
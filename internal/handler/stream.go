@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"dealer/internal/models"
+	"dealer/internal/service"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	streamOpSubscribe   = "subscribe"
+	streamOpUnsubscribe = "unsubscribe"
+	streamWriteWait     = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// Stream upgrades the request to a WebSocket, on which the client subscribes
+// to market data channels with StreamRequest messages. The market data channels
+// are public, and the orders channels are limited to the orders of the
+// authenticated user.
+func (h *Handler) Stream(ctx *gin.Context) {
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+
+	s := &stream{
+		ctx:           ctx.Request.Context(),
+		userID:        userID(ctx),
+		conn:          conn,
+		query:         h.query,
+		marketData:    h.marketData,
+		bufferSize:    h.streamBufferSize,
+		subscriptions: make(map[string]chan []byte),
+	}
+	s.run()
+}
+
+type stream struct {
+	ctx           context.Context
+	userID        int64
+	conn          *websocket.Conn
+	query         service.QueryInterface
+	marketData    service.MarketDataInterface
+	bufferSize    int
+	writeMu       sync.Mutex
+	mu            sync.Mutex
+	subscriptions map[string]chan []byte
+	wg            sync.WaitGroup
+}
+
+func (s *stream) run() {
+	defer s.close()
+
+	for {
+		var req models.StreamRequest
+		if err := s.conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		switch req.Op {
+		case streamOpSubscribe:
+			s.subscribe(req.Channel)
+		case streamOpUnsubscribe:
+			s.unsubscribe(req.Channel)
+		default:
+			s.writeError(req.Channel, service.ErrUnknownStreamOp)
+		}
+	}
+}
+
+func (s *stream) subscribe(channel string) {
+	s.mu.Lock()
+	_, ok := s.subscriptions[channel]
+	s.mu.Unlock()
+	if ok {
+		return
+	}
+
+	if orderID, ok := service.ParseOrdersChannel(channel); ok {
+		if s.userID == 0 {
+			s.writeError(channel, service.ErrUnauthorized)
+			return
+		}
+		if _, err := s.query.GetOrder(s.ctx, s.userID, orderID); err != nil {
+			s.writeError(channel, err)
+			return
+		}
+	}
+
+	c := make(chan []byte, s.bufferSize)
+	if err := s.marketData.Subscribe(channel, c); err != nil {
+		s.writeError(channel, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.subscriptions[channel] = c
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.forward(channel, c)
+}
+
+func (s *stream) unsubscribe(channel string) {
+	s.mu.Lock()
+	c, ok := s.subscriptions[channel]
+	delete(s.subscriptions, channel)
+	s.mu.Unlock()
+
+	if ok {
+		s.marketData.Unsubscribe(channel, c)
+	}
+}
+
+// forward writes the events of a subscription until it is closed. A
+// subscription closed by the market data rather than by the client has fallen
+// behind, so the client is told to subscribe again.
+func (s *stream) forward(channel string, c chan []byte) {
+	defer s.wg.Done()
+
+	for payload := range c {
+		if err := s.write(payload); err != nil {
+			s.conn.Close()
+		}
+	}
+
+	s.mu.Lock()
+	dropped := s.subscriptions[channel] == c
+	if dropped {
+		delete(s.subscriptions, channel)
+	}
+	s.mu.Unlock()
+
+	if dropped {
+		s.writeError(channel, service.ErrSlowSubscriber)
+	}
+}
+
+func (s *stream) writeError(channel string, err error) {
+	payload, _ := json.Marshal(&models.MarketEvent{Channel: channel, Type: models.MarketEventError, Error: err.Error()})
+	if err := s.write(payload); err != nil {
+		s.conn.Close()
+	}
+}
+
+func (s *stream) write(payload []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	return s.conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+func (s *stream) close() {
+	s.conn.Close()
+
+	s.mu.Lock()
+	subscriptions := s.subscriptions
+	s.subscriptions = make(map[string]chan []byte)
+	s.mu.Unlock()
+
+	for channel, c := range subscriptions {
+		s.marketData.Unsubscribe(channel, c)
+	}
+	s.wg.Wait()
+}
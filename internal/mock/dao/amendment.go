@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/amendment.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockAmendmentInterface is a mock of AmendmentInterface interface.
+type MockAmendmentInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAmendmentInterfaceMockRecorder
+}
+
+// MockAmendmentInterfaceMockRecorder is the mock recorder for MockAmendmentInterface.
+type MockAmendmentInterfaceMockRecorder struct {
+	mock *MockAmendmentInterface
+}
+
+// NewMockAmendmentInterface creates a new mock instance.
+func NewMockAmendmentInterface(ctrl *gomock.Controller) *MockAmendmentInterface {
+	mock := &MockAmendmentInterface{ctrl: ctrl}
+	mock.recorder = &MockAmendmentInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAmendmentInterface) EXPECT() *MockAmendmentInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockAmendmentInterface) Insert(arg0 context.Context, arg1 *gorm.DB, arg2 []*models.Amendment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockAmendmentInterfaceMockRecorder) Insert(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockAmendmentInterface)(nil).Insert), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockAmendmentInterface) List(arg0 context.Context, arg1 *gorm.DB, arg2 int64) ([]*models.Amendment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Amendment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAmendmentInterfaceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAmendmentInterface)(nil).List), arg0, arg1, arg2)
+}
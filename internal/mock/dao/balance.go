@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/balance.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockBalanceInterface is a mock of BalanceInterface interface.
+type MockBalanceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceInterfaceMockRecorder
+}
+
+// MockBalanceInterfaceMockRecorder is the mock recorder for MockBalanceInterface.
+type MockBalanceInterfaceMockRecorder struct {
+	mock *MockBalanceInterface
+}
+
+// NewMockBalanceInterface creates a new mock instance.
+func NewMockBalanceInterface(ctrl *gomock.Controller) *MockBalanceInterface {
+	mock := &MockBalanceInterface{ctrl: ctrl}
+	mock.recorder = &MockBalanceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceInterface) EXPECT() *MockBalanceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockBalanceInterface) Add(arg0 context.Context, arg1 *gorm.DB, arg2 []*models.Balance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockBalanceInterfaceMockRecorder) Add(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockBalanceInterface)(nil).Add), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockBalanceInterface) Get(arg0 context.Context, arg1 *gorm.DB, arg2 int64, arg3 string) (*models.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockBalanceInterfaceMockRecorder) Get(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBalanceInterface)(nil).Get), arg0, arg1, arg2, arg3)
+}
+
+// List mocks base method.
+func (m *MockBalanceInterface) List(arg0 context.Context, arg1 *gorm.DB, arg2 int64) ([]*models.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockBalanceInterfaceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockBalanceInterface)(nil).List), arg0, arg1, arg2)
+}
+
+// Lock mocks base method.
+func (m *MockBalanceInterface) Lock(arg0 context.Context, arg1 *gorm.DB, arg2 int64, arg3 string, arg4 models.Decimal) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockBalanceInterfaceMockRecorder) Lock(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockBalanceInterface)(nil).Lock), arg0, arg1, arg2, arg3, arg4)
+}
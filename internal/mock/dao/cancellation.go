@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/cancellation.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockCancellationInterface is a mock of CancellationInterface interface.
+type MockCancellationInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockCancellationInterfaceMockRecorder
+}
+
+// MockCancellationInterfaceMockRecorder is the mock recorder for MockCancellationInterface.
+type MockCancellationInterfaceMockRecorder struct {
+	mock *MockCancellationInterface
+}
+
+// NewMockCancellationInterface creates a new mock instance.
+func NewMockCancellationInterface(ctrl *gomock.Controller) *MockCancellationInterface {
+	mock := &MockCancellationInterface{ctrl: ctrl}
+	mock.recorder = &MockCancellationInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCancellationInterface) EXPECT() *MockCancellationInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockCancellationInterface) Insert(arg0 context.Context, arg1 *gorm.DB, arg2 []*models.Cancellation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCancellationInterfaceMockRecorder) Insert(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCancellationInterface)(nil).Insert), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockCancellationInterface) List(arg0 context.Context, arg1 *gorm.DB, arg2 int64) ([]*models.Cancellation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Cancellation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCancellationInterfaceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCancellationInterface)(nil).List), arg0, arg1, arg2)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/candle.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockCandleInterface is a mock of CandleInterface interface.
+type MockCandleInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockCandleInterfaceMockRecorder
+}
+
+// MockCandleInterfaceMockRecorder is the mock recorder for MockCandleInterface.
+type MockCandleInterfaceMockRecorder struct {
+	mock *MockCandleInterface
+}
+
+// NewMockCandleInterface creates a new mock instance.
+func NewMockCandleInterface(ctrl *gomock.Controller) *MockCandleInterface {
+	mock := &MockCandleInterface{ctrl: ctrl}
+	mock.recorder = &MockCandleInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCandleInterface) EXPECT() *MockCandleInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCandleInterface) Delete(arg0 context.Context, arg1 *gorm.DB, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCandleInterfaceMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCandleInterface)(nil).Delete), arg0, arg1, arg2)
+}
+
+// List mocks base method.
+func (m *MockCandleInterface) List(arg0 context.Context, arg1 *gorm.DB, arg2 *models.CandleQuery) ([]*models.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCandleInterfaceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCandleInterface)(nil).List), arg0, arg1, arg2)
+}
+
+// Upsert mocks base method.
+func (m *MockCandleInterface) Upsert(arg0 context.Context, arg1 *gorm.DB, arg2 []*models.Candle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockCandleInterfaceMockRecorder) Upsert(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockCandleInterface)(nil).Upsert), arg0, arg1, arg2)
+}
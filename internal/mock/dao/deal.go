@@ -7,6 +7,7 @@ package dao
 import (
 	models "dealer/internal/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	context "golang.org/x/net/context"
@@ -36,6 +37,21 @@ func (m *MockDealInterface) EXPECT() *MockDealInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AccountVolumes mocks base method.
+func (m *MockDealInterface) AccountVolumes(arg0 context.Context, arg1 *gorm.DB, arg2 time.Time) ([]*models.AccountVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountVolumes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.AccountVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccountVolumes indicates an expected call of AccountVolumes.
+func (mr *MockDealInterfaceMockRecorder) AccountVolumes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountVolumes", reflect.TypeOf((*MockDealInterface)(nil).AccountVolumes), arg0, arg1, arg2)
+}
+
 // Insert mocks base method.
 func (m *MockDealInterface) Insert(arg0 context.Context, arg1 *gorm.DB, arg2 []*models.Deal) error {
 	m.ctrl.T.Helper()
@@ -50,6 +66,21 @@ func (mr *MockDealInterfaceMockRecorder) Insert(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockDealInterface)(nil).Insert), arg0, arg1, arg2)
 }
 
+// Last mocks base method.
+func (m *MockDealInterface) Last(arg0 context.Context, arg1 *gorm.DB, arg2 string) (*models.Deal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Last", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Deal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Last indicates an expected call of Last.
+func (mr *MockDealInterfaceMockRecorder) Last(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Last", reflect.TypeOf((*MockDealInterface)(nil).Last), arg0, arg1, arg2)
+}
+
 // List mocks base method.
 func (m *MockDealInterface) List(arg0 context.Context, arg1 *gorm.DB, arg2 *models.Deal) ([]*models.Deal, error) {
 	m.ctrl.T.Helper()
@@ -64,3 +95,33 @@ func (mr *MockDealInterfaceMockRecorder) List(arg0, arg1, arg2 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDealInterface)(nil).List), arg0, arg1, arg2)
 }
+
+// ListAfter mocks base method.
+func (m *MockDealInterface) ListAfter(arg0 context.Context, arg1 *gorm.DB, arg2 string, arg3 int64, arg4 int) ([]*models.Deal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAfter", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*models.Deal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAfter indicates an expected call of ListAfter.
+func (mr *MockDealInterfaceMockRecorder) ListAfter(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAfter", reflect.TypeOf((*MockDealInterface)(nil).ListAfter), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Stats mocks base method.
+func (m *MockDealInterface) Stats(arg0 context.Context, arg1 *gorm.DB, arg2 string, arg3 time.Time) (*models.DealStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.DealStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockDealInterfaceMockRecorder) Stats(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockDealInterface)(nil).Stats), arg0, arg1, arg2, arg3)
+}
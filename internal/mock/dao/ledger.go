@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/ledger.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockLedgerInterface is a mock of LedgerInterface interface.
+type MockLedgerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerInterfaceMockRecorder
+}
+
+// MockLedgerInterfaceMockRecorder is the mock recorder for MockLedgerInterface.
+type MockLedgerInterfaceMockRecorder struct {
+	mock *MockLedgerInterface
+}
+
+// NewMockLedgerInterface creates a new mock instance.
+func NewMockLedgerInterface(ctrl *gomock.Controller) *MockLedgerInterface {
+	mock := &MockLedgerInterface{ctrl: ctrl}
+	mock.recorder = &MockLedgerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerInterface) EXPECT() *MockLedgerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockLedgerInterface) Insert(arg0 context.Context, arg1 *gorm.DB, arg2 []*models.LedgerEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockLedgerInterfaceMockRecorder) Insert(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockLedgerInterface)(nil).Insert), arg0, arg1, arg2)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/market_state.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockMarketStateInterface is a mock of MarketStateInterface interface.
+type MockMarketStateInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMarketStateInterfaceMockRecorder
+}
+
+// MockMarketStateInterfaceMockRecorder is the mock recorder for MockMarketStateInterface.
+type MockMarketStateInterfaceMockRecorder struct {
+	mock *MockMarketStateInterface
+}
+
+// NewMockMarketStateInterface creates a new mock instance.
+func NewMockMarketStateInterface(ctrl *gomock.Controller) *MockMarketStateInterface {
+	mock := &MockMarketStateInterface{ctrl: ctrl}
+	mock.recorder = &MockMarketStateInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMarketStateInterface) EXPECT() *MockMarketStateInterfaceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockMarketStateInterface) List(arg0 context.Context, arg1 *gorm.DB) ([]*models.MarketState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*models.MarketState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockMarketStateInterfaceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockMarketStateInterface)(nil).List), arg0, arg1)
+}
+
+// ListResumable mocks base method.
+func (m *MockMarketStateInterface) ListResumable(arg0 context.Context, arg1 *gorm.DB, arg2 time.Time) ([]*models.MarketState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListResumable", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.MarketState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListResumable indicates an expected call of ListResumable.
+func (mr *MockMarketStateInterfaceMockRecorder) ListResumable(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResumable", reflect.TypeOf((*MockMarketStateInterface)(nil).ListResumable), arg0, arg1, arg2)
+}
+
+// Upsert mocks base method.
+func (m *MockMarketStateInterface) Upsert(arg0 context.Context, arg1 *gorm.DB, arg2 *models.MarketState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockMarketStateInterfaceMockRecorder) Upsert(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockMarketStateInterface)(nil).Upsert), arg0, arg1, arg2)
+}
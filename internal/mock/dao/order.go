@@ -7,6 +7,7 @@ package dao
 import (
 	models "dealer/internal/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	context "golang.org/x/net/context"
@@ -50,6 +51,21 @@ func (mr *MockOrderInterfaceMockRecorder) BulkUpdate(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdate", reflect.TypeOf((*MockOrderInterface)(nil).BulkUpdate), arg0, arg1, arg2)
 }
 
+// Get mocks base method.
+func (m *MockOrderInterface) Get(arg0 context.Context, arg1 *gorm.DB, arg2 int64) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrderInterfaceMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderInterface)(nil).Get), arg0, arg1, arg2)
+}
+
 // Insert mocks base method.
 func (m *MockOrderInterface) Insert(arg0 context.Context, arg1 *gorm.DB, arg2 *models.Order) error {
 	m.ctrl.T.Helper()
@@ -64,6 +80,81 @@ func (mr *MockOrderInterfaceMockRecorder) Insert(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockOrderInterface)(nil).Insert), arg0, arg1, arg2)
 }
 
+// List mocks base method.
+func (m *MockOrderInterface) List(arg0 context.Context, arg1 *gorm.DB, arg2 *models.OrderQuery) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrderInterfaceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderInterface)(nil).List), arg0, arg1, arg2)
+}
+
+// ListExpired mocks base method.
+func (m *MockOrderInterface) ListExpired(arg0 context.Context, arg1 *gorm.DB, arg2 time.Time) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockOrderInterfaceMockRecorder) ListExpired(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockOrderInterface)(nil).ListExpired), arg0, arg1, arg2)
+}
+
+// ListOpen mocks base method.
+func (m *MockOrderInterface) ListOpen(arg0 context.Context, arg1 *gorm.DB) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpen", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpen indicates an expected call of ListOpen.
+func (mr *MockOrderInterfaceMockRecorder) ListOpen(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpen", reflect.TypeOf((*MockOrderInterface)(nil).ListOpen), arg0, arg1)
+}
+
+// ListOpenBySymbol mocks base method.
+func (m *MockOrderInterface) ListOpenBySymbol(arg0 context.Context, arg1 *gorm.DB, arg2 string) ([]*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenBySymbol", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenBySymbol indicates an expected call of ListOpenBySymbol.
+func (mr *MockOrderInterfaceMockRecorder) ListOpenBySymbol(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenBySymbol", reflect.TypeOf((*MockOrderInterface)(nil).ListOpenBySymbol), arg0, arg1, arg2)
+}
+
+// MaxSequence mocks base method.
+func (m *MockOrderInterface) MaxSequence(arg0 context.Context, arg1 *gorm.DB) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxSequence", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaxSequence indicates an expected call of MaxSequence.
+func (mr *MockOrderInterfaceMockRecorder) MaxSequence(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxSequence", reflect.TypeOf((*MockOrderInterface)(nil).MaxSequence), arg0, arg1)
+}
+
 // Update mocks base method.
 func (m *MockOrderInterface) Update(arg0 context.Context, arg1 *gorm.DB, arg2 *models.Order) error {
 	m.ctrl.T.Helper()
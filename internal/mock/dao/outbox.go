@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/dao/outbox.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	context "golang.org/x/net/context"
+	gorm "gorm.io/gorm"
+)
+
+// MockOutboxInterface is a mock of OutboxInterface interface.
+type MockOutboxInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxInterfaceMockRecorder
+}
+
+// MockOutboxInterfaceMockRecorder is the mock recorder for MockOutboxInterface.
+type MockOutboxInterfaceMockRecorder struct {
+	mock *MockOutboxInterface
+}
+
+// NewMockOutboxInterface creates a new mock instance.
+func NewMockOutboxInterface(ctrl *gomock.Controller) *MockOutboxInterface {
+	mock := &MockOutboxInterface{ctrl: ctrl}
+	mock.recorder = &MockOutboxInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxInterface) EXPECT() *MockOutboxInterfaceMockRecorder {
+	return m.recorder
+}
+
+// IncreaseAttempts mocks base method.
+func (m *MockOutboxInterface) IncreaseAttempts(arg0 context.Context, arg1 *gorm.DB, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncreaseAttempts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncreaseAttempts indicates an expected call of IncreaseAttempts.
+func (mr *MockOutboxInterfaceMockRecorder) IncreaseAttempts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseAttempts", reflect.TypeOf((*MockOutboxInterface)(nil).IncreaseAttempts), arg0, arg1, arg2)
+}
+
+// Insert mocks base method.
+func (m *MockOutboxInterface) Insert(arg0 context.Context, arg1 *gorm.DB, arg2 *models.Outbox) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockOutboxInterfaceMockRecorder) Insert(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockOutboxInterface)(nil).Insert), arg0, arg1, arg2)
+}
+
+// LastSequence mocks base method.
+func (m *MockOutboxInterface) LastSequence(arg0 context.Context, arg1 *gorm.DB) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastSequence", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastSequence indicates an expected call of LastSequence.
+func (mr *MockOutboxInterfaceMockRecorder) LastSequence(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastSequence", reflect.TypeOf((*MockOutboxInterface)(nil).LastSequence), arg0, arg1)
+}
+
+// ListPending mocks base method.
+func (m *MockOutboxInterface) ListPending(arg0 context.Context, arg1 *gorm.DB, arg2 int) ([]*models.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockOutboxInterfaceMockRecorder) ListPending(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockOutboxInterface)(nil).ListPending), arg0, arg1, arg2)
+}
+
+// ListUnsequenced mocks base method.
+func (m *MockOutboxInterface) ListUnsequenced(arg0 context.Context, arg1 *gorm.DB, arg2 int) ([]*models.Outbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnsequenced", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Outbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnsequenced indicates an expected call of ListUnsequenced.
+func (mr *MockOutboxInterfaceMockRecorder) ListUnsequenced(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnsequenced", reflect.TypeOf((*MockOutboxInterface)(nil).ListUnsequenced), arg0, arg1, arg2)
+}
+
+// MarkSent mocks base method.
+func (m *MockOutboxInterface) MarkSent(arg0 context.Context, arg1 *gorm.DB, arg2 int64, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockOutboxInterfaceMockRecorder) MarkSent(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockOutboxInterface)(nil).MarkSent), arg0, arg1, arg2, arg3)
+}
+
+// SetSequence mocks base method.
+func (m *MockOutboxInterface) SetSequence(arg0 context.Context, arg1 *gorm.DB, arg2, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSequence", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSequence indicates an expected call of SetSequence.
+func (mr *MockOutboxInterfaceMockRecorder) SetSequence(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSequence", reflect.TypeOf((*MockOutboxInterface)(nil).SetSequence), arg0, arg1, arg2, arg3)
+}
@@ -50,6 +50,36 @@ func (mr *MockAMQPChannelMockRecorder) Consume(queue, consumer, autoAck, exclusi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockAMQPChannel)(nil).Consume), queue, consumer, autoAck, exclusive, noLocal, noWait, args)
 }
 
+// Get mocks base method.
+func (m *MockAMQPChannel) Get(queue string, autoAck bool) (amqp091.Delivery, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", queue, autoAck)
+	ret0, _ := ret[0].(amqp091.Delivery)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAMQPChannelMockRecorder) Get(queue, autoAck interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAMQPChannel)(nil).Get), queue, autoAck)
+}
+
+// NotifyPublish mocks base method.
+func (m *MockAMQPChannel) NotifyPublish(confirm chan amqp091.Confirmation) chan amqp091.Confirmation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyPublish", confirm)
+	ret0, _ := ret[0].(chan amqp091.Confirmation)
+	return ret0
+}
+
+// NotifyPublish indicates an expected call of NotifyPublish.
+func (mr *MockAMQPChannelMockRecorder) NotifyPublish(confirm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyPublish", reflect.TypeOf((*MockAMQPChannel)(nil).NotifyPublish), confirm)
+}
+
 // PublishWithContext mocks base method.
 func (m *MockAMQPChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp091.Publishing) error {
 	m.ctrl.T.Helper()
@@ -63,3 +93,82 @@ func (mr *MockAMQPChannelMockRecorder) PublishWithContext(ctx, exchange, key, ma
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithContext", reflect.TypeOf((*MockAMQPChannel)(nil).PublishWithContext), ctx, exchange, key, mandatory, immediate, msg)
 }
+
+// Qos mocks base method.
+func (m *MockAMQPChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Qos", prefetchCount, prefetchSize, global)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Qos indicates an expected call of Qos.
+func (mr *MockAMQPChannelMockRecorder) Qos(prefetchCount, prefetchSize, global interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Qos", reflect.TypeOf((*MockAMQPChannel)(nil).Qos), prefetchCount, prefetchSize, global)
+}
+
+// MockAMQPAcknowledger is a mock of AMQPAcknowledger interface.
+type MockAMQPAcknowledger struct {
+	ctrl     *gomock.Controller
+	recorder *MockAMQPAcknowledgerMockRecorder
+}
+
+// MockAMQPAcknowledgerMockRecorder is the mock recorder for MockAMQPAcknowledger.
+type MockAMQPAcknowledgerMockRecorder struct {
+	mock *MockAMQPAcknowledger
+}
+
+// NewMockAMQPAcknowledger creates a new mock instance.
+func NewMockAMQPAcknowledger(ctrl *gomock.Controller) *MockAMQPAcknowledger {
+	mock := &MockAMQPAcknowledger{ctrl: ctrl}
+	mock.recorder = &MockAMQPAcknowledgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAMQPAcknowledger) EXPECT() *MockAMQPAcknowledgerMockRecorder {
+	return m.recorder
+}
+
+// Ack mocks base method.
+func (m *MockAMQPAcknowledger) Ack(tag uint64, multiple bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ack", tag, multiple)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ack indicates an expected call of Ack.
+func (mr *MockAMQPAcknowledgerMockRecorder) Ack(tag, multiple interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockAMQPAcknowledger)(nil).Ack), tag, multiple)
+}
+
+// Nack mocks base method.
+func (m *MockAMQPAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nack", tag, multiple, requeue)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Nack indicates an expected call of Nack.
+func (mr *MockAMQPAcknowledgerMockRecorder) Nack(tag, multiple, requeue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nack", reflect.TypeOf((*MockAMQPAcknowledger)(nil).Nack), tag, multiple, requeue)
+}
+
+// Reject mocks base method.
+func (m *MockAMQPAcknowledger) Reject(tag uint64, requeue bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", tag, requeue)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockAMQPAcknowledgerMockRecorder) Reject(tag, requeue interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockAMQPAcknowledger)(nil).Reject), tag, requeue)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/candle.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCandleBackfillInterface is a mock of CandleBackfillInterface interface.
+type MockCandleBackfillInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockCandleBackfillInterfaceMockRecorder
+}
+
+// MockCandleBackfillInterfaceMockRecorder is the mock recorder for MockCandleBackfillInterface.
+type MockCandleBackfillInterfaceMockRecorder struct {
+	mock *MockCandleBackfillInterface
+}
+
+// NewMockCandleBackfillInterface creates a new mock instance.
+func NewMockCandleBackfillInterface(ctrl *gomock.Controller) *MockCandleBackfillInterface {
+	mock := &MockCandleBackfillInterface{ctrl: ctrl}
+	mock.recorder = &MockCandleBackfillInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCandleBackfillInterface) EXPECT() *MockCandleBackfillInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Backfill mocks base method.
+func (m *MockCandleBackfillInterface) Backfill(ctx context.Context, symbol string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backfill", ctx, symbol)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backfill indicates an expected call of Backfill.
+func (mr *MockCandleBackfillInterfaceMockRecorder) Backfill(ctx, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backfill", reflect.TypeOf((*MockCandleBackfillInterface)(nil).Backfill), ctx, symbol)
+}
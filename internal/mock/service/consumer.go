@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/consumer.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+)
+
+// MockConsumerInterface is a mock of ConsumerInterface interface.
+type MockConsumerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsumerInterfaceMockRecorder
+}
+
+// MockConsumerInterfaceMockRecorder is the mock recorder for MockConsumerInterface.
+type MockConsumerInterfaceMockRecorder struct {
+	mock *MockConsumerInterface
+}
+
+// NewMockConsumerInterface creates a new mock instance.
+func NewMockConsumerInterface(ctrl *gomock.Controller) *MockConsumerInterface {
+	mock := &MockConsumerInterface{ctrl: ctrl}
+	mock.recorder = &MockConsumerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsumerInterface) EXPECT() *MockConsumerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Handle mocks base method.
+func (m *MockConsumerInterface) Handle(arg0 context.Context, arg1 amqp091.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Handle", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Handle indicates an expected call of Handle.
+func (mr *MockConsumerInterfaceMockRecorder) Handle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockConsumerInterface)(nil).Handle), arg0, arg1)
+}
+
+// Run mocks base method.
+func (m *MockConsumerInterface) Run(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockConsumerInterfaceMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockConsumerInterface)(nil).Run), arg0)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/deadletter.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDeadLetterInterface is a mock of DeadLetterInterface interface.
+type MockDeadLetterInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterInterfaceMockRecorder
+}
+
+// MockDeadLetterInterfaceMockRecorder is the mock recorder for MockDeadLetterInterface.
+type MockDeadLetterInterfaceMockRecorder struct {
+	mock *MockDeadLetterInterface
+}
+
+// NewMockDeadLetterInterface creates a new mock instance.
+func NewMockDeadLetterInterface(ctrl *gomock.Controller) *MockDeadLetterInterface {
+	mock := &MockDeadLetterInterface{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterInterface) EXPECT() *MockDeadLetterInterfaceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockDeadLetterInterface) List(ctx context.Context, limit int) ([]*models.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, limit)
+	ret0, _ := ret[0].([]*models.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockDeadLetterInterfaceMockRecorder) List(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDeadLetterInterface)(nil).List), ctx, limit)
+}
+
+// Replay mocks base method.
+func (m *MockDeadLetterInterface) Replay(ctx context.Context, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockDeadLetterInterfaceMockRecorder) Replay(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockDeadLetterInterface)(nil).Replay), ctx, limit)
+}
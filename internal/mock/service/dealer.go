@@ -35,6 +35,76 @@ func (m *MockDealerInterface) EXPECT() *MockDealerInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AmendOrder mocks base method.
+func (m *MockDealerInterface) AmendOrder(arg0 context.Context, arg1 *models.Amendment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AmendOrder", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AmendOrder indicates an expected call of AmendOrder.
+func (mr *MockDealerInterfaceMockRecorder) AmendOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AmendOrder", reflect.TypeOf((*MockDealerInterface)(nil).AmendOrder), arg0, arg1)
+}
+
+// CancelAll mocks base method.
+func (m *MockDealerInterface) CancelAll(arg0 context.Context, arg1 *models.MassCancel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelAll", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelAll indicates an expected call of CancelAll.
+func (mr *MockDealerInterfaceMockRecorder) CancelAll(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelAll", reflect.TypeOf((*MockDealerInterface)(nil).CancelAll), arg0, arg1)
+}
+
+// ChangePhase mocks base method.
+func (m *MockDealerInterface) ChangePhase(arg0 context.Context, arg1 *models.MarketState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePhase", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePhase indicates an expected call of ChangePhase.
+func (mr *MockDealerInterfaceMockRecorder) ChangePhase(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePhase", reflect.TypeOf((*MockDealerInterface)(nil).ChangePhase), arg0, arg1)
+}
+
+// HaltMarket mocks base method.
+func (m *MockDealerInterface) HaltMarket(arg0 context.Context, arg1 *models.MarketState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HaltMarket", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HaltMarket indicates an expected call of HaltMarket.
+func (mr *MockDealerInterfaceMockRecorder) HaltMarket(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HaltMarket", reflect.TypeOf((*MockDealerInterface)(nil).HaltMarket), arg0, arg1)
+}
+
+// ProcessMessage mocks base method.
+func (m *MockDealerInterface) ProcessMessage(arg0 context.Context, arg1 *models.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessMessage indicates an expected call of ProcessMessage.
+func (mr *MockDealerInterfaceMockRecorder) ProcessMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessMessage", reflect.TypeOf((*MockDealerInterface)(nil).ProcessMessage), arg0, arg1)
+}
+
 // ProcessOrder mocks base method.
 func (m *MockDealerInterface) ProcessOrder(arg0 context.Context, arg1 *models.Order) error {
 	m.ctrl.T.Helper()
@@ -48,3 +118,31 @@ func (mr *MockDealerInterfaceMockRecorder) ProcessOrder(arg0, arg1 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOrder", reflect.TypeOf((*MockDealerInterface)(nil).ProcessOrder), arg0, arg1)
 }
+
+// Recover mocks base method.
+func (m *MockDealerInterface) Recover(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recover", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Recover indicates an expected call of Recover.
+func (mr *MockDealerInterfaceMockRecorder) Recover(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recover", reflect.TypeOf((*MockDealerInterface)(nil).Recover), arg0)
+}
+
+// ResumeMarket mocks base method.
+func (m *MockDealerInterface) ResumeMarket(arg0 context.Context, arg1 *models.MarketState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeMarket", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeMarket indicates an expected call of ResumeMarket.
+func (mr *MockDealerInterfaceMockRecorder) ResumeMarket(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeMarket", reflect.TypeOf((*MockDealerInterface)(nil).ResumeMarket), arg0, arg1)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/fee.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	models "dealer/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFeeCalculatorInterface is a mock of FeeCalculatorInterface interface.
+type MockFeeCalculatorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeeCalculatorInterfaceMockRecorder
+}
+
+// MockFeeCalculatorInterfaceMockRecorder is the mock recorder for MockFeeCalculatorInterface.
+type MockFeeCalculatorInterfaceMockRecorder struct {
+	mock *MockFeeCalculatorInterface
+}
+
+// NewMockFeeCalculatorInterface creates a new mock instance.
+func NewMockFeeCalculatorInterface(ctrl *gomock.Controller) *MockFeeCalculatorInterface {
+	mock := &MockFeeCalculatorInterface{ctrl: ctrl}
+	mock.recorder = &MockFeeCalculatorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeeCalculatorInterface) EXPECT() *MockFeeCalculatorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Rates mocks base method.
+func (m *MockFeeCalculatorInterface) Rates(userID int64, quoteAsset string) (models.Decimal, models.Decimal) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rates", userID, quoteAsset)
+	ret0, _ := ret[0].(models.Decimal)
+	ret1, _ := ret[1].(models.Decimal)
+	return ret0, ret1
+}
+
+// Rates indicates an expected call of Rates.
+func (mr *MockFeeCalculatorInterfaceMockRecorder) Rates(userID, quoteAsset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rates", reflect.TypeOf((*MockFeeCalculatorInterface)(nil).Rates), userID, quoteAsset)
+}
+
+// Record mocks base method.
+func (m *MockFeeCalculatorInterface) Record(deals []*models.Deal) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", deals)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockFeeCalculatorInterfaceMockRecorder) Record(deals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockFeeCalculatorInterface)(nil).Record), deals)
+}
+
+// Refresh mocks base method.
+func (m *MockFeeCalculatorInterface) Refresh(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockFeeCalculatorInterfaceMockRecorder) Refresh(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockFeeCalculatorInterface)(nil).Refresh), ctx, now)
+}
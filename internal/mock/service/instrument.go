@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/instrument.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockInstrumentRegistryInterface is a mock of InstrumentRegistryInterface interface.
+type MockInstrumentRegistryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockInstrumentRegistryInterfaceMockRecorder
+}
+
+// MockInstrumentRegistryInterfaceMockRecorder is the mock recorder for MockInstrumentRegistryInterface.
+type MockInstrumentRegistryInterfaceMockRecorder struct {
+	mock *MockInstrumentRegistryInterface
+}
+
+// NewMockInstrumentRegistryInterface creates a new mock instance.
+func NewMockInstrumentRegistryInterface(ctrl *gomock.Controller) *MockInstrumentRegistryInterface {
+	mock := &MockInstrumentRegistryInterface{ctrl: ctrl}
+	mock.recorder = &MockInstrumentRegistryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInstrumentRegistryInterface) EXPECT() *MockInstrumentRegistryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockInstrumentRegistryInterface) Get(arg0 string) (*models.Instrument, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*models.Instrument)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInstrumentRegistryInterfaceMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInstrumentRegistryInterface)(nil).Get), arg0)
+}
+
+// List mocks base method.
+func (m *MockInstrumentRegistryInterface) List() []*models.Instrument {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]*models.Instrument)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockInstrumentRegistryInterfaceMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockInstrumentRegistryInterface)(nil).List))
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/ledger.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	gorm "gorm.io/gorm"
+)
+
+// MockLedgerInterface is a mock of LedgerInterface interface.
+type MockLedgerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerInterfaceMockRecorder
+}
+
+// MockLedgerInterfaceMockRecorder is the mock recorder for MockLedgerInterface.
+type MockLedgerInterfaceMockRecorder struct {
+	mock *MockLedgerInterface
+}
+
+// NewMockLedgerInterface creates a new mock instance.
+func NewMockLedgerInterface(ctrl *gomock.Controller) *MockLedgerInterface {
+	mock := &MockLedgerInterface{ctrl: ctrl}
+	mock.recorder = &MockLedgerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerInterface) EXPECT() *MockLedgerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Deposit mocks base method.
+func (m *MockLedgerInterface) Deposit(ctx context.Context, userID int64, asset string, amount models.Decimal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", ctx, userID, asset, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockLedgerInterfaceMockRecorder) Deposit(ctx, userID, asset, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockLedgerInterface)(nil).Deposit), ctx, userID, asset, amount)
+}
+
+// Lock mocks base method.
+func (m *MockLedgerInterface) Lock(ctx context.Context, tx *gorm.DB, userID, orderID int64, asset string, amount models.Decimal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, tx, userID, orderID, asset, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLedgerInterfaceMockRecorder) Lock(ctx, tx, userID, orderID, asset, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLedgerInterface)(nil).Lock), ctx, tx, userID, orderID, asset, amount)
+}
+
+// Post mocks base method.
+func (m *MockLedgerInterface) Post(ctx context.Context, tx *gorm.DB, entries []*models.LedgerEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Post", ctx, tx, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Post indicates an expected call of Post.
+func (mr *MockLedgerInterfaceMockRecorder) Post(ctx, tx, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockLedgerInterface)(nil).Post), ctx, tx, entries)
+}
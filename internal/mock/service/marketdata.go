@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/marketdata.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMarketDataInterface is a mock of MarketDataInterface interface.
+type MockMarketDataInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMarketDataInterfaceMockRecorder
+}
+
+// MockMarketDataInterfaceMockRecorder is the mock recorder for MockMarketDataInterface.
+type MockMarketDataInterfaceMockRecorder struct {
+	mock *MockMarketDataInterface
+}
+
+// NewMockMarketDataInterface creates a new mock instance.
+func NewMockMarketDataInterface(ctrl *gomock.Controller) *MockMarketDataInterface {
+	mock := &MockMarketDataInterface{ctrl: ctrl}
+	mock.recorder = &MockMarketDataInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMarketDataInterface) EXPECT() *MockMarketDataInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Depth mocks base method.
+func (m *MockMarketDataInterface) Depth(symbol string, levels int) (*models.Depth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depth", symbol, levels)
+	ret0, _ := ret[0].(*models.Depth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Depth indicates an expected call of Depth.
+func (mr *MockMarketDataInterfaceMockRecorder) Depth(symbol, levels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depth", reflect.TypeOf((*MockMarketDataInterface)(nil).Depth), symbol, levels)
+}
+
+// Publish mocks base method.
+func (m *MockMarketDataInterface) Publish(symbol string, update *models.MarketUpdate) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", symbol, update)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockMarketDataInterfaceMockRecorder) Publish(symbol, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockMarketDataInterface)(nil).Publish), symbol, update)
+}
+
+// Subscribe mocks base method.
+func (m *MockMarketDataInterface) Subscribe(channel string, c chan []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", channel, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockMarketDataInterfaceMockRecorder) Subscribe(channel, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockMarketDataInterface)(nil).Subscribe), channel, c)
+}
+
+// Ticker mocks base method.
+func (m *MockMarketDataInterface) Ticker(symbol string) (*models.Ticker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ticker", symbol)
+	ret0, _ := ret[0].(*models.Ticker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ticker indicates an expected call of Ticker.
+func (mr *MockMarketDataInterfaceMockRecorder) Ticker(symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ticker", reflect.TypeOf((*MockMarketDataInterface)(nil).Ticker), symbol)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockMarketDataInterface) Unsubscribe(channel string, c chan []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Unsubscribe", channel, c)
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockMarketDataInterfaceMockRecorder) Unsubscribe(channel, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockMarketDataInterface)(nil).Unsubscribe), channel, c)
+}
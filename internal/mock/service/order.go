@@ -8,6 +8,7 @@ import (
 	context "context"
 	models "dealer/internal/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,18 +36,75 @@ func (m *MockOrderProcessorInterface) EXPECT() *MockOrderProcessorInterfaceMockR
 	return m.recorder
 }
 
+// AmendOrder mocks base method.
+func (m *MockOrderProcessorInterface) AmendOrder(arg0 context.Context, arg1 int64, arg2 *models.Amendment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AmendOrder", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AmendOrder indicates an expected call of AmendOrder.
+func (mr *MockOrderProcessorInterfaceMockRecorder) AmendOrder(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AmendOrder", reflect.TypeOf((*MockOrderProcessorInterface)(nil).AmendOrder), arg0, arg1, arg2)
+}
+
+// CancelAll mocks base method.
+func (m *MockOrderProcessorInterface) CancelAll(arg0 context.Context, arg1 *models.MassCancel) ([]*models.MassCancel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelAll", arg0, arg1)
+	ret0, _ := ret[0].([]*models.MassCancel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelAll indicates an expected call of CancelAll.
+func (mr *MockOrderProcessorInterfaceMockRecorder) CancelAll(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelAll", reflect.TypeOf((*MockOrderProcessorInterface)(nil).CancelAll), arg0, arg1)
+}
+
 // CancelOrder mocks base method.
-func (m *MockOrderProcessorInterface) CancelOrder(arg0 context.Context, arg1 int64) error {
+func (m *MockOrderProcessorInterface) CancelOrder(arg0 context.Context, arg1 int64, arg2 string, arg3 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CancelOrder", arg0, arg1)
+	ret := m.ctrl.Call(m, "CancelOrder", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CancelOrder indicates an expected call of CancelOrder.
-func (mr *MockOrderProcessorInterfaceMockRecorder) CancelOrder(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockOrderProcessorInterfaceMockRecorder) CancelOrder(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderProcessorInterface)(nil).CancelOrder), arg0, arg1, arg2, arg3)
+}
+
+// ChangePhase mocks base method.
+func (m *MockOrderProcessorInterface) ChangePhase(arg0 context.Context, arg1 string, arg2 models.TradingPhase) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePhase", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePhase indicates an expected call of ChangePhase.
+func (mr *MockOrderProcessorInterfaceMockRecorder) ChangePhase(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderProcessorInterface)(nil).CancelOrder), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePhase", reflect.TypeOf((*MockOrderProcessorInterface)(nil).ChangePhase), arg0, arg1, arg2)
+}
+
+// HaltMarket mocks base method.
+func (m *MockOrderProcessorInterface) HaltMarket(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HaltMarket", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HaltMarket indicates an expected call of HaltMarket.
+func (mr *MockOrderProcessorInterfaceMockRecorder) HaltMarket(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HaltMarket", reflect.TypeOf((*MockOrderProcessorInterface)(nil).HaltMarket), arg0, arg1)
 }
 
 // NewOrder mocks base method.
@@ -62,3 +120,17 @@ func (mr *MockOrderProcessorInterfaceMockRecorder) NewOrder(arg0, arg1 interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewOrder", reflect.TypeOf((*MockOrderProcessorInterface)(nil).NewOrder), arg0, arg1)
 }
+
+// ResumeMarket mocks base method.
+func (m *MockOrderProcessorInterface) ResumeMarket(arg0 context.Context, arg1 string, arg2 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeMarket", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeMarket indicates an expected call of ResumeMarket.
+func (mr *MockOrderProcessorInterfaceMockRecorder) ResumeMarket(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeMarket", reflect.TypeOf((*MockOrderProcessorInterface)(nil).ResumeMarket), arg0, arg1, arg2)
+}
@@ -46,6 +46,20 @@ func (mr *MockOrderBookInterfaceMockRecorder) AddOrder(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrderBookInterface)(nil).AddOrder), arg0)
 }
 
+// Depth mocks base method.
+func (m *MockOrderBookInterface) Depth(arg0 int) []*models.PriceLevel {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depth", arg0)
+	ret0, _ := ret[0].([]*models.PriceLevel)
+	return ret0
+}
+
+// Depth indicates an expected call of Depth.
+func (mr *MockOrderBookInterfaceMockRecorder) Depth(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depth", reflect.TypeOf((*MockOrderBookInterface)(nil).Depth), arg0)
+}
+
 // Dequeue mocks base method.
 func (m *MockOrderBookInterface) Dequeue() *models.Order {
 	m.ctrl.T.Helper()
@@ -60,6 +74,20 @@ func (mr *MockOrderBookInterfaceMockRecorder) Dequeue() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dequeue", reflect.TypeOf((*MockOrderBookInterface)(nil).Dequeue))
 }
 
+// Get mocks base method.
+func (m *MockOrderBookInterface) Get(arg0 int64) *models.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*models.Order)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrderBookInterfaceMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderBookInterface)(nil).Get), arg0)
+}
+
 // Peek mocks base method.
 func (m *MockOrderBookInterface) Peek() *models.Order {
 	m.ctrl.T.Helper()
@@ -74,6 +102,18 @@ func (mr *MockOrderBookInterfaceMockRecorder) Peek() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peek", reflect.TypeOf((*MockOrderBookInterface)(nil).Peek))
 }
 
+// Range mocks base method.
+func (m *MockOrderBookInterface) Range(arg0 func(*models.Order) bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Range", arg0)
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockOrderBookInterfaceMockRecorder) Range(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockOrderBookInterface)(nil).Range), arg0)
+}
+
 // RemoveOrder mocks base method.
 func (m *MockOrderBookInterface) RemoveOrder(arg0 int64) {
 	m.ctrl.T.Helper()
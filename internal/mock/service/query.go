@@ -0,0 +1,158 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/query.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	models "dealer/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockQueryInterface is a mock of QueryInterface interface.
+type MockQueryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockQueryInterfaceMockRecorder
+}
+
+// MockQueryInterfaceMockRecorder is the mock recorder for MockQueryInterface.
+type MockQueryInterfaceMockRecorder struct {
+	mock *MockQueryInterface
+}
+
+// NewMockQueryInterface creates a new mock instance.
+func NewMockQueryInterface(ctrl *gomock.Controller) *MockQueryInterface {
+	mock := &MockQueryInterface{ctrl: ctrl}
+	mock.recorder = &MockQueryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQueryInterface) EXPECT() *MockQueryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Book mocks base method.
+func (m *MockQueryInterface) Book(arg0 context.Context, arg1 string) (*models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Book", arg0, arg1)
+	ret0, _ := ret[0].(*models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Book indicates an expected call of Book.
+func (mr *MockQueryInterfaceMockRecorder) Book(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Book", reflect.TypeOf((*MockQueryInterface)(nil).Book), arg0, arg1)
+}
+
+// Depth mocks base method.
+func (m *MockQueryInterface) Depth(arg0 context.Context, arg1 string, arg2 int) (*models.Depth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depth", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Depth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Depth indicates an expected call of Depth.
+func (mr *MockQueryInterfaceMockRecorder) Depth(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depth", reflect.TypeOf((*MockQueryInterface)(nil).Depth), arg0, arg1, arg2)
+}
+
+// GetOrder mocks base method.
+func (m *MockQueryInterface) GetOrder(arg0 context.Context, arg1, arg2 int64) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockQueryInterfaceMockRecorder) GetOrder(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockQueryInterface)(nil).GetOrder), arg0, arg1, arg2)
+}
+
+// ListBalances mocks base method.
+func (m *MockQueryInterface) ListBalances(arg0 context.Context, arg1 int64) ([]*models.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBalances", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBalances indicates an expected call of ListBalances.
+func (mr *MockQueryInterfaceMockRecorder) ListBalances(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBalances", reflect.TypeOf((*MockQueryInterface)(nil).ListBalances), arg0, arg1)
+}
+
+// ListCandles mocks base method.
+func (m *MockQueryInterface) ListCandles(arg0 context.Context, arg1 *models.CandleQuery) ([]*models.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCandles", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCandles indicates an expected call of ListCandles.
+func (mr *MockQueryInterfaceMockRecorder) ListCandles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCandles", reflect.TypeOf((*MockQueryInterface)(nil).ListCandles), arg0, arg1)
+}
+
+// ListDeals mocks base method.
+func (m *MockQueryInterface) ListDeals(arg0 context.Context, arg1, arg2 int64) ([]*models.Deal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeals", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Deal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeals indicates an expected call of ListDeals.
+func (mr *MockQueryInterfaceMockRecorder) ListDeals(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeals", reflect.TypeOf((*MockQueryInterface)(nil).ListDeals), arg0, arg1, arg2)
+}
+
+// ListOrders mocks base method.
+func (m *MockQueryInterface) ListOrders(arg0 context.Context, arg1 *models.OrderQuery) ([]*models.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockQueryInterfaceMockRecorder) ListOrders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockQueryInterface)(nil).ListOrders), arg0, arg1)
+}
+
+// Ticker mocks base method.
+func (m *MockQueryInterface) Ticker(arg0 context.Context, arg1 string, arg2 time.Time) (*models.Ticker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ticker", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Ticker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ticker indicates an expected call of Ticker.
+func (mr *MockQueryInterfaceMockRecorder) Ticker(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ticker", reflect.TypeOf((*MockQueryInterface)(nil).Ticker), arg0, arg1, arg2)
+}
@@ -0,0 +1,62 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/relay.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOutboxRelayInterface is a mock of OutboxRelayInterface interface.
+type MockOutboxRelayInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRelayInterfaceMockRecorder
+}
+
+// MockOutboxRelayInterfaceMockRecorder is the mock recorder for MockOutboxRelayInterface.
+type MockOutboxRelayInterfaceMockRecorder struct {
+	mock *MockOutboxRelayInterface
+}
+
+// NewMockOutboxRelayInterface creates a new mock instance.
+func NewMockOutboxRelayInterface(ctrl *gomock.Controller) *MockOutboxRelayInterface {
+	mock := &MockOutboxRelayInterface{ctrl: ctrl}
+	mock.recorder = &MockOutboxRelayInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRelayInterface) EXPECT() *MockOutboxRelayInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Relay mocks base method.
+func (m *MockOutboxRelayInterface) Relay(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Relay", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Relay indicates an expected call of Relay.
+func (mr *MockOutboxRelayInterfaceMockRecorder) Relay(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Relay", reflect.TypeOf((*MockOutboxRelayInterface)(nil).Relay), arg0, arg1)
+}
+
+// Run mocks base method.
+func (m *MockOutboxRelayInterface) Run(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", arg0)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockOutboxRelayInterfaceMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockOutboxRelayInterface)(nil).Run), arg0)
+}
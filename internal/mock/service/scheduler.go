@@ -0,0 +1,62 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/scheduler.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPhaseSchedulerInterface is a mock of PhaseSchedulerInterface interface.
+type MockPhaseSchedulerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockPhaseSchedulerInterfaceMockRecorder
+}
+
+// MockPhaseSchedulerInterfaceMockRecorder is the mock recorder for MockPhaseSchedulerInterface.
+type MockPhaseSchedulerInterfaceMockRecorder struct {
+	mock *MockPhaseSchedulerInterface
+}
+
+// NewMockPhaseSchedulerInterface creates a new mock instance.
+func NewMockPhaseSchedulerInterface(ctrl *gomock.Controller) *MockPhaseSchedulerInterface {
+	mock := &MockPhaseSchedulerInterface{ctrl: ctrl}
+	mock.recorder = &MockPhaseSchedulerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPhaseSchedulerInterface) EXPECT() *MockPhaseSchedulerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockPhaseSchedulerInterface) Check(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockPhaseSchedulerInterfaceMockRecorder) Check(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockPhaseSchedulerInterface)(nil).Check), arg0, arg1)
+}
+
+// Run mocks base method.
+func (m *MockPhaseSchedulerInterface) Run(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", arg0)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockPhaseSchedulerInterfaceMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockPhaseSchedulerInterface)(nil).Run), arg0)
+}
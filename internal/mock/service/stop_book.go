@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/stop_book.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	models "dealer/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStopBookInterface is a mock of StopBookInterface interface.
+type MockStopBookInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockStopBookInterfaceMockRecorder
+}
+
+// MockStopBookInterfaceMockRecorder is the mock recorder for MockStopBookInterface.
+type MockStopBookInterfaceMockRecorder struct {
+	mock *MockStopBookInterface
+}
+
+// NewMockStopBookInterface creates a new mock instance.
+func NewMockStopBookInterface(ctrl *gomock.Controller) *MockStopBookInterface {
+	mock := &MockStopBookInterface{ctrl: ctrl}
+	mock.recorder = &MockStopBookInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStopBookInterface) EXPECT() *MockStopBookInterfaceMockRecorder {
+	return m.recorder
+}
+
+// AddOrder mocks base method.
+func (m *MockStopBookInterface) AddOrder(arg0 *models.Order) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddOrder", arg0)
+}
+
+// AddOrder indicates an expected call of AddOrder.
+func (mr *MockStopBookInterfaceMockRecorder) AddOrder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockStopBookInterface)(nil).AddOrder), arg0)
+}
+
+// Get mocks base method.
+func (m *MockStopBookInterface) Get(arg0 int64) *models.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*models.Order)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStopBookInterfaceMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStopBookInterface)(nil).Get), arg0)
+}
+
+// Range mocks base method.
+func (m *MockStopBookInterface) Range(arg0 func(*models.Order) bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Range", arg0)
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockStopBookInterfaceMockRecorder) Range(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockStopBookInterface)(nil).Range), arg0)
+}
+
+// RemoveOrder mocks base method.
+func (m *MockStopBookInterface) RemoveOrder(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveOrder", arg0)
+}
+
+// RemoveOrder indicates an expected call of RemoveOrder.
+func (mr *MockStopBookInterfaceMockRecorder) RemoveOrder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrder", reflect.TypeOf((*MockStopBookInterface)(nil).RemoveOrder), arg0)
+}
+
+// Trigger mocks base method.
+func (m *MockStopBookInterface) Trigger(arg0 models.Decimal) []*models.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trigger", arg0)
+	ret0, _ := ret[0].([]*models.Order)
+	return ret0
+}
+
+// Trigger indicates an expected call of Trigger.
+func (mr *MockStopBookInterfaceMockRecorder) Trigger(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trigger", reflect.TypeOf((*MockStopBookInterface)(nil).Trigger), arg0)
+}
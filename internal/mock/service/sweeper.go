@@ -0,0 +1,62 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/sweeper.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockExpirySweeperInterface is a mock of ExpirySweeperInterface interface.
+type MockExpirySweeperInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpirySweeperInterfaceMockRecorder
+}
+
+// MockExpirySweeperInterfaceMockRecorder is the mock recorder for MockExpirySweeperInterface.
+type MockExpirySweeperInterfaceMockRecorder struct {
+	mock *MockExpirySweeperInterface
+}
+
+// NewMockExpirySweeperInterface creates a new mock instance.
+func NewMockExpirySweeperInterface(ctrl *gomock.Controller) *MockExpirySweeperInterface {
+	mock := &MockExpirySweeperInterface{ctrl: ctrl}
+	mock.recorder = &MockExpirySweeperInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpirySweeperInterface) EXPECT() *MockExpirySweeperInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockExpirySweeperInterface) Run(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", arg0)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockExpirySweeperInterfaceMockRecorder) Run(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockExpirySweeperInterface)(nil).Run), arg0)
+}
+
+// Sweep mocks base method.
+func (m *MockExpirySweeperInterface) Sweep(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sweep", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sweep indicates an expected call of Sweep.
+func (mr *MockExpirySweeperInterfaceMockRecorder) Sweep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sweep", reflect.TypeOf((*MockExpirySweeperInterface)(nil).Sweep), arg0, arg1)
+}
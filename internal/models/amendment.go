@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Amendment is a change of the price or the quantity of an order. The dealer
+// records an amendment it can not apply with the reason in RejectReason.
+type Amendment struct {
+	ID           int64     `gorm:"primaryKey;column:id" json:"id"`
+	OrderID      int64     `gorm:"column:order_id" json:"order_id"`
+	Symbol       string    `gorm:"column:symbol" json:"symbol"`
+	OldPrice     Decimal   `gorm:"column:old_price" json:"old_price"`
+	Price        Decimal   `gorm:"column:price" json:"price"`
+	OldQuantity  uint      `gorm:"column:old_quantity" json:"old_quantity"`
+	Quantity     uint      `gorm:"column:quantity" json:"quantity"`
+	RejectReason string    `gorm:"column:reject_reason" json:"-"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	Sequence     int64     `gorm:"-" json:"-"`
+}
+
+var _ schema.Tabler = (*Amendment)(nil)
+
+func (Amendment) TableName() string {
+	return "order_amendment"
+}
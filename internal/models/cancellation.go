@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+type CancelReason string
+
+const (
+	CancelReasonUser              CancelReason = "user"
+	CancelReasonExpired           CancelReason = "expired"
+	CancelReasonIOC               CancelReason = "ioc"
+	CancelReasonFOK               CancelReason = "fok"
+	CancelReasonInsufficientFunds CancelReason = "insufficient_funds"
+	CancelReasonSelfTrade         CancelReason = "self_trade"
+	CancelReasonPostOnly          CancelReason = "post_only"
+	CancelReasonMinQuantity       CancelReason = "min_quantity"
+	CancelReasonUnfilled          CancelReason = "unfilled"
+	CancelReasonSlippage          CancelReason = "slippage"
+	CancelReasonAdmin             CancelReason = "admin"
+)
+
+// Cancellation is a quantity of an order cancelled by the dealer. A
+// decrement by self-trade prevention cancels part of an order that stays open.
+type Cancellation struct {
+	ID        int64        `gorm:"primaryKey;column:id" json:"id"`
+	OrderID   int64        `gorm:"column:order_id" json:"order_id"`
+	Symbol    string       `gorm:"column:symbol" json:"symbol"`
+	UserID    int64        `gorm:"column:user_id" json:"user_id"`
+	Quantity  uint         `gorm:"column:quantity" json:"quantity"`
+	Reason    CancelReason `gorm:"column:reason" json:"reason"`
+	CreatedAt time.Time    `gorm:"column:created_at" json:"created_at"`
+}
+
+var _ schema.Tabler = (*Cancellation)(nil)
+
+func (Cancellation) TableName() string {
+	return "order_cancellation"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+type CandleInterval string
+
+const (
+	CandleInterval1m CandleInterval = "1m"
+	CandleInterval5m CandleInterval = "5m"
+	CandleInterval1h CandleInterval = "1h"
+	CandleInterval1d CandleInterval = "1d"
+)
+
+var CandleIntervals = []CandleInterval{CandleInterval1m, CandleInterval5m, CandleInterval1h, CandleInterval1d}
+
+var candleDurations = map[CandleInterval]time.Duration{
+	CandleInterval1m: time.Minute,
+	CandleInterval5m: 5 * time.Minute,
+	CandleInterval1h: time.Hour,
+	CandleInterval1d: 24 * time.Hour,
+}
+
+func (i CandleInterval) IsValid() bool {
+	_, ok := candleDurations[i]
+	return ok
+}
+
+// OpenTime returns the open time of the candle of the interval containing t.
+// Candles are aligned to UTC.
+func (i CandleInterval) OpenTime(t time.Time) time.Time {
+	return t.UTC().Truncate(candleDurations[i])
+}
+
+// Candle is the OHLCV bar of the deals of a symbol executed in
+// [OpenTime, OpenTime + Interval).
+type Candle struct {
+	Symbol   string         `gorm:"primaryKey;column:symbol" json:"symbol"`
+	Interval CandleInterval `gorm:"primaryKey;column:interval" json:"interval"`
+	OpenTime time.Time      `gorm:"primaryKey;column:open_time" json:"open_time"`
+	Open     Decimal        `gorm:"column:open" json:"open"`
+	High     Decimal        `gorm:"column:high" json:"high"`
+	Low      Decimal        `gorm:"column:low" json:"low"`
+	Close    Decimal        `gorm:"column:close" json:"close"`
+	Volume   uint64         `gorm:"column:volume" json:"volume"`
+}
+
+var _ schema.Tabler = (*Candle)(nil)
+
+func (Candle) TableName() string {
+	return "candle"
+}
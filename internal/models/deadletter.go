@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DeadLetter is a message of the order queue that the dealer gave up on.
+type DeadLetter struct {
+	Reason  string     `json:"reason"`
+	Count   int64      `json:"count"`
+	DiedAt  *time.Time `json:"died_at,omitempty"`
+	Body    string     `json:"body"`
+	Message *Message   `json:"message,omitempty"`
+}
@@ -1,13 +1,44 @@
 package models
 
-import "gorm.io/gorm/schema"
+import (
+	"time"
 
+	"gorm.io/gorm/schema"
+)
+
+// Deal is a match between a taker and a maker order. CreatedAt is the
+// execution time of the match. The fee of each side is charged in the asset
+// the side receives, the base asset for the buyer and the quote asset for the
+// seller.
 type Deal struct {
-	ID           int64   `gorm:"primaryKey;column:id" json:"id"`
-	TakerOrderID int64   `gorm:"column:taker_order_id"`
-	MakerOrderID int64   `gorm:"column:maker_order_id"`
-	Quantity     uint    `gorm:"column:quantity"`
-	Price        float64 `gorm:"column:price"`
+	ID            int64     `gorm:"primaryKey;column:id" json:"id"`
+	Symbol        string    `gorm:"column:symbol" json:"symbol"`
+	TakerOrderID  int64     `gorm:"column:taker_order_id" json:"taker_order_id"`
+	MakerOrderID  int64     `gorm:"column:maker_order_id" json:"maker_order_id"`
+	TakerUserID   int64     `gorm:"column:taker_user_id" json:"taker_user_id"`
+	MakerUserID   int64     `gorm:"column:maker_user_id" json:"maker_user_id"`
+	Quantity      uint      `gorm:"column:quantity" json:"quantity"`
+	Price         Decimal   `gorm:"column:price" json:"price"`
+	TakerFee      Decimal   `gorm:"column:taker_fee" json:"taker_fee"`
+	TakerFeeAsset string    `gorm:"column:taker_fee_asset" json:"taker_fee_asset"`
+	MakerFee      Decimal   `gorm:"column:maker_fee" json:"maker_fee"`
+	MakerFeeAsset string    `gorm:"column:maker_fee_asset" json:"maker_fee_asset"`
+	CreatedAt     time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// DealStats aggregates the deals of a symbol over a period.
+type DealStats struct {
+	Volume uint64  `gorm:"column:volume"`
+	High   Decimal `gorm:"column:high"`
+	Low    Decimal `gorm:"column:low"`
+}
+
+// AccountVolume is the quote asset traded by an account in a symbol over a
+// period.
+type AccountVolume struct {
+	UserID int64   `gorm:"column:user_id"`
+	Symbol string  `gorm:"column:symbol"`
+	Volume Decimal `gorm:"column:volume"`
 }
 
 var _ schema.Tabler = (*Deal)(nil)
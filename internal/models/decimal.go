@@ -0,0 +1,128 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const (
+	DecimalPlaces = 8
+	DecimalScale  = 100000000
+)
+
+var (
+	ErrInvalidDecimal  = errors.New("invalid decimal")
+	ErrDecimalOverflow = errors.New("decimal overflow")
+)
+
+// Decimal is a fixed-point number scaled by DecimalScale, so 1.5 is stored as
+// 150000000. It is stored in BIGINT columns and encoded as a JSON number.
+type Decimal int64
+
+func NewDecimalFromInt(value int64) Decimal {
+	return Decimal(value * DecimalScale)
+}
+
+func ParseDecimal(s string) (Decimal, error) {
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	integer, fraction, _ := strings.Cut(s, ".")
+	if integer == "" && fraction == "" || len(fraction) > DecimalPlaces {
+		return 0, ErrInvalidDecimal
+	}
+
+	fraction += strings.Repeat("0", DecimalPlaces-len(fraction))
+	var value uint64
+	for _, c := range integer + fraction {
+		if c < '0' || c > '9' {
+			return 0, ErrInvalidDecimal
+		}
+
+		if value > (math.MaxInt64-uint64(c-'0'))/10 {
+			return 0, ErrInvalidDecimal
+		}
+		value = value*10 + uint64(c-'0')
+	}
+
+	if negative {
+		return -Decimal(value), nil
+	}
+
+	return Decimal(value), nil
+}
+
+func (d Decimal) String() string {
+	value := int64(d)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	integer := strconv.FormatInt(value/DecimalScale, 10)
+	fraction := strings.TrimRight(strconv.FormatInt(value%DecimalScale+DecimalScale, 10)[1:], "0")
+	if fraction == "" {
+		return sign + integer
+	}
+
+	return sign + integer + "." + fraction
+}
+
+// Mul returns the value of quantity units priced at d, or ErrDecimalOverflow
+// when it does not fit in a Decimal.
+func (d Decimal) Mul(quantity uint) (Decimal, error) {
+	value := uint64(d)
+	if d < 0 {
+		value = uint64(-(d + 1)) + 1
+	}
+
+	hi, lo := bits.Mul64(value, uint64(quantity))
+	if hi != 0 || lo > math.MaxInt64 {
+		return 0, ErrDecimalOverflow
+	}
+
+	if d < 0 {
+		return -Decimal(lo), nil
+	}
+
+	return Decimal(lo), nil
+}
+
+// MulRate returns d times rate truncated to DecimalPlaces, saturated at the
+// largest Decimal. Both must not be negative.
+func (d Decimal) MulRate(rate Decimal) Decimal {
+	hi, lo := bits.Mul64(uint64(d), uint64(rate))
+	if hi >= DecimalScale {
+		return math.MaxInt64
+	}
+
+	quotient, _ := bits.Div64(hi, lo, DecimalScale)
+	if quotient > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return Decimal(quotient)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	value, err := ParseDecimal(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+
+	*d = value
+	return nil
+}
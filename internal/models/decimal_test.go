@@ -0,0 +1,171 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Decimal
+		hasError bool
+	}{
+		{
+			name:     "Parse integer",
+			input:    "12",
+			expected: 1200000000,
+		},
+		{
+			name:     "Parse fraction",
+			input:    "0.01",
+			expected: 1000000,
+		},
+		{
+			name:     "Parse smallest unit",
+			input:    "0.00000001",
+			expected: 1,
+		},
+		{
+			name:     "Parse negative",
+			input:    "-1.5",
+			expected: -150000000,
+		},
+		{
+			name:     "Parse without integer part",
+			input:    ".5",
+			expected: 50000000,
+		},
+		{
+			name:     "Parse too many decimal places",
+			input:    "0.000000001",
+			hasError: true,
+		},
+		{
+			name:     "Parse exponent",
+			input:    "1e5",
+			hasError: true,
+		},
+		{
+			name:     "Parse empty",
+			input:    "",
+			hasError: true,
+		},
+		{
+			name:     "Parse overflow",
+			input:    "100000000000",
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ParseDecimal(test.input)
+			assert.Equal(t, test.hasError, err != nil)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Decimal
+		expected string
+	}{
+		{
+			name:     "Integer",
+			input:    NewDecimalFromInt(12),
+			expected: "12",
+		},
+		{
+			name:     "Fraction",
+			input:    1000000,
+			expected: "0.01",
+		},
+		{
+			name:     "Negative",
+			input:    -150000000,
+			expected: "-1.5",
+		},
+		{
+			name:     "Zero",
+			input:    0,
+			expected: "0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.input.String())
+		})
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var actual struct {
+		Number Decimal `json:"number"`
+		String Decimal `json:"string"`
+	}
+	err := json.Unmarshal([]byte(`{"number": 0.1, "string": "0.2"}`), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, Decimal(10000000), actual.Number)
+	assert.Equal(t, Decimal(20000000), actual.String)
+
+	data, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"number":0.1,"string":0.2}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"number": 1e-9}`), &actual)
+	assert.Error(t, err)
+}
+
+func TestDecimalMulRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Decimal
+		rate     Decimal
+		expected Decimal
+	}{
+		{name: "Multiply by rate", value: NewDecimalFromInt(200), rate: 200000, expected: 40000000},
+		{name: "Truncate below smallest unit", value: 10, rate: 200000, expected: 0},
+		{name: "Multiply large value", value: NewDecimalFromInt(50000000000), rate: 100000, expected: NewDecimalFromInt(50000000)},
+		{name: "Multiply by zero rate", value: NewDecimalFromInt(1), rate: 0, expected: 0},
+		{name: "Saturate overflowing product", value: math.MaxInt64, rate: NewDecimalFromInt(3), expected: math.MaxInt64},
+		{name: "Saturate overflowing quotient", value: math.MaxInt64, rate: NewDecimalFromInt(1) + 1, expected: math.MaxInt64},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.value.MulRate(test.rate))
+		})
+	}
+}
+
+func TestDecimalMul(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    Decimal
+		quantity uint
+		expected Decimal
+		err      error
+	}{
+		{name: "Multiply by quantity", value: NewDecimalFromInt(10), quantity: 3, expected: NewDecimalFromInt(30)},
+		{name: "Multiply negative value", value: NewDecimalFromInt(-10), quantity: 3, expected: NewDecimalFromInt(-30)},
+		{name: "Multiply by zero", value: NewDecimalFromInt(10), quantity: 0, expected: 0},
+		{name: "Multiply overflow", value: NewDecimalFromInt(10000), quantity: 10000000, err: ErrDecimalOverflow},
+		{name: "Multiply negative overflow", value: NewDecimalFromInt(-10000), quantity: 10000000, err: ErrDecimalOverflow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.value.Mul(test.quantity)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+// FeeTier applies its rates to the accounts that have traded at least Volume
+// of the quote asset in the volume period.
+type FeeTier struct {
+	Volume    Decimal
+	MakerRate Decimal
+	TakerRate Decimal
+}
+
+// FeeSchedule is the fee rates of the deals. A rate of 0.001 charges 0.1% of
+// what a side receives. Tiers are sorted by volume.
+type FeeSchedule struct {
+	MakerRate Decimal
+	TakerRate Decimal
+	Tiers     []FeeTier
+}
+
+// Rates returns the rates of the highest tier reached by volume.
+func (s *FeeSchedule) Rates(volume Decimal) (maker, taker Decimal) {
+	maker, taker = s.MakerRate, s.TakerRate
+	for _, tier := range s.Tiers {
+		if volume < tier.Volume {
+			break
+		}
+		maker, taker = tier.MakerRate, tier.TakerRate
+	}
+
+	return maker, taker
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeScheduleRates(t *testing.T) {
+	schedule := &FeeSchedule{
+		MakerRate: 100000,
+		TakerRate: 200000,
+		Tiers: []FeeTier{
+			{Volume: NewDecimalFromInt(1000), MakerRate: 80000, TakerRate: 150000},
+			{Volume: NewDecimalFromInt(10000), MakerRate: 0, TakerRate: 100000},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		volume Decimal
+		maker  Decimal
+		taker  Decimal
+	}{
+		{name: "Below the first tier", volume: NewDecimalFromInt(999), maker: 100000, taker: 200000},
+		{name: "At the first tier", volume: NewDecimalFromInt(1000), maker: 80000, taker: 150000},
+		{name: "Above the last tier", volume: NewDecimalFromInt(20000), maker: 0, taker: 100000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			maker, taker := schedule.Rates(test.volume)
+			assert.Equal(t, test.maker, maker)
+			assert.Equal(t, test.taker, taker)
+		})
+	}
+}
@@ -1,12 +1,83 @@
 package models
 
+import "time"
+
 type OrderRequest struct {
-	OrderType OrderType `json:"order_type"`
-	Quantity  uint      `son:"quantity"`
-	PriceType PriceType `json:"price_type"`
-	Price     float64   `json:"price"`
+	Symbol              string              `json:"symbol"`
+	OrderType           OrderType           `json:"order_type"`
+	Quantity            uint                `son:"quantity"`
+	PriceType           PriceType           `json:"price_type"`
+	Price               Decimal             `json:"price"`
+	TimeInForce         TimeInForce         `json:"time_in_force"`
+	ExpireAt            *time.Time          `json:"expire_at"`
+	StopPrice           Decimal             `json:"stop_price"`
+	SelfTradePrevention SelfTradePrevention `json:"self_trade_prevention"`
+	DisplayQuantity     uint                `json:"display_quantity"`
+	PostOnly            PostOnly            `json:"post_only"`
+	MinQuantity         uint                `json:"min_quantity"`
+	Budget              Decimal             `json:"budget"`
 }
 
 type CancelOrderRequest struct {
-	ID int64 `uri:"id"`
+	ID     int64  `uri:"id"`
+	Symbol string `form:"symbol"`
+}
+
+type AmendOrderRequest struct {
+	ID       int64   `uri:"id" json:"-"`
+	Symbol   string  `json:"symbol"`
+	Price    Decimal `json:"price"`
+	Quantity uint    `json:"quantity"`
+}
+
+type ListOrdersRequest struct {
+	Symbol      string      `form:"symbol"`
+	OrderType   OrderType   `form:"order_type"`
+	Status      OrderStatus `form:"status"`
+	PriceType   PriceType   `form:"price_type"`
+	CreatedFrom *time.Time  `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time  `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor      int64       `form:"cursor"`
+	Limit       int         `form:"limit"`
+}
+
+type ListOrdersResponse struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor int64    `json:"next_cursor,omitempty"`
+}
+
+type DepthRequest struct {
+	Symbol string `form:"symbol"`
+	Levels int    `form:"levels"`
+}
+
+type TickerRequest struct {
+	Symbol string `form:"symbol"`
+}
+
+type CandlesRequest struct {
+	Symbol   string         `form:"symbol"`
+	Interval CandleInterval `form:"interval"`
+	From     *time.Time     `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time     `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit    int            `form:"limit"`
+}
+
+// StreamRequest is sent by a WebSocket client to subscribe or unsubscribe a
+// market data channel.
+type StreamRequest struct {
+	Op      string `json:"op"`
+	Channel string `json:"channel"`
+}
+
+type MarketRequest struct {
+	Symbol string `uri:"symbol"`
+}
+
+// CancelAllRequest cancels the resting orders of the symbol, or of all
+// symbols when it is empty, of one side and of one user when they are set.
+type CancelAllRequest struct {
+	Symbol    string    `json:"symbol"`
+	OrderType OrderType `json:"order_type"`
+	UserID    int64     `json:"user_id"`
 }
@@ -0,0 +1,20 @@
+package models
+
+type Instrument struct {
+	Symbol     string    `json:"symbol"`
+	BaseAsset  string    `json:"base_asset"`
+	QuoteAsset string    `json:"quote_asset"`
+	TickSize   Decimal   `json:"tick_size"`
+	LotSize    uint      `json:"lot_size"`
+	PriceBand  PriceBand `json:"price_band"`
+	// ReferencePrice prices the market orders before the first deal and
+	// centres the static price band before the first auction, and
+	// MaxSlippage is the fraction a market order may trade away from the
+	// best price when it arrives, zero for no limit.
+	ReferencePrice Decimal `json:"reference_price"`
+	MaxSlippage    Decimal `json:"max_slippage"`
+	// MaxPrice and MaxQuantity bound the prices and the quantity of an order,
+	// so the value of any order fits in a Decimal. Zero leaves it unbounded.
+	MaxPrice    Decimal `json:"max_price"`
+	MaxQuantity uint    `json:"max_quantity"`
+}
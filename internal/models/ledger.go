@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// SystemUserID is the account on the other side of the deposits and the
+// collector of the fees, so its balances are the fees collected less the
+// totals deposited.
+const SystemUserID = 0
+
+type BalanceBucket string
+
+const (
+	BalanceAvailable BalanceBucket = "available"
+	BalanceLocked    BalanceBucket = "locked"
+)
+
+type LedgerReason string
+
+const (
+	LedgerReasonDeposit LedgerReason = "deposit"
+	LedgerReasonLock    LedgerReason = "lock"
+	LedgerReasonRelease LedgerReason = "release"
+	LedgerReasonTrade   LedgerReason = "trade"
+	LedgerReasonFee     LedgerReason = "fee"
+)
+
+// Balance is the amount of an asset held by a user. Locked funds are
+// reserved for open orders.
+type Balance struct {
+	UserID    int64   `gorm:"primaryKey;column:user_id" json:"-"`
+	Asset     string  `gorm:"primaryKey;column:asset" json:"asset"`
+	Available Decimal `gorm:"column:available" json:"available"`
+	Locked    Decimal `gorm:"column:locked" json:"locked"`
+}
+
+var _ schema.Tabler = (*Balance)(nil)
+
+func (Balance) TableName() string {
+	return "balance"
+}
+
+// LedgerEntry moves Amount into a bucket of a balance, out of it when
+// negative. The entries of a posting sum to zero for every asset.
+type LedgerEntry struct {
+	ID        int64         `gorm:"primaryKey;column:id" json:"id"`
+	UserID    int64         `gorm:"column:user_id" json:"user_id"`
+	Asset     string        `gorm:"column:asset" json:"asset"`
+	Bucket    BalanceBucket `gorm:"column:bucket" json:"bucket"`
+	Amount    Decimal       `gorm:"column:amount" json:"amount"`
+	Reason    LedgerReason  `gorm:"column:reason" json:"reason"`
+	OrderID   int64         `gorm:"column:order_id" json:"order_id,omitempty"`
+	DealID    int64         `gorm:"column:deal_id" json:"deal_id,omitempty"`
+	CreatedAt time.Time     `gorm:"column:created_at" json:"created_at"`
+}
+
+var _ schema.Tabler = (*LedgerEntry)(nil)
+
+func (LedgerEntry) TableName() string {
+	return "ledger"
+}
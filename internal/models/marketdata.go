@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+const (
+	MarketEventSnapshot = "snapshot"
+	MarketEventUpdate   = "update"
+	MarketEventError    = "error"
+)
+
+// PriceLevel is the total remaining quantity and the number of the limit
+// orders at a price. A level of zero quantity in a depth update means the
+// level was removed.
+type PriceLevel struct {
+	Price    Decimal `json:"price"`
+	Quantity uint    `json:"quantity"`
+	Count    uint    `json:"count"`
+}
+
+// Depth is the level-2 order book of a symbol, with the best prices first.
+type Depth struct {
+	Bids []*PriceLevel `json:"bids"`
+	Asks []*PriceLevel `json:"asks"`
+}
+
+// Book is every resting order of a symbol, the orders of each side in the
+// order they match and the stop orders waiting for their trigger.
+type Book struct {
+	Symbol     string       `json:"symbol"`
+	Phase      TradingPhase `json:"phase"`
+	Bids       []*Order     `json:"bids"`
+	Asks       []*Order     `json:"asks"`
+	StopOrders []*Order     `json:"stop_orders"`
+}
+
+// MarketUpdate is what a processed message changed in a market.
+type MarketUpdate struct {
+	Deals     []*Deal
+	Orders    []*Order
+	Depth     *Depth
+	LastPrice Decimal
+	Status    *MarketStatus
+}
+
+// Ticker summarizes a market. Prices of zero mean there is no such price yet.
+type Ticker struct {
+	Symbol           string       `json:"symbol"`
+	BestBid          Decimal      `json:"best_bid"`
+	BestAsk          Decimal      `json:"best_ask"`
+	LastPrice        Decimal      `json:"last_price"`
+	Volume           uint64       `json:"volume"`
+	High             Decimal      `json:"high"`
+	Low              Decimal      `json:"low"`
+	Phase            TradingPhase `json:"phase,omitempty"`
+	IndicativePrice  Decimal      `json:"indicative_price,omitempty"`
+	IndicativeVolume uint         `json:"indicative_volume,omitempty"`
+	ResumeAt         *time.Time   `json:"resume_at,omitempty"`
+}
+
+// MarketEvent is sent to the WebSocket subscribers of a channel. Sequence
+// increases by one for every update of the channel, and a snapshot carries the
+// sequence of the last update it includes.
+type MarketEvent struct {
+	Channel  string      `json:"channel"`
+	Type     string      `json:"type"`
+	Sequence int64       `json:"sequence"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+type MessageType int
+
+const (
+	MessageTypeNewOrder MessageType = iota + 1
+	MessageTypeCancelOrder
+	MessageTypeAmendOrder
+	MessageTypeChangePhase
+	MessageTypeResumeMarket
+	MessageTypeHaltMarket
+	MessageTypeCancelAll
+)
+
+// Message is the envelope published to the order queue and consumed by the
+// dealer. Sequence is the sequence the relay assigned to the outbox row the
+// message was published from, carried in the sequence header of the AMQP
+// message.
+type Message struct {
+	Sequence   int64        `json:"sequence,omitempty"`
+	Type       MessageType  `json:"type"`
+	Order      *Order       `json:"order,omitempty"`
+	Amendment  *Amendment   `json:"amendment,omitempty"`
+	Market     *MarketState `json:"market,omitempty"`
+	MassCancel *MassCancel  `json:"mass_cancel,omitempty"`
+}
+
+// MassCancel selects the resting orders of a market an operator cancels. A
+// zero OrderType or UserID matches the orders of both sides or of all users.
+type MassCancel struct {
+	Symbol    string    `json:"symbol"`
+	OrderType OrderType `json:"order_type,omitempty"`
+	UserID    int64     `json:"user_id,omitempty"`
+	Sequence  int64     `json:"-"`
+}
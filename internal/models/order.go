@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"time"
+
 	"gorm.io/gorm/schema"
 )
 
@@ -16,20 +19,193 @@ type PriceType int
 const (
 	PriceTypeLimit PriceType = iota + 1
 	PriceTypeMarket
+	PriceTypeStopLimit
+	PriceTypeStopMarket
+)
+
+type TimeInForce int
+
+const (
+	TimeInForceGTC TimeInForce = iota + 1
+	TimeInForceIOC
+	TimeInForceFOK
+	TimeInForceGTD
+)
+
+// SelfTradePrevention is what happens when an order would match an order of
+// the same user. The mode of the taker applies.
+type SelfTradePrevention int
+
+const (
+	SelfTradePreventionCancelNewest SelfTradePrevention = iota + 1
+	SelfTradePreventionCancelOldest
+	SelfTradePreventionCancelBoth
+	SelfTradePreventionDecrementAndCancel
+)
+
+// PostOnly is what happens to a post-only order that would take liquidity
+// when it enters the book.
+type PostOnly int
+
+const (
+	PostOnlyReject PostOnly = iota + 1
+	PostOnlyReprice
+)
+
+type OrderStatus int
+
+const (
+	OrderStatusNew OrderStatus = iota + 1
+	OrderStatusPartiallyFilled
+	OrderStatusFilled
+	OrderStatusCancelled
+	OrderStatusRejected
 )
 
+var ErrInvalidStatusTransition = errors.New("invalid order status transition")
+
+// IsFinal reports whether no transition leaves the status.
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusFilled || s == OrderStatusCancelled || s == OrderStatusRejected
+}
+
+// CanTransitionTo reports whether an order can move from s to next. A zero
+// status is an order from before the status column and is treated as new.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	switch s {
+	case 0, OrderStatusNew:
+		return next != OrderStatusNew
+	case OrderStatusPartiallyFilled:
+		return next == OrderStatusPartiallyFilled || next == OrderStatusFilled || next == OrderStatusCancelled
+	default:
+		return false
+	}
+}
+
 type Order struct {
-	ID             int64     `gorm:"primaryKey;column:id" json:"id"`
-	OrderType      OrderType `gorm:"column:order_type" json:"order_type"`
-	Quantity       uint      `gorm:"column:quantity" json:"quantity"`
-	RemainQuantity uint      `gorm:"column:remain_quantity" json:"remain_quantity"`
-	PriceType      PriceType `gorm:"column:price_type" json:"price_type"`
-	Price          float64   `gorm:"column:price" json:"price"`
-	IsCancel       bool      `gorm:"column:is_cancel" json:"is_cancel"`
+	ID                  int64               `gorm:"primaryKey;column:id" json:"id"`
+	UserID              int64               `gorm:"column:user_id" json:"user_id"`
+	Symbol              string              `gorm:"column:symbol" json:"symbol"`
+	OrderType           OrderType           `gorm:"column:order_type" json:"order_type"`
+	Quantity            uint                `gorm:"column:quantity" json:"quantity"`
+	RemainQuantity      uint                `gorm:"column:remain_quantity" json:"remain_quantity"`
+	PriceType           PriceType           `gorm:"column:price_type" json:"price_type"`
+	Price               Decimal             `gorm:"column:price" json:"price"`
+	IsCancel            bool                `gorm:"column:is_cancel" json:"is_cancel"`
+	Status              OrderStatus         `gorm:"column:status" json:"status"`
+	TimeInForce         TimeInForce         `gorm:"column:time_in_force" json:"time_in_force"`
+	ExpireAt            *time.Time          `gorm:"column:expire_at" json:"expire_at,omitempty"`
+	StopPrice           Decimal             `gorm:"column:stop_price" json:"stop_price"`
+	TriggeredAt         *time.Time          `gorm:"column:triggered_at" json:"triggered_at,omitempty"`
+	Budget              Decimal             `gorm:"column:budget" json:"budget,omitempty"`
+	SelfTradePrevention SelfTradePrevention `gorm:"column:self_trade_prevention" json:"self_trade_prevention"`
+	DisplayQuantity     uint                `gorm:"column:display_quantity" json:"display_quantity,omitempty"`
+	PostOnly            PostOnly            `gorm:"column:post_only" json:"post_only,omitempty"`
+	MinQuantity         uint                `gorm:"column:min_quantity" json:"min_quantity,omitempty"`
+	Priority            int64               `gorm:"column:priority" json:"-"`
+	Sequence            int64               `gorm:"column:sequence" json:"-"`
+	CreatedAt           time.Time           `gorm:"column:created_at" json:"created_at"`
 }
 
 var _ schema.Tabler = (*Order)(nil)
 
+// Applied reports whether a message with the sequence has already been
+// applied to the order. A message without a sequence is never applied.
+func (o *Order) Applied(sequence int64) bool {
+	return sequence != 0 && sequence <= o.Sequence
+}
+
+// Apply records the sequence of a message applied to the order. It reports
+// false when the message has already been applied, while a message without a
+// sequence is always applied.
+func (o *Order) Apply(sequence int64) bool {
+	if sequence == 0 {
+		return true
+	}
+	if o.Applied(sequence) {
+		return false
+	}
+
+	o.Sequence = sequence
+	return true
+}
+
+// Before reports whether o is ahead of other in the queue of a price level.
+func (o *Order) Before(other *Order) bool {
+	if o.Priority != other.Priority {
+		return o.Priority < other.Priority
+	}
+
+	return o.ID < other.ID
+}
+
+func (o *Order) SetStatus(status OrderStatus) error {
+	if !o.Status.CanTransitionTo(status) {
+		return ErrInvalidStatusTransition
+	}
+
+	o.Status = status
+	return nil
+}
+
+// Fill takes quantity off the remain quantity of an open order and moves it
+// to partially filled or filled.
+func (o *Order) Fill(quantity uint) {
+	o.RemainQuantity -= quantity
+	if o.RemainQuantity == 0 {
+		o.Status = OrderStatusFilled
+	} else {
+		o.Status = OrderStatusPartiallyFilled
+	}
+}
+
+// Cancel cancels an open order, the remain quantity is kept for reference.
+func (o *Order) Cancel() error {
+	if err := o.SetStatus(OrderStatusCancelled); err != nil {
+		return err
+	}
+
+	o.IsCancel = true
+	return nil
+}
+
+func (o *Order) IsIceberg() bool {
+	return o.DisplayQuantity != 0
+}
+
+// VisibleQuantity is the quantity shown in the order book. An iceberg order
+// shows what is left of its current slice of DisplayQuantity, the slices are
+// counted from the filled quantity so they survive a restart.
+func (o *Order) VisibleQuantity() uint {
+	if !o.IsIceberg() {
+		return o.RemainQuantity
+	}
+
+	visible := o.DisplayQuantity - (o.Quantity-o.RemainQuantity)%o.DisplayQuantity
+	if visible > o.RemainQuantity {
+		return o.RemainQuantity
+	}
+
+	return visible
+}
+
+func (o *Order) IsStop() bool {
+	return o.PriceType == PriceTypeStopLimit || o.PriceType == PriceTypeStopMarket
+}
+
+// MatchPriceType is the price type the order is matched with, a triggered
+// stop order is matched as a limit or market order.
+func (o *Order) MatchPriceType() PriceType {
+	switch o.PriceType {
+	case PriceTypeStopLimit:
+		return PriceTypeLimit
+	case PriceTypeStopMarket:
+		return PriceTypeMarket
+	default:
+		return o.PriceType
+	}
+}
+
 func (Order) TableName() string {
 	return "order"
 }
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     OrderStatus
+		to       OrderStatus
+		expected bool
+	}{
+		{name: "New to partially filled", from: OrderStatusNew, to: OrderStatusPartiallyFilled, expected: true},
+		{name: "New to rejected", from: OrderStatusNew, to: OrderStatusRejected, expected: true},
+		{name: "New to new", from: OrderStatusNew, to: OrderStatusNew, expected: false},
+		{name: "Unset to cancelled", from: 0, to: OrderStatusCancelled, expected: true},
+		{name: "Partially filled to filled", from: OrderStatusPartiallyFilled, to: OrderStatusFilled, expected: true},
+		{name: "Partially filled to cancelled", from: OrderStatusPartiallyFilled, to: OrderStatusCancelled, expected: true},
+		{name: "Partially filled to rejected", from: OrderStatusPartiallyFilled, to: OrderStatusRejected, expected: false},
+		{name: "Filled to cancelled", from: OrderStatusFilled, to: OrderStatusCancelled, expected: false},
+		{name: "Cancelled to filled", from: OrderStatusCancelled, to: OrderStatusFilled, expected: false},
+		{name: "Rejected to new", from: OrderStatusRejected, to: OrderStatusNew, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.from.CanTransitionTo(test.to))
+		})
+	}
+}
+
+func TestOrderFill(t *testing.T) {
+	order := &Order{Quantity: 3, RemainQuantity: 3, Status: OrderStatusNew}
+	order.Fill(1)
+	assert.Equal(t, uint(2), order.RemainQuantity)
+	assert.Equal(t, OrderStatusPartiallyFilled, order.Status)
+
+	order.Fill(2)
+	assert.Equal(t, uint(0), order.RemainQuantity)
+	assert.Equal(t, OrderStatusFilled, order.Status)
+}
+
+func TestOrderVisibleQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    *Order
+		expected uint
+	}{
+		{name: "Not iceberg", order: &Order{Quantity: 10, RemainQuantity: 7}, expected: 7},
+		{name: "First slice", order: &Order{Quantity: 10, RemainQuantity: 10, DisplayQuantity: 4}, expected: 4},
+		{name: "Partially filled slice", order: &Order{Quantity: 10, RemainQuantity: 7, DisplayQuantity: 4}, expected: 1},
+		{name: "Replenished slice", order: &Order{Quantity: 10, RemainQuantity: 6, DisplayQuantity: 4}, expected: 4},
+		{name: "Last slice", order: &Order{Quantity: 10, RemainQuantity: 2, DisplayQuantity: 4}, expected: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.order.VisibleQuantity())
+		})
+	}
+}
+
+func TestOrderCancel(t *testing.T) {
+	order := &Order{Quantity: 3, RemainQuantity: 2, Status: OrderStatusPartiallyFilled}
+	assert.NoError(t, order.Cancel())
+	assert.True(t, order.IsCancel)
+	assert.Equal(t, OrderStatusCancelled, order.Status)
+
+	order = &Order{Quantity: 3, Status: OrderStatusFilled}
+	assert.ErrorIs(t, order.Cancel(), ErrInvalidStatusTransition)
+	assert.False(t, order.IsCancel)
+}
+
+func TestOrderApply(t *testing.T) {
+	order := &Order{Sequence: 5}
+	assert.False(t, order.Apply(5))
+	assert.False(t, order.Apply(4))
+	assert.True(t, order.Apply(0))
+	assert.Equal(t, int64(5), order.Sequence)
+	assert.True(t, order.Apply(6))
+	assert.Equal(t, int64(6), order.Sequence)
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Outbox is a message waiting to be published to the order queue. It is
+// written in the same transaction as the change it describes. The sequence is
+// assigned by the relay once the row is committed, zero until then.
+type Outbox struct {
+	ID        int64      `gorm:"primaryKey;column:id"`
+	Sequence  int64      `gorm:"column:sequence"`
+	Payload   []byte     `gorm:"column:payload"`
+	Attempts  int        `gorm:"column:attempts"`
+	SentAt    *time.Time `gorm:"column:sent_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
+
+var _ schema.Tabler = (*Outbox)(nil)
+
+func (Outbox) TableName() string {
+	return "outbox"
+}
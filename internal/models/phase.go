@@ -0,0 +1,151 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+type TradingPhase string
+
+const (
+	TradingPhasePreOpen        TradingPhase = "pre_open"
+	TradingPhaseAuction        TradingPhase = "auction"
+	TradingPhaseContinuous     TradingPhase = "continuous"
+	TradingPhaseClosingAuction TradingPhase = "closing_auction"
+	TradingPhaseClosed         TradingPhase = "closed"
+
+	// The circuit breaker phases interrupt continuous trading until the
+	// market resumes.
+	TradingPhaseHalted            TradingPhase = "halted"
+	TradingPhaseVolatilityAuction TradingPhase = "volatility_auction"
+)
+
+// tradingDay is the order a market goes through the phases, after closed it
+// starts over from pre-open.
+var tradingDay = []TradingPhase{
+	TradingPhasePreOpen,
+	TradingPhaseAuction,
+	TradingPhaseContinuous,
+	TradingPhaseClosingAuction,
+	TradingPhaseClosed,
+}
+
+func (p TradingPhase) IsValid() bool {
+	for _, phase := range tradingDay {
+		if p == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Next is the phase after p in a trading day. A halted market goes back to
+// continuous trading.
+func (p TradingPhase) Next() TradingPhase {
+	for i, phase := range tradingDay {
+		if p == phase {
+			return tradingDay[(i+1)%len(tradingDay)]
+		}
+	}
+
+	return TradingPhaseContinuous
+}
+
+// IsAuction reports whether the orders collected in the phase are uncrossed
+// at its end.
+func (p TradingPhase) IsAuction() bool {
+	return p == TradingPhaseAuction || p == TradingPhaseClosingAuction || p == TradingPhaseVolatilityAuction
+}
+
+// IsHalt reports whether the phase interrupts continuous trading.
+func (p TradingPhase) IsHalt() bool {
+	return p == TradingPhaseHalted || p == TradingPhaseVolatilityAuction
+}
+
+// MarketState is the trading phase of a market kept by the dealer across
+// restarts. A market without a state is in continuous trading. ResumeAt is
+// the end of the cooldown of a halted market, and ResumePhase is the phase a
+// market halted by an operator resumes in, continuous trading when empty.
+type MarketState struct {
+	Symbol      string       `gorm:"primaryKey;column:symbol" json:"symbol"`
+	Phase       TradingPhase `gorm:"column:phase" json:"phase"`
+	ResumeAt    *time.Time   `gorm:"column:resume_at" json:"resume_at,omitempty"`
+	ResumePhase TradingPhase `gorm:"column:resume_phase" json:"resume_phase,omitempty"`
+	Sequence    int64        `gorm:"column:sequence" json:"-"`
+	// ReferencePrice is the price of the last auction of the market, zero
+	// before its first auction.
+	ReferencePrice Decimal   `gorm:"column:reference_price" json:"-"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+var _ schema.Tabler = (*MarketState)(nil)
+
+func (MarketState) TableName() string {
+	return "market_state"
+}
+
+// MarketStatus is the trading phase of a market, with the price and volume
+// the running auction would execute if it ended now.
+type MarketStatus struct {
+	Phase            TradingPhase `json:"phase"`
+	IndicativePrice  Decimal      `json:"indicative_price,omitempty"`
+	IndicativeVolume uint         `json:"indicative_volume,omitempty"`
+	ResumeAt         *time.Time   `json:"resume_at,omitempty"`
+}
+
+func (s *MarketStatus) Equal(other *MarketStatus) bool {
+	if s.Phase != other.Phase || s.IndicativePrice != other.IndicativePrice || s.IndicativeVolume != other.IndicativeVolume {
+		return false
+	}
+	if s.ResumeAt == nil || other.ResumeAt == nil {
+		return s.ResumeAt == other.ResumeAt
+	}
+
+	return s.ResumeAt.Equal(*other.ResumeAt)
+}
+
+// PriceBand limits the prices of a market. Orders priced further than Static
+// from the reference price are rejected, and a deal further than Dynamic from
+// the last trading price before the order moves the market to the Breaker
+// phase for Cooldown. The bands are fractions of the price, and zero disables
+// a band.
+type PriceBand struct {
+	Static   Decimal       `json:"static"`
+	Dynamic  Decimal       `json:"dynamic"`
+	Breaker  TradingPhase  `json:"breaker"`
+	Cooldown time.Duration `json:"cooldown"`
+}
+
+// TradingSchedule is the time of the day each phase starts in Location. The
+// market is closed before PreOpen and from Close.
+type TradingSchedule struct {
+	Location       *time.Location
+	PreOpen        time.Duration
+	Auction        time.Duration
+	Continuous     time.Duration
+	ClosingAuction time.Duration
+	Close          time.Duration
+}
+
+func (s *TradingSchedule) Phase(now time.Time) TradingPhase {
+	local := now.In(s.Location)
+	offset := time.Duration(local.Hour())*time.Hour +
+		time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second +
+		time.Duration(local.Nanosecond())
+
+	switch {
+	case offset < s.PreOpen || offset >= s.Close:
+		return TradingPhaseClosed
+	case offset < s.Auction:
+		return TradingPhasePreOpen
+	case offset < s.Continuous:
+		return TradingPhaseAuction
+	case offset < s.ClosingAuction:
+		return TradingPhaseContinuous
+	default:
+		return TradingPhaseClosingAuction
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTradingPhaseNext(t *testing.T) {
+	assert.Equal(t, TradingPhaseAuction, TradingPhasePreOpen.Next())
+	assert.Equal(t, TradingPhaseContinuous, TradingPhaseAuction.Next())
+	assert.Equal(t, TradingPhaseClosingAuction, TradingPhaseContinuous.Next())
+	assert.Equal(t, TradingPhaseClosed, TradingPhaseClosingAuction.Next())
+	assert.Equal(t, TradingPhasePreOpen, TradingPhaseClosed.Next())
+	assert.Equal(t, TradingPhaseContinuous, TradingPhaseHalted.Next())
+	assert.Equal(t, TradingPhaseContinuous, TradingPhaseVolatilityAuction.Next())
+	assert.False(t, TradingPhaseHalted.IsValid())
+	assert.True(t, TradingPhaseVolatilityAuction.IsAuction())
+	assert.False(t, TradingPhase("halted_forever").IsValid())
+}
+
+func TestTradingSchedulePhase(t *testing.T) {
+	location := time.FixedZone("UTC+8", 8*60*60)
+	schedule := &TradingSchedule{
+		Location:       location,
+		PreOpen:        8*time.Hour + 30*time.Minute,
+		Auction:        8*time.Hour + 50*time.Minute,
+		Continuous:     9 * time.Hour,
+		ClosingAuction: 16*time.Hour + 50*time.Minute,
+		Close:          17 * time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected TradingPhase
+	}{
+		{name: "Before pre-open", now: time.Date(2022, 8, 1, 8, 29, 59, 0, location), expected: TradingPhaseClosed},
+		{name: "Pre-open", now: time.Date(2022, 8, 1, 8, 30, 0, 0, location), expected: TradingPhasePreOpen},
+		{name: "Opening auction", now: time.Date(2022, 8, 1, 8, 55, 0, 0, location), expected: TradingPhaseAuction},
+		{name: "Continuous", now: time.Date(2022, 8, 1, 1, 0, 0, 0, time.UTC), expected: TradingPhaseContinuous},
+		{name: "Closing auction", now: time.Date(2022, 8, 1, 16, 59, 59, 0, location), expected: TradingPhaseClosingAuction},
+		{name: "Closed", now: time.Date(2022, 8, 1, 17, 0, 0, 0, location), expected: TradingPhaseClosed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, schedule.Phase(test.now))
+		})
+	}
+}
+
+func TestMarketStatusEqual(t *testing.T) {
+	resumeAt := time.Date(2022, 8, 1, 0, 5, 0, 0, time.UTC)
+	sameResumeAt := resumeAt
+	status := &MarketStatus{Phase: TradingPhaseHalted, ResumeAt: &resumeAt}
+
+	assert.True(t, status.Equal(&MarketStatus{Phase: TradingPhaseHalted, ResumeAt: &sameResumeAt}))
+	assert.False(t, status.Equal(&MarketStatus{Phase: TradingPhaseHalted}))
+	assert.False(t, status.Equal(&MarketStatus{Phase: TradingPhaseVolatilityAuction, ResumeAt: &resumeAt}))
+	assert.True(t, (&MarketStatus{Phase: TradingPhaseContinuous}).Equal(&MarketStatus{Phase: TradingPhaseContinuous}))
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// OrderQuery filters the orders listed by the query API. Zero fields are not
+// filtered, and the orders are returned by ID after Cursor.
+type OrderQuery struct {
+	UserID      int64
+	Symbol      string
+	OrderType   OrderType
+	Status      OrderStatus
+	PriceType   PriceType
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Cursor      int64
+	Limit       int
+}
+
+// CandleQuery filters the candles listed by the query API. Nil times are not
+// filtered, and the candles are returned by open time.
+type CandleQuery struct {
+	Symbol   string
+	Interval CandleInterval
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// User owns orders and signs its requests with the secret of its API key. An
+// admin user may also call the admin API.
+type User struct {
+	ID        int64     `gorm:"primaryKey;column:id" json:"id"`
+	Name      string    `gorm:"column:name" json:"name"`
+	APIKey    string    `gorm:"column:api_key" json:"api_key"`
+	APISecret string    `gorm:"column:api_secret" json:"-"`
+	IsAdmin   bool      `gorm:"column:is_admin" json:"is_admin"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+var _ schema.Tabler = (*User)(nil)
+
+func (User) TableName() string {
+	return "user"
+}
+
+// SignedRequest is the part of an HTTP request covered by its signature.
+type SignedRequest struct {
+	APIKey    string
+	Timestamp int64
+	Signature string
+	Method    string
+	Path      string
+	Body      []byte
+}
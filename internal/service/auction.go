@@ -0,0 +1,221 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"sort"
+	"time"
+)
+
+// auctionSide is the remaining quantity of one book by limit price, with the
+// quantity of the market orders, which take any price.
+type auctionSide struct {
+	market uint
+	limits map[models.Decimal]uint
+}
+
+func newAuctionSide(book OrderBookInterface) *auctionSide {
+	side := &auctionSide{limits: make(map[models.Decimal]uint)}
+	book.Range(func(order *models.Order) bool {
+		if order.MatchPriceType() == models.PriceTypeMarket {
+			side.market += order.RemainQuantity
+		} else {
+			side.limits[order.Price] += order.RemainQuantity
+		}
+		return true
+	})
+
+	return side
+}
+
+type auctionPrice struct {
+	price   models.Decimal
+	volume  uint
+	surplus int64
+}
+
+// status is the phase of the market, with the indicative price and volume of
+// a running auction.
+func (m *market) status() *models.MarketStatus {
+	status := &models.MarketStatus{Phase: m.phase, ResumeAt: m.resumeAt}
+	if m.phase.IsAuction() {
+		status.IndicativePrice, status.IndicativeVolume = m.equilibrium()
+	}
+
+	return status
+}
+
+// equilibrium returns the price that executes the largest volume between the
+// books, and that volume. A tie is broken by the smallest surplus, then by
+// the market pressure, which is the highest price when all the tied prices
+// leave a buy surplus and the lowest when they all leave a sell surplus, and
+// at last by the price closest to the last trading price, the lower one when
+// still tied. The limit prices of both books are the candidates, and books
+// with only market orders uncross at the market price.
+func (m *market) equilibrium() (models.Decimal, uint) {
+	bids := newAuctionSide(m.buyBook)
+	asks := newAuctionSide(m.sellBook)
+
+	var prices []models.Decimal
+	for price := range bids.limits {
+		prices = append(prices, price)
+	}
+	for price := range asks.limits {
+		if _, ok := bids.limits[price]; !ok {
+			prices = append(prices, price)
+		}
+	}
+	if len(prices) == 0 && m.marketPrice() > 0 {
+		prices = append(prices, m.marketPrice())
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+
+	askVolumes := make([]uint, len(prices))
+	volume := asks.market
+	for i, price := range prices {
+		volume += asks.limits[price]
+		askVolumes[i] = volume
+	}
+
+	candidates := make([]*auctionPrice, len(prices))
+	bidVolume := bids.market
+	for i := len(prices) - 1; i >= 0; i-- {
+		bidVolume += bids.limits[prices[i]]
+		candidates[i] = &auctionPrice{
+			price:   prices[i],
+			volume:  askVolumes[i],
+			surplus: int64(bidVolume) - int64(askVolumes[i]),
+		}
+		if bidVolume < askVolumes[i] {
+			candidates[i].volume = bidVolume
+		}
+	}
+
+	candidates = bestAuctionPrices(candidates, func(c *auctionPrice) int64 { return int64(c.volume) })
+	if len(candidates) == 0 || candidates[0].volume == 0 {
+		return 0, 0
+	}
+	candidates = bestAuctionPrices(candidates, func(c *auctionPrice) int64 { return -abs(c.surplus) })
+
+	buyPressure, sellPressure := true, true
+	for _, c := range candidates {
+		buyPressure = buyPressure && c.surplus > 0
+		sellPressure = sellPressure && c.surplus < 0
+	}
+	switch {
+	case buyPressure:
+		best := candidates[len(candidates)-1]
+		return best.price, best.volume
+	case sellPressure:
+		return candidates[0].price, candidates[0].volume
+	}
+
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if abs(int64(c.price-m.lastTradingPrice)) < abs(int64(best.price-m.lastTradingPrice)) {
+			best = c
+		}
+	}
+
+	return best.price, best.volume
+}
+
+// bestAuctionPrices keeps the candidates of the largest key.
+func bestAuctionPrices(candidates []*auctionPrice, key func(*auctionPrice) int64) []*auctionPrice {
+	var best []*auctionPrice
+	for _, c := range candidates {
+		switch {
+		case len(best) == 0 || key(c) > key(best[0]):
+			best = []*auctionPrice{c}
+		case key(c) == key(best[0]):
+			best = append(best, c)
+		}
+	}
+
+	return best
+}
+
+// endAuction uncrosses the orders collected in an auction and cancels the
+// market orders left, as they do not rest in continuous trading.
+func (m *market) endAuction(result *matchResult, now time.Time) {
+	m.uncross(result, now)
+
+	for _, book := range []OrderBookInterface{m.buyBook, m.sellBook} {
+		var unfilled []*models.Order
+		book.Range(func(order *models.Order) bool {
+			if order.MatchPriceType() == models.PriceTypeMarket {
+				unfilled = append(unfilled, order)
+			}
+			return true
+		})
+
+		for _, order := range unfilled {
+			book.RemoveOrder(order.ID)
+			result.cancel(order, models.CancelReasonUnfilled, now)
+			result.orders = append(result.orders, order)
+		}
+	}
+}
+
+// uncross executes all the crossing orders collected in an auction at the
+// equilibrium price. Of the two orders of a deal, the one that entered the
+// book later is the taker.
+func (m *market) uncross(result *matchResult, now time.Time) {
+	price, volume := m.equilibrium()
+	if volume == 0 {
+		return
+	}
+	m.auctionPrice = price
+
+	for {
+		bid, ask := m.buyBook.Peek(), m.sellBook.Peek()
+		if bid == nil || ask == nil || !isPriceMatch(bid, price) || !isPriceMatch(ask, price) {
+			return
+		}
+
+		takerOrder, makerOrder := bid, ask
+		takerBook, makerBook := m.buyBook, m.sellBook
+		if bid.Before(ask) {
+			takerOrder, makerOrder = ask, bid
+			takerBook, makerBook = m.sellBook, m.buyBook
+		}
+
+		if isSelfTrade(takerOrder, makerOrder) {
+			if !m.preventSelfTrade(takerOrder, makerOrder, makerBook, result, now) {
+				takerBook.RemoveOrder(takerOrder.ID)
+			}
+			result.orders = append(result.orders, takerOrder)
+			continue
+		}
+
+		quantity := bid.RemainQuantity
+		if ask.RemainQuantity < quantity {
+			quantity = ask.RemainQuantity
+		}
+		if affordable := affordableQuantity(bid, price); affordable < quantity {
+			quantity = affordable
+		}
+		if quantity == 0 {
+			m.buyBook.Dequeue()
+			result.cancel(bid, models.CancelReasonInsufficientFunds, now)
+			result.orders = append(result.orders, bid)
+			continue
+		}
+
+		m.trade(takerOrder, makerOrder, quantity, price, result, now)
+		result.orders = append(result.orders, bid, ask)
+		if bid.RemainQuantity == 0 {
+			m.buyBook.Dequeue()
+		}
+		if ask.RemainQuantity == 0 {
+			m.sellBook.Dequeue()
+		}
+	}
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
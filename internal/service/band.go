@@ -0,0 +1,94 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"time"
+)
+
+// priceBand is the range of prices allowed around a reference price, the
+// zero value allows any price.
+type priceBand struct {
+	low  models.Decimal
+	high models.Decimal
+}
+
+func newPriceBand(reference, width models.Decimal) priceBand {
+	if reference == 0 || width == 0 {
+		return priceBand{}
+	}
+
+	delta := reference.MulRate(width)
+	return priceBand{low: reference - delta, high: reference + delta}
+}
+
+func (b priceBand) contains(price models.Decimal) bool {
+	return b.high == 0 || (price >= b.low && price <= b.high)
+}
+
+// intersect returns the prices allowed by both bands.
+func (b priceBand) intersect(other priceBand) priceBand {
+	switch {
+	case b.high == 0:
+		return other
+	case other.high == 0:
+		return b
+	}
+
+	if other.low > b.low {
+		b.low = other.low
+	}
+	if other.high < b.high {
+		b.high = other.high
+	}
+	return b
+}
+
+// staticBand is the range of the order prices a market accepts, around its
+// reference price.
+func (m *market) staticBand() priceBand {
+	return newPriceBand(m.referencePrice(), m.instrument.PriceBand.Static)
+}
+
+// referencePrice is the price of the last auction of the market, or the
+// reference price of the instrument before its first auction. The auction
+// price is saved with the state of the market, so a restart does not move the
+// static band.
+func (m *market) referencePrice() models.Decimal {
+	if m.auctionPrice != 0 {
+		return m.auctionPrice
+	}
+
+	return m.instrument.ReferencePrice
+}
+
+// dynamicBand is the range of the deal prices around the last trading price.
+func (m *market) dynamicBand() priceBand {
+	return newPriceBand(m.lastTradingPrice, m.instrument.PriceBand.Dynamic)
+}
+
+// slippageBand is the range of the deal prices of a market order around the
+// best price of the maker book when the order arrives.
+func (m *market) slippageBand(takerOrder *models.Order, makerBook OrderBookInterface) priceBand {
+	if m.instrument.MaxSlippage == 0 || takerOrder.MatchPriceType() != models.PriceTypeMarket {
+		return priceBand{}
+	}
+
+	makerOrder := makerBook.Peek()
+	if makerOrder == nil {
+		return priceBand{}
+	}
+
+	return newPriceBand(makerPrice(makerOrder, m.marketPrice()), m.instrument.MaxSlippage)
+}
+
+// trip moves the market to its circuit breaker phase for the cooldown.
+func (m *market) trip(result *matchResult, now time.Time) {
+	resumeAt := now.Add(m.instrument.PriceBand.Cooldown)
+	m.phase = m.instrument.PriceBand.Breaker
+	m.resumeAt = &resumeAt
+	result.state = m.state(now)
+}
+
+func (m *market) state(now time.Time) *models.MarketState {
+	return &models.MarketState{Symbol: m.instrument.Symbol, Phase: m.phase, ResumeAt: m.resumeAt, ResumePhase: m.resumePhase, Sequence: m.sequence, ReferencePrice: m.auctionPrice, UpdatedAt: now}
+}
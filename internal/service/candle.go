@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/dao"
+	"dealer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CandleBackfillInterface interface {
+	Backfill(ctx context.Context, symbol string) (int, error)
+}
+
+// CandleBackfill rebuilds the candles from the deal table. It should run
+// while the dealer is stopped, otherwise the deals committed during the
+// backfill may be missing from or counted twice in the candles.
+type CandleBackfill struct {
+	db        *gorm.DB
+	dealDAO   dao.DealInterface
+	candleDAO dao.CandleInterface
+	batchSize int
+}
+
+var _ CandleBackfillInterface = (*CandleBackfill)(nil)
+
+func NewCandleBackfill(db *gorm.DB, dealDAO dao.DealInterface, candleDAO dao.CandleInterface, batchSize int) *CandleBackfill {
+	return &CandleBackfill{
+		db:        db,
+		dealDAO:   dealDAO,
+		candleDAO: candleDAO,
+		batchSize: batchSize,
+	}
+}
+
+// Backfill replaces the candles of the symbol, or of all symbols when it is
+// empty, with the candles aggregated from its deals in one transaction, and
+// returns the number of deals aggregated.
+func (b *CandleBackfill) Backfill(ctx context.Context, symbol string) (int, error) {
+	tx := b.db.Begin()
+	if err := b.candleDAO.Delete(ctx, tx, symbol); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var count int
+	var cursor int64
+	for {
+		deals, err := b.dealDAO.ListAfter(ctx, tx, symbol, cursor, b.batchSize)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if err := b.candleDAO.Upsert(ctx, tx, aggregateCandles(deals)); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		count += len(deals)
+		if len(deals) < b.batchSize {
+			break
+		}
+		cursor = deals[len(deals)-1].ID
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// aggregateCandles builds the candles of every interval from deals in
+// execution order.
+func aggregateCandles(deals []*models.Deal) []*models.Candle {
+	type key struct {
+		symbol   string
+		interval models.CandleInterval
+		openTime int64
+	}
+
+	var candles []*models.Candle
+	index := make(map[key]*models.Candle)
+	for _, interval := range models.CandleIntervals {
+		for _, deal := range deals {
+			openTime := interval.OpenTime(deal.CreatedAt)
+			k := key{symbol: deal.Symbol, interval: interval, openTime: openTime.UnixNano()}
+			candle, ok := index[k]
+			if !ok {
+				candle = &models.Candle{
+					Symbol:   deal.Symbol,
+					Interval: interval,
+					OpenTime: openTime,
+					Open:     deal.Price,
+					High:     deal.Price,
+					Low:      deal.Price,
+				}
+				index[k] = candle
+				candles = append(candles, candle)
+			}
+
+			if deal.Price > candle.High {
+				candle.High = deal.Price
+			}
+			if deal.Price < candle.Low {
+				candle.Low = deal.Price
+			}
+			candle.Close = deal.Price
+			candle.Volume += uint64(deal.Quantity)
+		}
+	}
+
+	return candles
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockDAO "dealer/internal/mock/dao"
+	"dealer/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type CandleBackfillTestSuite struct {
+	suite.Suite
+	ctrl          *gomock.Controller
+	db            *sql.DB
+	mockDB        sqlmock.Sqlmock
+	mockGormDB    *gorm.DB
+	mockDealDAO   *mockDAO.MockDealInterface
+	mockCandleDAO *mockDAO.MockCandleInterface
+	svc           *CandleBackfill
+}
+
+func (t *CandleBackfillTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockDealDAO = mockDAO.NewMockDealInterface(t.ctrl)
+	t.mockCandleDAO = mockDAO.NewMockCandleInterface(t.ctrl)
+	t.svc = NewCandleBackfill(t.mockGormDB, t.mockDealDAO, t.mockCandleDAO, 2)
+}
+
+func (t *CandleBackfillTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestCandleBackfillTestSuite(t *testing.T) {
+	suite.Run(t, new(CandleBackfillTestSuite))
+}
+
+func (t *CandleBackfillTestSuite) TestBackfill() {
+	errSQL := errors.New("")
+	deals := []*models.Deal{
+		{ID: 1, Symbol: testSymbol, Quantity: 1, Price: 10, CreatedAt: testNow},
+		{ID: 2, Symbol: testSymbol, Quantity: 1, Price: 11, CreatedAt: testNow},
+		{ID: 3, Symbol: testSymbol, Quantity: 1, Price: 12, CreatedAt: testNow},
+	}
+	tests := []struct {
+		name     string
+		fn       func()
+		expected int
+		err      error
+	}{
+		{
+			name: "Backfill success",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockCandleDAO.EXPECT().Delete(context.Background(), gomock.Any(), testSymbol).Return(nil)
+				t.mockDealDAO.EXPECT().ListAfter(context.Background(), gomock.Any(), testSymbol, int64(0), 2).Return(deals[:2], nil)
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), aggregateCandles(deals[:2])).Return(nil)
+				t.mockDealDAO.EXPECT().ListAfter(context.Background(), gomock.Any(), testSymbol, int64(2), 2).Return(deals[2:], nil)
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), aggregateCandles(deals[2:])).Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			expected: 3,
+			err:      nil,
+		},
+		{
+			name: "Backfill delete failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockCandleDAO.EXPECT().Delete(context.Background(), gomock.Any(), testSymbol).Return(errSQL)
+				t.mockDB.ExpectRollback()
+			},
+			expected: 0,
+			err:      errSQL,
+		},
+		{
+			name: "Backfill list deals failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockCandleDAO.EXPECT().Delete(context.Background(), gomock.Any(), testSymbol).Return(nil)
+				t.mockDealDAO.EXPECT().ListAfter(context.Background(), gomock.Any(), testSymbol, int64(0), 2).Return(nil, errSQL)
+				t.mockDB.ExpectRollback()
+			},
+			expected: 0,
+			err:      errSQL,
+		},
+		{
+			name: "Backfill upsert failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockCandleDAO.EXPECT().Delete(context.Background(), gomock.Any(), testSymbol).Return(nil)
+				t.mockDealDAO.EXPECT().ListAfter(context.Background(), gomock.Any(), testSymbol, int64(0), 2).Return(deals[:2], nil)
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(errSQL)
+				t.mockDB.ExpectRollback()
+			},
+			expected: 0,
+			err:      errSQL,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := t.svc.Backfill(context.Background(), testSymbol)
+			t.Equal(test.err, err)
+			t.Equal(test.expected, actual)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAggregateCandles(t *testing.T) {
+	at := func(minute, second int) time.Time {
+		return time.Date(2022, 8, 1, 10, minute, second, 0, time.UTC)
+	}
+	deals := []*models.Deal{
+		{Symbol: testSymbol, Quantity: 1, Price: 10, CreatedAt: at(0, 10)},
+		{Symbol: testSymbol, Quantity: 2, Price: 12, CreatedAt: at(0, 20)},
+		{Symbol: testSymbol, Quantity: 3, Price: 9, CreatedAt: at(0, 30)},
+		{Symbol: testSymbol, Quantity: 4, Price: 11, CreatedAt: at(1, 0)},
+	}
+
+	assert.Equal(t, []*models.Candle{
+		{Symbol: testSymbol, Interval: models.CandleInterval1m, OpenTime: at(0, 0), Open: 10, High: 12, Low: 9, Close: 9, Volume: 6},
+		{Symbol: testSymbol, Interval: models.CandleInterval1m, OpenTime: at(1, 0), Open: 11, High: 11, Low: 11, Close: 11, Volume: 4},
+		{Symbol: testSymbol, Interval: models.CandleInterval5m, OpenTime: at(0, 0), Open: 10, High: 12, Low: 9, Close: 11, Volume: 10},
+		{Symbol: testSymbol, Interval: models.CandleInterval1h, OpenTime: at(0, 0), Open: 10, High: 12, Low: 9, Close: 11, Volume: 10},
+		{Symbol: testSymbol, Interval: models.CandleInterval1d, OpenTime: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC), Open: 10, High: 12, Low: 9, Close: 11, Volume: 10},
+	}, aggregateCandles(deals))
+	assert.Nil(t, aggregateCandles(nil))
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/logger"
+	"dealer/internal/models"
+	"dealer/internal/sdk"
+	"encoding/json"
+	"errors"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// rejections are the errors of messages the dealer has handled by refusing
+// them. Retrying such a message gives the same result, so it is acked.
+var rejections = []error{
+	ErrUnknownSymbol,
+	ErrInvalidOrderType,
+	ErrOrderNotFound,
+	ErrOrderClosed,
+	ErrQuantityFilled,
+	ErrPriceNotAmendable,
+	ErrInsufficientFunds,
+	ErrMarketClosed,
+	ErrContinuousOnly,
+	ErrInvalidPhase,
+	ErrMarketHalted,
+	ErrOutsidePriceBand,
+	models.ErrInvalidStatusTransition,
+}
+
+type ConsumerInterface interface {
+	Run(context.Context) error
+	Handle(context.Context, amqp.Delivery) error
+}
+
+// Consumer feeds the order queue to the dealer one message at a time and acks
+// a message only after its deal transaction has been committed. A failed
+// message is retried with exponential backoff after the dealer has been
+// recovered from the DB, so the books never keep the effects of an
+// uncommitted transaction. A message that still fails after the last attempt,
+// or that can not be decoded, is rejected to the dead letter queue. At most
+// prefetch messages are delivered before they are acked.
+type Consumer struct {
+	ch          sdk.AMQPChannel
+	queueName   string
+	prefetch    int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	dealer      DealerInterface
+}
+
+var _ ConsumerInterface = (*Consumer)(nil)
+
+func NewConsumer(ch sdk.AMQPChannel, queueName string, prefetch, maxAttempts int, backoff, maxBackoff time.Duration, dealer DealerInterface) *Consumer {
+	return &Consumer{
+		ch:          ch,
+		queueName:   queueName,
+		prefetch:    prefetch,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+		dealer:      dealer,
+	}
+}
+
+// Run consumes the order queue until the channel is closed. It returns an
+// error when the dealer can not be recovered, as the books are no longer
+// consistent with the DB.
+func (c *Consumer) Run(ctx context.Context) error {
+	if err := c.ch.Qos(c.prefetch, 0, false); err != nil {
+		return err
+	}
+
+	msgs, err := c.ch.Consume(c.queueName, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	for msg := range msgs {
+		if err := c.Handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Consumer) Handle(ctx context.Context, delivery amqp.Delivery) error {
+	var message *models.Message
+	if err := json.Unmarshal(delivery.Body, &message); err != nil || message == nil {
+		logger.GetLogger().Errorf("dead-letter undecodable message %d: %v", delivery.DeliveryTag, err)
+		return c.deadLetter(delivery)
+	}
+
+	message.Sequence, _ = delivery.Headers[sequenceHeader].(int64)
+
+	backoff := c.backoff
+	for attempt := 1; ; attempt++ {
+		err := c.dealer.ProcessMessage(ctx, message)
+		if err == nil {
+			return c.ack(delivery)
+		}
+
+		if isRejection(err) {
+			logger.GetLogger().Warn(err.Error())
+			return c.ack(delivery)
+		}
+
+		if errors.Is(err, ErrInvalidMessage) {
+			logger.GetLogger().Errorf("dead-letter invalid message %d: %v", delivery.DeliveryTag, err)
+			return c.deadLetter(delivery)
+		}
+
+		logger.GetLogger().Errorf("attempt %d of message %d failed: %v", attempt, delivery.DeliveryTag, err)
+		if err := c.dealer.Recover(ctx); err != nil {
+			return err
+		}
+
+		if attempt >= c.maxAttempts {
+			return c.deadLetter(delivery)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+func (c *Consumer) ack(delivery amqp.Delivery) error {
+	if err := delivery.Ack(false); err != nil {
+		logger.GetLogger().Error(err.Error())
+	}
+	return nil
+}
+
+func (c *Consumer) deadLetter(delivery amqp.Delivery) error {
+	if err := delivery.Nack(false, false); err != nil {
+		logger.GetLogger().Error(err.Error())
+	}
+	return nil
+}
+
+func isRejection(err error) bool {
+	for _, rejection := range rejections {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+
+	return false
+}
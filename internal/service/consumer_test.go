@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockSDK "dealer/internal/mock/sdk"
+	mockService "dealer/internal/mock/service"
+	"dealer/internal/models"
+
+	"github.com/golang/mock/gomock"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConsumerTestSuite struct {
+	suite.Suite
+	ctrl             *gomock.Controller
+	mockChannel      *mockSDK.MockAMQPChannel
+	mockAcknowledger *mockSDK.MockAMQPAcknowledger
+	mockDealer       *mockService.MockDealerInterface
+	svc              *Consumer
+}
+
+func (t *ConsumerTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	t.mockChannel = mockSDK.NewMockAMQPChannel(t.ctrl)
+	t.mockAcknowledger = mockSDK.NewMockAMQPAcknowledger(t.ctrl)
+	t.mockDealer = mockService.NewMockDealerInterface(t.ctrl)
+	t.svc = NewConsumer(t.mockChannel, "name", 2, 3, time.Millisecond, 2*time.Millisecond, t.mockDealer)
+}
+
+func (t *ConsumerTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+}
+
+func TestConsumerTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsumerTestSuite))
+}
+
+func (t *ConsumerTestSuite) TestHandle() {
+	message := &models.Message{Type: models.MessageTypeNewOrder, Order: &models.Order{ID: 1}}
+	body := []byte(`{"type":1,"order":{"id":1}}`)
+	tests := []struct {
+		name     string
+		body     []byte
+		headers  amqp.Table
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Handle processed message",
+			body: body,
+			fn: func() {
+				t.mockDealer.EXPECT().ProcessMessage(context.Background(), message).Return(nil)
+				t.mockAcknowledger.EXPECT().Ack(uint64(1), false).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name:    "Handle message with sequence",
+			body:    body,
+			headers: amqp.Table{"sequence": int64(7)},
+			fn: func() {
+				t.mockDealer.EXPECT().ProcessMessage(context.Background(), &models.Message{Sequence: 7, Type: models.MessageTypeNewOrder, Order: &models.Order{ID: 1}}).Return(nil)
+				t.mockAcknowledger.EXPECT().Ack(uint64(1), false).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Handle rejected message",
+			body: body,
+			fn: func() {
+				t.mockDealer.EXPECT().ProcessMessage(context.Background(), message).Return(ErrOrderClosed)
+				t.mockAcknowledger.EXPECT().Ack(uint64(1), false).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Handle undecodable message",
+			body: []byte(`{`),
+			fn: func() {
+				t.mockAcknowledger.EXPECT().Nack(uint64(1), false, false).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Handle invalid message",
+			body: []byte(`{"type":9}`),
+			fn: func() {
+				t.mockDealer.EXPECT().ProcessMessage(context.Background(), &models.Message{Type: 9}).Return(ErrInvalidMessage)
+				t.mockAcknowledger.EXPECT().Nack(uint64(1), false, false).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Handle message succeeded after retry",
+			body: body,
+			fn: func() {
+				gomock.InOrder(
+					t.mockDealer.EXPECT().ProcessMessage(context.Background(), message).Return(errors.New("")),
+					t.mockDealer.EXPECT().Recover(context.Background()).Return(nil),
+					t.mockDealer.EXPECT().ProcessMessage(context.Background(), message).Return(nil),
+					t.mockAcknowledger.EXPECT().Ack(uint64(1), false).Return(nil),
+				)
+			},
+			hasError: false,
+		},
+		{
+			name: "Handle message failed after all attempts",
+			body: body,
+			fn: func() {
+				t.mockDealer.EXPECT().ProcessMessage(context.Background(), message).Return(errors.New("")).Times(3)
+				t.mockDealer.EXPECT().Recover(context.Background()).Return(nil).Times(3)
+				t.mockAcknowledger.EXPECT().Nack(uint64(1), false, false).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Handle message recover failed",
+			body: body,
+			fn: func() {
+				t.mockDealer.EXPECT().ProcessMessage(context.Background(), message).Return(errors.New(""))
+				t.mockDealer.EXPECT().Recover(context.Background()).Return(errors.New(""))
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.Handle(context.Background(), amqp.Delivery{Acknowledger: t.mockAcknowledger, Headers: test.headers, DeliveryTag: 1, Body: test.body})
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *ConsumerTestSuite) TestRun() {
+	msgs := make(chan amqp.Delivery, 1)
+	msgs <- amqp.Delivery{Acknowledger: t.mockAcknowledger, DeliveryTag: 1, Body: []byte(`{`)}
+	close(msgs)
+
+	gomock.InOrder(
+		t.mockChannel.EXPECT().Qos(2, 0, false).Return(nil),
+		t.mockChannel.EXPECT().Consume("name", "", false, false, false, false, nil).Return((<-chan amqp.Delivery)(msgs), nil),
+	)
+	t.mockAcknowledger.EXPECT().Nack(uint64(1), false, false).Return(nil)
+
+	t.NoError(t.svc.Run(context.Background()))
+}
+
+func (t *ConsumerTestSuite) TestRunQosFailed() {
+	t.mockChannel.EXPECT().Qos(2, 0, false).Return(errors.New(""))
+
+	t.Error(t.svc.Run(context.Background()))
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/logger"
+	"dealer/internal/models"
+	"dealer/internal/sdk"
+	"encoding/json"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type DeadLetterInterface interface {
+	List(ctx context.Context, limit int) ([]*models.DeadLetter, error)
+	Replay(ctx context.Context, limit int) (int, error)
+}
+
+// DeadLetter inspects the dead letter queue and publishes its messages back
+// to the order queue.
+type DeadLetter struct {
+	ch              sdk.AMQPChannel
+	confirms        <-chan amqp.Confirmation
+	queueName       string
+	deadLetterQueue string
+}
+
+var _ DeadLetterInterface = (*DeadLetter)(nil)
+
+func NewDeadLetter(ch sdk.AMQPChannel, queueName, deadLetterQueue string) *DeadLetter {
+	return &DeadLetter{
+		ch:              ch,
+		confirms:        ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+		queueName:       queueName,
+		deadLetterQueue: deadLetterQueue,
+	}
+}
+
+// List returns up to limit dead letters without removing them from the queue.
+func (d *DeadLetter) List(ctx context.Context, limit int) ([]*models.DeadLetter, error) {
+	var deliveries []amqp.Delivery
+	defer func() {
+		for _, delivery := range deliveries {
+			if err := delivery.Nack(false, true); err != nil {
+				logger.GetLogger().Error(err.Error())
+			}
+		}
+	}()
+
+	var deadLetters []*models.DeadLetter
+	for len(deliveries) < limit {
+		delivery, ok, err := d.ch.Get(d.deadLetterQueue, false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		deliveries = append(deliveries, delivery)
+		deadLetters = append(deadLetters, newDeadLetter(delivery))
+	}
+
+	return deadLetters, nil
+}
+
+// Replay moves up to limit dead letters back to the order queue and returns
+// how many of them were moved.
+func (d *DeadLetter) Replay(ctx context.Context, limit int) (int, error) {
+	for n := 0; n < limit; n++ {
+		delivery, ok, err := d.ch.Get(d.deadLetterQueue, false)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			return n, nil
+		}
+
+		if err := publish(ctx, d.ch, d.confirms, d.queueName, amqp.Publishing{Headers: delivery.Headers, ContentType: delivery.ContentType, Body: delivery.Body}); err != nil {
+			if err := delivery.Nack(false, true); err != nil {
+				logger.GetLogger().Error(err.Error())
+			}
+			return n, err
+		}
+
+		if err := delivery.Ack(false); err != nil {
+			return n, err
+		}
+	}
+
+	return limit, nil
+}
+
+func newDeadLetter(delivery amqp.Delivery) *models.DeadLetter {
+	deadLetter := &models.DeadLetter{Body: string(delivery.Body)}
+
+	var message *models.Message
+	if err := json.Unmarshal(delivery.Body, &message); err == nil {
+		deadLetter.Message = message
+	}
+
+	deaths, _ := delivery.Headers["x-death"].([]interface{})
+	if len(deaths) == 0 {
+		return deadLetter
+	}
+
+	death, _ := deaths[0].(amqp.Table)
+	deadLetter.Reason, _ = death["reason"].(string)
+	deadLetter.Count, _ = death["count"].(int64)
+	if t, ok := death["time"].(time.Time); ok {
+		deadLetter.DiedAt = &t
+	}
+
+	return deadLetter
+}
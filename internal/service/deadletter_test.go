@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockSDK "dealer/internal/mock/sdk"
+	"dealer/internal/models"
+
+	"github.com/golang/mock/gomock"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadLetterTestSuite struct {
+	suite.Suite
+	ctrl             *gomock.Controller
+	mockChannel      *mockSDK.MockAMQPChannel
+	confirms         chan amqp.Confirmation
+	mockAcknowledger *mockSDK.MockAMQPAcknowledger
+	svc              *DeadLetter
+}
+
+func (t *DeadLetterTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	t.mockChannel = mockSDK.NewMockAMQPChannel(t.ctrl)
+	t.confirms = make(chan amqp.Confirmation, 1)
+	t.mockChannel.EXPECT().NotifyPublish(gomock.Any()).Return(t.confirms)
+	t.mockAcknowledger = mockSDK.NewMockAMQPAcknowledger(t.ctrl)
+	t.svc = NewDeadLetter(t.mockChannel, "name", "name.dlq")
+}
+
+func (t *DeadLetterTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+}
+
+func TestDeadLetterTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadLetterTestSuite))
+}
+
+func (t *DeadLetterTestSuite) delivery(tag uint64, body string) amqp.Delivery {
+	return amqp.Delivery{Acknowledger: t.mockAcknowledger, DeliveryTag: tag, ContentType: "application/json", Body: []byte(body)}
+}
+
+func (t *DeadLetterTestSuite) confirm(ack bool) func(context.Context, string, string, bool, bool, amqp.Publishing) {
+	return func(context.Context, string, string, bool, bool, amqp.Publishing) {
+		t.confirms <- amqp.Confirmation{Ack: ack}
+	}
+}
+
+func (t *DeadLetterTestSuite) TestList() {
+	diedAt := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	first := t.delivery(1, `{"type":2,"order":{"id":1}}`)
+	first.Headers = amqp.Table{"x-death": []interface{}{amqp.Table{"reason": "rejected", "count": int64(1), "time": diedAt}}}
+	tests := []struct {
+		name     string
+		limit    int
+		fn       func()
+		expected []*models.DeadLetter
+		hasError bool
+	}{
+		{
+			name:  "List dead letters",
+			limit: 10,
+			fn: func() {
+				gomock.InOrder(
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(first, true, nil),
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(2, `{`), true, nil),
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(amqp.Delivery{}, false, nil),
+				)
+				t.mockAcknowledger.EXPECT().Nack(uint64(1), false, true).Return(nil)
+				t.mockAcknowledger.EXPECT().Nack(uint64(2), false, true).Return(nil)
+			},
+			expected: []*models.DeadLetter{
+				{
+					Reason:  "rejected",
+					Count:   1,
+					DiedAt:  &diedAt,
+					Body:    `{"type":2,"order":{"id":1}}`,
+					Message: &models.Message{Type: models.MessageTypeCancelOrder, Order: &models.Order{ID: 1}},
+				},
+				{Body: `{`},
+			},
+			hasError: false,
+		},
+		{
+			name:  "List dead letters up to limit",
+			limit: 1,
+			fn: func() {
+				t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(2, `{`), true, nil)
+				t.mockAcknowledger.EXPECT().Nack(uint64(2), false, true).Return(nil)
+			},
+			expected: []*models.DeadLetter{{Body: `{`}},
+			hasError: false,
+		},
+		{
+			name:  "List dead letters failed",
+			limit: 10,
+			fn: func() {
+				gomock.InOrder(
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(2, `{`), true, nil),
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(amqp.Delivery{}, false, errors.New("")),
+				)
+				t.mockAcknowledger.EXPECT().Nack(uint64(2), false, true).Return(nil)
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			deadLetters, err := t.svc.List(context.Background(), test.limit)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, deadLetters)
+		})
+	}
+}
+
+func (t *DeadLetterTestSuite) TestReplay() {
+	publishing := amqp.Publishing{DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: []byte(`{"type":1}`)}
+	tests := []struct {
+		name     string
+		limit    int
+		fn       func()
+		expected int
+		hasError bool
+	}{
+		{
+			name:  "Replay dead letters",
+			limit: 10,
+			fn: func() {
+				gomock.InOrder(
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(1, `{"type":1}`), true, nil),
+					t.mockChannel.EXPECT().PublishWithContext(context.Background(), "", "name", false, false, publishing).Do(t.confirm(true)).Return(nil),
+					t.mockAcknowledger.EXPECT().Ack(uint64(1), false).Return(nil),
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(amqp.Delivery{}, false, nil),
+				)
+			},
+			expected: 1,
+			hasError: false,
+		},
+		{
+			name:  "Replay dead letters up to limit",
+			limit: 1,
+			fn: func() {
+				gomock.InOrder(
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(1, `{"type":1}`), true, nil),
+					t.mockChannel.EXPECT().PublishWithContext(context.Background(), "", "name", false, false, publishing).Do(t.confirm(true)).Return(nil),
+					t.mockAcknowledger.EXPECT().Ack(uint64(1), false).Return(nil),
+				)
+			},
+			expected: 1,
+			hasError: false,
+		},
+		{
+			name:  "Replay publish failed",
+			limit: 10,
+			fn: func() {
+				gomock.InOrder(
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(1, `{"type":1}`), true, nil),
+					t.mockChannel.EXPECT().PublishWithContext(context.Background(), "", "name", false, false, publishing).Return(errors.New("")),
+					t.mockAcknowledger.EXPECT().Nack(uint64(1), false, true).Return(nil),
+				)
+			},
+			expected: 0,
+			hasError: true,
+		},
+		{
+			name:  "Replay not confirmed",
+			limit: 10,
+			fn: func() {
+				gomock.InOrder(
+					t.mockChannel.EXPECT().Get("name.dlq", false).Return(t.delivery(1, `{"type":1}`), true, nil),
+					t.mockChannel.EXPECT().PublishWithContext(context.Background(), "", "name", false, false, publishing).Do(t.confirm(false)).Return(nil),
+					t.mockAcknowledger.EXPECT().Nack(uint64(1), false, true).Return(nil),
+				)
+			},
+			expected: 0,
+			hasError: true,
+		},
+		{
+			name:  "Replay get failed",
+			limit: 10,
+			fn: func() {
+				t.mockChannel.EXPECT().Get("name.dlq", false).Return(amqp.Delivery{}, false, errors.New(""))
+			},
+			expected: 0,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			n, err := t.svc.Replay(context.Background(), test.limit)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, n)
+		})
+	}
+}
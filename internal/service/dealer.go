@@ -3,96 +3,883 @@ package service
 import (
 	"context"
 	"dealer/internal/dao"
+	"dealer/internal/logger"
 	"dealer/internal/models"
 	"errors"
+	"math"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type DealerInterface interface {
+	Recover(context.Context) error
+	ProcessMessage(context.Context, *models.Message) error
 	ProcessOrder(context.Context, *models.Order) error
+	AmendOrder(context.Context, *models.Amendment) error
+	ChangePhase(context.Context, *models.MarketState) error
+	ResumeMarket(context.Context, *models.MarketState) error
+	HaltMarket(context.Context, *models.MarketState) error
+	CancelAll(context.Context, *models.MassCancel) error
 }
 
 type Dealer struct {
-	db               *gorm.DB
-	orderDAO         dao.OrderInterface
-	dealDAO          dao.DealInterface
-	buyBook          OrderBookInterface
-	sellBook         OrderBookInterface
-	lastTradingPrice float64
+	db              *gorm.DB
+	orderDAO        dao.OrderInterface
+	dealDAO         dao.DealInterface
+	amendmentDAO    dao.AmendmentInterface
+	cancellationDAO dao.CancellationInterface
+	candleDAO       dao.CandleInterface
+	marketStateDAO  dao.MarketStateInterface
+	ledger          LedgerInterface
+	fees            FeeCalculatorInterface
+	marketData      MarketDataInterface
+	depthLimit      int
+	markets         map[string]*market
+	lastSequence    int64
+	clock           func() time.Time
+}
+
+type market struct {
+	instrument        *models.Instrument
+	phase             models.TradingPhase
+	buyBook           OrderBookInterface
+	sellBook          OrderBookInterface
+	buyStopBook       StopBookInterface
+	sellStopBook      StopBookInterface
+	lastTradingPrice  models.Decimal
+	auctionPrice      models.Decimal
+	resumeAt          *time.Time
+	resumePhase       models.TradingPhase
+	sequence          int64
+	lastQueuePosition int64
 }
 
 var _ (DealerInterface) = (*Dealer)(nil)
 
-func NewDealer(db *gorm.DB, orderDAO dao.OrderInterface, dealDAO dao.DealInterface) *Dealer {
+func NewDealer(db *gorm.DB, orderDAO dao.OrderInterface, dealDAO dao.DealInterface, amendmentDAO dao.AmendmentInterface, cancellationDAO dao.CancellationInterface, candleDAO dao.CandleInterface, marketStateDAO dao.MarketStateInterface, ledger LedgerInterface, fees FeeCalculatorInterface, marketData MarketDataInterface, depthLimit int, registry InstrumentRegistryInterface) *Dealer {
+	markets := make(map[string]*market)
+	for _, instrument := range registry.List() {
+		markets[instrument.Symbol] = newMarket(instrument)
+	}
+
 	return &Dealer{
-		db:       db,
-		orderDAO: orderDAO,
-		dealDAO:  dealDAO,
-		buyBook:  NewOrderBook(BuyComparator),
-		sellBook: NewOrderBook(SellComparator),
+		db:              db,
+		orderDAO:        orderDAO,
+		dealDAO:         dealDAO,
+		amendmentDAO:    amendmentDAO,
+		cancellationDAO: cancellationDAO,
+		candleDAO:       candleDAO,
+		marketStateDAO:  marketStateDAO,
+		ledger:          ledger,
+		fees:            fees,
+		marketData:      marketData,
+		depthLimit:      depthLimit,
+		markets:         markets,
+		clock:           time.Now,
+	}
+}
+
+func newMarket(instrument *models.Instrument) *market {
+	return &market{
+		instrument:   instrument,
+		phase:        models.TradingPhaseContinuous,
+		buyBook:      NewPriceLevelOrderBook(BuyPriceComparator),
+		sellBook:     NewPriceLevelOrderBook(SellPriceComparator),
+		buyStopBook:  NewStopBook(BuyStopComparator),
+		sellStopBook: NewStopBook(SellStopComparator),
+	}
+}
+
+func (d *Dealer) Recover(ctx context.Context) error {
+	orders, err := d.orderDAO.ListOpen(ctx, d.db)
+	if err != nil {
+		return err
+	}
+
+	lastSequence, err := d.orderDAO.MaxSequence(ctx, d.db)
+	if err != nil {
+		return err
+	}
+
+	markets := make(map[string]*market, len(d.markets))
+	for symbol, current := range d.markets {
+		lastDeal, err := d.dealDAO.Last(ctx, d.db, symbol)
+		if err != nil {
+			return err
+		}
+
+		m := newMarket(current.instrument)
+		if lastDeal != nil {
+			m.lastTradingPrice = lastDeal.Price
+		}
+		markets[symbol] = m
+	}
+
+	states, err := d.marketStateDAO.List(ctx, d.db)
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		if m, ok := markets[state.Symbol]; ok {
+			m.phase = state.Phase
+			m.resumeAt = state.ResumeAt
+			m.resumePhase = state.ResumePhase
+			m.sequence = state.Sequence
+			m.auctionPrice = state.ReferencePrice
+		}
+	}
+
+	var unqueued []*models.Order
+	for _, order := range orders {
+		m, ok := markets[order.Symbol]
+		if !ok {
+			logger.GetLogger().Warnf("skip order %d of unknown symbol %q", order.ID, order.Symbol)
+			continue
+		}
+
+		if order.OrderType != models.OrderTypeBuy && order.OrderType != models.OrderTypeSell || order.Status.IsFinal() {
+			continue
+		}
+
+		if order.IsStop() && order.TriggeredAt == nil {
+			m.stopBook(order.OrderType).AddOrder(order)
+			continue
+		}
+
+		if order.Priority == 0 {
+			unqueued = append(unqueued, order)
+			continue
+		}
+
+		m.observeQueuePosition(order)
+		_, book := m.books(order.OrderType)
+		book.AddOrder(order)
 	}
+
+	// The orders without a priority join the back of the queues in the order
+	// they were placed.
+	for _, order := range unqueued {
+		m := markets[order.Symbol]
+		_, book := m.books(order.OrderType)
+		m.enqueue(order, book)
+	}
+
+	d.markets = markets
+	d.lastSequence = lastSequence
+	for symbol, m := range markets {
+		d.marketData.Publish(symbol, &models.MarketUpdate{Depth: m.depth(d.depthLimit), LastPrice: m.lastTradingPrice, Status: m.status()})
+	}
+
+	return nil
 }
 
+func (d *Dealer) ProcessMessage(ctx context.Context, message *models.Message) error {
+	switch message.Type {
+	case models.MessageTypeNewOrder, models.MessageTypeCancelOrder:
+		if message.Order == nil {
+			return ErrInvalidMessage
+		}
+		message.Order.Sequence = message.Sequence
+		return d.ProcessOrder(ctx, message.Order)
+	case models.MessageTypeAmendOrder:
+		if message.Amendment == nil {
+			return ErrInvalidMessage
+		}
+		message.Amendment.Sequence = message.Sequence
+		return d.AmendOrder(ctx, message.Amendment)
+	case models.MessageTypeChangePhase:
+		if message.Market == nil {
+			return ErrInvalidMessage
+		}
+		message.Market.Sequence = message.Sequence
+		return d.ChangePhase(ctx, message.Market)
+	case models.MessageTypeResumeMarket:
+		if message.Market == nil {
+			return ErrInvalidMessage
+		}
+		message.Market.Sequence = message.Sequence
+		return d.ResumeMarket(ctx, message.Market)
+	case models.MessageTypeHaltMarket:
+		if message.Market == nil {
+			return ErrInvalidMessage
+		}
+		message.Market.Sequence = message.Sequence
+		return d.HaltMarket(ctx, message.Market)
+	case models.MessageTypeCancelAll:
+		if message.MassCancel == nil {
+			return ErrInvalidMessage
+		}
+		message.MassCancel.Sequence = message.Sequence
+		return d.CancelAll(ctx, message.MassCancel)
+	default:
+		return ErrInvalidMessage
+	}
+}
+
+// ProcessOrder matches a new order or cancels an open one. A message with a
+// sequence not greater than the last one applied to the order is a redelivery
+// and is skipped.
 func (d *Dealer) ProcessOrder(ctx context.Context, order *models.Order) error {
+	m, ok := d.markets[order.Symbol]
 	if order.IsCancel {
-		d.buyBook.RemoveOrder(order.ID)
-		d.sellBook.RemoveOrder(order.ID)
+		if !ok {
+			return ErrUnknownSymbol
+		}
+		return d.cancelOrder(ctx, m, order.ID, order.Sequence)
+	}
+
+	applied, err := d.isApplied(ctx, m, order)
+	if err != nil {
+		return err
+	}
+	if applied {
+		logger.GetLogger().Infof("skip applied message %d of order %d", order.Sequence, order.ID)
+		return nil
+	}
+	if order.Sequence > d.lastSequence {
+		d.lastSequence = order.Sequence
+	}
+
+	if !ok {
+		return d.rejectOrder(ctx, order, ErrUnknownSymbol)
+	}
+
+	if order.OrderType != models.OrderTypeBuy && order.OrderType != models.OrderTypeSell {
+		return d.rejectOrder(ctx, order, ErrInvalidOrderType)
+	}
+
+	switch {
+	case m.phase == models.TradingPhaseClosed:
+		return d.rejectOrder(ctx, order, ErrMarketClosed)
+	case m.phase == models.TradingPhaseHalted:
+		return d.rejectOrder(ctx, order, ErrMarketHalted)
+	case m.phase != models.TradingPhaseContinuous && !isAuctionOrder(order):
+		return d.rejectOrder(ctx, order, ErrContinuousOnly)
+	case order.MatchPriceType() == models.PriceTypeLimit && !m.staticBand().contains(order.Price):
+		return d.rejectOrder(ctx, order, ErrOutsidePriceBand)
+	}
+
+	now := d.clock()
+	if err := d.fees.Refresh(ctx, now); err != nil {
+		return err
+	}
+
+	if order.IsStop() && order.TriggeredAt == nil {
+		if m.phase != models.TradingPhaseContinuous || !isStopTriggered(order, m.lastTradingPrice) {
+			m.stopBook(order.OrderType).AddOrder(order)
+			return d.recordDeal(ctx, &matchResult{orders: []*models.Order{order}})
+		}
+		order.TriggeredAt = &now
+	}
+
+	result := &matchResult{}
+	m.processOrder(order, result, now)
+	m.triggerStopOrders(result, now)
+	return d.recordDeal(ctx, result)
+}
+
+// isApplied reports whether the message of a new order has already been
+// applied. The relay publishes in the order of the sequences, so a sequence
+// above the last one applied is new and only a redelivery looks the order up.
+// The DB is read when the order is no longer in the books.
+func (d *Dealer) isApplied(ctx context.Context, m *market, order *models.Order) (bool, error) {
+	if order.Sequence == 0 || order.Sequence > d.lastSequence {
+		return false, nil
+	}
+
+	var applied *models.Order
+	if m != nil {
+		if applied, _ = m.findStopOrder(order.ID); applied == nil {
+			applied, _ = m.findOrder(order.ID)
+		}
+	}
+	if applied == nil {
+		var err error
+		if applied, err = d.orderDAO.Get(ctx, d.db, order.ID); err != nil {
+			return false, err
+		}
+	}
+
+	return applied != nil && applied.Applied(order.Sequence), nil
+}
+
+// cancelOrder cancels an order still resting in the books. An order that is
+// not in the books has already been filled or cancelled.
+func (d *Dealer) cancelOrder(ctx context.Context, m *market, orderID, sequence int64) error {
+	order, stopBook := m.findStopOrder(orderID)
+	var book OrderBookInterface
+	if order == nil {
+		order, book = m.findOrder(orderID)
+	}
+	if order == nil {
+		return ErrOrderClosed
+	}
+
+	if !order.Apply(sequence) {
+		logger.GetLogger().Infof("skip applied message %d of order %d", sequence, orderID)
+		return nil
+	}
+
+	now := d.clock()
+	reason := models.CancelReasonUser
+	if isExpired(order, now) {
+		reason = models.CancelReasonExpired
+	}
+
+	result := &matchResult{orders: []*models.Order{order}}
+	if err := result.cancel(order, reason, now); err != nil {
+		return err
+	}
+
+	if stopBook != nil {
+		stopBook.RemoveOrder(orderID)
+	} else {
+		book.RemoveOrder(orderID)
+	}
+
+	return d.recordDeal(ctx, result)
+}
+
+// rejectOrder records a new order the dealer can not process as rejected and
+// returns the reason.
+func (d *Dealer) rejectOrder(ctx context.Context, order *models.Order, reason error) error {
+	if err := order.SetStatus(models.OrderStatusRejected); err != nil {
+		return err
+	}
+
+	if err := d.recordDeal(ctx, &matchResult{orders: []*models.Order{order}}); err != nil {
+		return err
+	}
+
+	return reason
+}
+
+// AmendOrder changes the price or the total quantity of an open order. A
+// quantity decrease keeps the queue position, while a price change or a
+// quantity increase moves the order to the back of the queue and matches it
+// again as a taker. The difference of the funds the order locks is locked or
+// released with the amendment. An amendment the dealer can not apply is
+// recorded as rejected.
+func (d *Dealer) AmendOrder(ctx context.Context, amendment *models.Amendment) error {
+	m, ok := d.markets[amendment.Symbol]
+	if !ok {
+		return ErrUnknownSymbol
+	}
+
+	order, stopBook := m.findStopOrder(amendment.OrderID)
+	var book OrderBookInterface
+	if order == nil {
+		order, book = m.findOrder(amendment.OrderID)
+	}
+	if order == nil {
+		return ErrOrderNotFound
+	}
+
+	if order.Applied(amendment.Sequence) {
+		logger.GetLogger().Infof("skip applied message %d of order %d", amendment.Sequence, order.ID)
 		return nil
 	}
 
-	switch order.OrderType {
-	case models.OrderTypeBuy:
-		return d.processOrder(ctx, order, d.sellBook, d.buyBook)
-	case models.OrderTypeSell:
-		return d.processOrder(ctx, order, d.buyBook, d.sellBook)
+	filled := order.Quantity - order.RemainQuantity
+	if amendment.Quantity <= filled {
+		return d.rejectAmendment(ctx, order, amendment, ErrQuantityFilled)
+	}
+
+	if amendment.Price != order.Price && order.MatchPriceType() != models.PriceTypeLimit {
+		return d.rejectAmendment(ctx, order, amendment, ErrPriceNotAmendable)
+	}
+
+	if amendment.Price != order.Price && !m.staticBand().contains(amendment.Price) {
+		return d.rejectAmendment(ctx, order, amendment, ErrOutsidePriceBand)
+	}
+
+	// A halted market only takes the amendments that keep the queue position.
+	if m.phase == models.TradingPhaseHalted && (amendment.Price != order.Price || amendment.Quantity > order.Quantity) {
+		return d.rejectAmendment(ctx, order, amendment, ErrMarketHalted)
+	}
+
+	now := d.clock()
+	if err := d.fees.Refresh(ctx, now); err != nil {
+		return err
+	}
+
+	amended := *order
+	amended.Price = amendment.Price
+	amended.RemainQuantity = amendment.Quantity - filled
+	asset, before := reservation(m.instrument, order)
+	_, after := reservation(m.instrument, &amended)
+
+	result := &matchResult{amendments: []*models.Amendment{amendment}}
+	tx := d.db.Begin()
+	if after > before {
+		if err := d.ledger.Lock(ctx, tx, order.UserID, order.ID, asset, after-before); err != nil {
+			tx.Rollback()
+			if errors.Is(err, ErrInsufficientFunds) {
+				return d.rejectAmendment(ctx, order, amendment, err)
+			}
+			return err
+		}
+	} else if after < before {
+		result.releases = releaseEntries(order, asset, before-after)
+	}
+
+	order.Apply(amendment.Sequence)
+
+	amendment.OldPrice = order.Price
+	amendment.OldQuantity = order.Quantity
+	keepPriority := amendment.Price == order.Price && amendment.Quantity <= order.Quantity
+
+	switch {
+	case stopBook != nil:
+		stopBook.RemoveOrder(order.ID)
+		order.Price = amendment.Price
+		order.Quantity = amendment.Quantity
+		order.RemainQuantity = amendment.Quantity - filled
+		stopBook.AddOrder(order)
+		result.orders = append(result.orders, order)
+	case keepPriority:
+		order.Quantity = amendment.Quantity
+		order.RemainQuantity = amendment.Quantity - filled
+		result.orders = append(result.orders, order)
 	default:
-		return errors.New("invalid order type")
+		book.RemoveOrder(order.ID)
+		order.Price = amendment.Price
+		order.Quantity = amendment.Quantity
+		order.RemainQuantity = amendment.Quantity - filled
+		m.processOrder(order, result, now)
+		m.triggerStopOrders(result, now)
+	}
+
+	return d.record(ctx, tx, result)
+}
+
+// rejectAmendment records an amendment the dealer can not apply as rejected
+// with the order it leaves unchanged, and returns the reason.
+func (d *Dealer) rejectAmendment(ctx context.Context, order *models.Order, amendment *models.Amendment, reason error) error {
+	order.Apply(amendment.Sequence)
+	amendment.OldPrice = order.Price
+	amendment.OldQuantity = order.Quantity
+	amendment.RejectReason = reason.Error()
+	result := &matchResult{
+		orders:     []*models.Order{order},
+		amendments: []*models.Amendment{amendment},
+	}
+	if err := d.recordDeal(ctx, result); err != nil {
+		return err
+	}
+
+	return reason
+}
+
+// ChangePhase moves a market through the phases of the trading day until it
+// reaches the phase of the state. The orders collected in an auction are
+// uncrossed when the auction ends, and the stop orders are triggered once
+// continuous trading starts. A market halted by an operator stays halted and
+// resumes in the phase of the state.
+func (d *Dealer) ChangePhase(ctx context.Context, state *models.MarketState) error {
+	m, ok := d.markets[state.Symbol]
+	if !ok {
+		return ErrUnknownSymbol
+	}
+
+	if !state.Phase.IsValid() {
+		return ErrInvalidPhase
+	}
+
+	if !m.apply(state.Sequence) {
+		return nil
+	}
+
+	if m.isHaltedByOperator() {
+		if m.resumePhase == state.Phase {
+			return nil
+		}
+		m.resumePhase = state.Phase
+		return d.recordDeal(ctx, &matchResult{state: m.state(d.clock())})
+	}
+
+	if m.phase == state.Phase {
+		return nil
+	}
+
+	now := d.clock()
+	if err := d.fees.Refresh(ctx, now); err != nil {
+		return err
+	}
+
+	result := &matchResult{}
+	for m.phase != state.Phase {
+		if m.phase.IsAuction() {
+			m.endAuction(result, now)
+		}
+		m.phase = m.phase.Next()
+	}
+	m.resumeAt = nil
+	m.triggerStopOrders(result, now)
+
+	result.state = m.state(now)
+	return d.recordDeal(ctx, result)
+}
+
+// ResumeMarket returns a halted market to continuous trading, or to the
+// phase a market halted by an operator resumes in. The orders collected in a
+// volatility auction, or in an auction that ended during the halt, are
+// uncrossed when it resumes in a phase that follows an auction. A resume sent
+// for the cooldown of an earlier halt, or of a halt an operator took over, is
+// ignored.
+func (d *Dealer) ResumeMarket(ctx context.Context, state *models.MarketState) error {
+	m, ok := d.markets[state.Symbol]
+	if !ok {
+		return ErrUnknownSymbol
+	}
+
+	if !m.apply(state.Sequence) || !m.phase.IsHalt() {
+		return nil
+	}
+	if state.ResumeAt != nil && (m.resumeAt == nil || m.resumeAt.After(*state.ResumeAt)) {
+		return nil
+	}
+
+	now := d.clock()
+	if err := d.fees.Refresh(ctx, now); err != nil {
+		return err
+	}
+
+	phase := m.resumePhase
+	if phase == "" {
+		phase = models.TradingPhaseContinuous
+	}
+
+	result := &matchResult{}
+	if phase == models.TradingPhaseContinuous || phase == models.TradingPhaseClosed {
+		m.endAuction(result, now)
+	}
+	m.phase = phase
+	m.resumeAt = nil
+	m.resumePhase = ""
+	m.triggerStopOrders(result, now)
+
+	result.state = m.state(now)
+	return d.recordDeal(ctx, result)
+}
+
+// HaltMarket halts a market until an operator resumes it. The halted market
+// rejects new orders but still accepts cancels, and it resumes in the phase
+// it was halted in, or in continuous trading when a circuit breaker had
+// halted it.
+func (d *Dealer) HaltMarket(ctx context.Context, state *models.MarketState) error {
+	m, ok := d.markets[state.Symbol]
+	if !ok {
+		return ErrUnknownSymbol
+	}
+
+	if !m.apply(state.Sequence) || m.isHaltedByOperator() {
+		return nil
+	}
+
+	if !m.phase.IsHalt() {
+		m.resumePhase = m.phase
+	}
+	m.phase = models.TradingPhaseHalted
+	m.resumeAt = nil
+
+	return d.recordDeal(ctx, &matchResult{state: m.state(d.clock())})
+}
+
+// CancelAll cancels the resting orders of a market selected by an operator,
+// including the stop orders waiting for their trigger.
+func (d *Dealer) CancelAll(ctx context.Context, massCancel *models.MassCancel) error {
+	m, ok := d.markets[massCancel.Symbol]
+	if !ok {
+		return ErrUnknownSymbol
+	}
+
+	if massCancel.OrderType != 0 && massCancel.OrderType != models.OrderTypeBuy && massCancel.OrderType != models.OrderTypeSell {
+		return ErrInvalidOrderType
+	}
+
+	if !m.apply(massCancel.Sequence) {
+		return nil
+	}
+
+	selected := func(order *models.Order) bool {
+		return (massCancel.OrderType == 0 || order.OrderType == massCancel.OrderType) &&
+			(massCancel.UserID == 0 || order.UserID == massCancel.UserID)
+	}
+
+	books := []interface {
+		Range(func(*models.Order) bool)
+		RemoveOrder(int64)
+	}{m.buyBook, m.sellBook, m.buyStopBook, m.sellStopBook}
+
+	now := d.clock()
+	result := &matchResult{}
+	for _, book := range books {
+		var orders []*models.Order
+		book.Range(func(order *models.Order) bool {
+			if selected(order) {
+				orders = append(orders, order)
+			}
+			return true
+		})
+
+		for _, order := range orders {
+			book.RemoveOrder(order.ID)
+			result.cancel(order, models.CancelReasonAdmin, now)
+			result.orders = append(result.orders, order)
+		}
+	}
+
+	// The state records the sequence even when no order is cancelled, so a
+	// redelivery does not cancel the orders placed since.
+	if massCancel.Sequence != 0 {
+		result.state = m.state(now)
+	}
+	if len(result.orders) == 0 && result.state == nil {
+		return nil
+	}
+
+	return d.recordDeal(ctx, result)
+}
+
+type matchResult struct {
+	deals         []*models.Deal
+	orders        []*models.Order
+	amendments    []*models.Amendment
+	cancellations []*models.Cancellation
+	settlements   []*settlement
+	releases      []*models.LedgerEntry
+	state         *models.MarketState
+}
+
+// cancel cancels an open order and records its remain quantity as cancelled
+// for reason.
+func (r *matchResult) cancel(order *models.Order, reason models.CancelReason, now time.Time) error {
+	if err := order.Cancel(); err != nil {
+		return err
+	}
+
+	r.cancellations = append(r.cancellations, newCancellation(order, order.RemainQuantity, reason, now))
+	return nil
+}
+
+func newCancellation(order *models.Order, quantity uint, reason models.CancelReason, now time.Time) *models.Cancellation {
+	return &models.Cancellation{
+		OrderID:   order.ID,
+		Symbol:    order.Symbol,
+		UserID:    order.UserID,
+		Quantity:  quantity,
+		Reason:    reason,
+		CreatedAt: now,
 	}
 }
 
-func (d *Dealer) processOrder(ctx context.Context, takerOrder *models.Order, makerBook, takerBook OrderBookInterface) error {
-	var deals []*models.Deal
-	var updateOrders []*models.Order
+// settlement is the ledger entries of a deal, which are posted once the deal
+// has its ID. reserved is the quote the buyer locked for the deal.
+type settlement struct {
+	deal     *models.Deal
+	buyer    *models.Order
+	seller   *models.Order
+	reserved models.Decimal
+	entries  []*models.LedgerEntry
+}
+
+func (m *market) findOrder(orderID int64) (*models.Order, OrderBookInterface) {
+	for _, book := range []OrderBookInterface{m.buyBook, m.sellBook} {
+		if order := book.Get(orderID); order != nil {
+			return order, book
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *market) findStopOrder(orderID int64) (*models.Order, StopBookInterface) {
+	for _, book := range []StopBookInterface{m.buyStopBook, m.sellStopBook} {
+		if order := book.Get(orderID); order != nil {
+			return order, book
+		}
+	}
+
+	return nil, nil
+}
+
+// observeQueuePosition continues the queue positions of a recovered market
+// after the priorities of its resting orders, which are saved with the orders.
+func (m *market) observeQueuePosition(order *models.Order) {
+	if order.Priority > m.lastQueuePosition {
+		m.lastQueuePosition = order.Priority
+	}
+}
+
+// enqueue puts an order at the back of the queue of its price. Every order
+// entering a book takes the next queue position of the market, which is
+// independent of the order IDs.
+func (m *market) enqueue(order *models.Order, book OrderBookInterface) {
+	m.lastQueuePosition++
+	order.Priority = m.lastQueuePosition
+	book.AddOrder(order)
+}
+
+func (m *market) books(orderType models.OrderType) (makerBook, takerBook OrderBookInterface) {
+	if orderType == models.OrderTypeBuy {
+		return m.sellBook, m.buyBook
+	}
+
+	return m.buyBook, m.sellBook
+}
+
+// apply records the sequence of a market message applied to the market. It
+// reports false when the message has already been applied, while a message
+// without a sequence is always applied. The sequence is saved with the next
+// state of the market.
+func (m *market) apply(sequence int64) bool {
+	if sequence == 0 {
+		return true
+	}
+	if sequence <= m.sequence {
+		logger.GetLogger().Infof("skip applied message %d of market %s", sequence, m.instrument.Symbol)
+		return false
+	}
+
+	m.sequence = sequence
+	return true
+}
+
+// isHaltedByOperator reports whether the market is halted without a cooldown,
+// which only an operator resumes.
+func (m *market) isHaltedByOperator() bool {
+	return m.phase == models.TradingPhaseHalted && m.resumeAt == nil
+}
+
+// marketPrice is the price of the market orders in the book, which is the
+// last trading price, or the reference price of the instrument before the
+// first deal.
+func (m *market) marketPrice() models.Decimal {
+	if m.lastTradingPrice != 0 {
+		return m.lastTradingPrice
+	}
+
+	return m.instrument.ReferencePrice
+}
+
+func (m *market) depth(limit int) *models.Depth {
+	return &models.Depth{
+		Bids: m.buyBook.Depth(limit),
+		Asks: m.sellBook.Depth(limit),
+	}
+}
+
+func (m *market) stopBook(orderType models.OrderType) StopBookInterface {
+	if orderType == models.OrderTypeBuy {
+		return m.buyStopBook
+	}
+
+	return m.sellStopBook
+}
+
+func (m *market) processOrder(takerOrder *models.Order, result *matchResult, now time.Time) {
+	makerBook, takerBook := m.books(takerOrder.OrderType)
+	if isExpired(takerOrder, now) {
+		result.cancel(takerOrder, models.CancelReasonExpired, now)
+		result.orders = append(result.orders, takerOrder)
+		return
+	}
+	// Outside continuous trading the order waits in the book for an auction.
+	if m.phase != models.TradingPhaseContinuous {
+		result.orders = append(result.orders, takerOrder)
+		m.enqueue(takerOrder, takerBook)
+		return
+	}
+	if takerOrder.PostOnly != 0 && !m.postOnly(takerOrder, makerBook, result, now) {
+		result.orders = append(result.orders, takerOrder)
+		return
+	}
+	band := m.dynamicBand()
+	slippage := m.slippageBand(takerOrder, makerBook)
+	if takerOrder.TimeInForce == models.TimeInForceFOK && fillableQuantity(takerOrder, makerBook, m.marketPrice(), band.intersect(slippage)) < takerOrder.RemainQuantity {
+		result.cancel(takerOrder, models.CancelReasonFOK, now)
+		result.orders = append(result.orders, takerOrder)
+		return
+	}
+	if minQuantity := takerOrder.MinQuantity; minQuantity != 0 {
+		if minQuantity > takerOrder.RemainQuantity {
+			minQuantity = takerOrder.RemainQuantity
+		}
+		if fillableQuantity(takerOrder, makerBook, m.marketPrice(), band.intersect(slippage)) < minQuantity {
+			result.cancel(takerOrder, models.CancelReasonMinQuantity, now)
+			result.orders = append(result.orders, takerOrder)
+			return
+		}
+	}
+
+	var exhausted, slipped bool
 	for {
 		makerOrder := makerBook.Peek()
 		if makerOrder == nil {
 			break
 		}
 
-		var price float64
-		switch {
-		case makerOrder.PriceType == models.PriceTypeLimit:
-			price = makerOrder.Price
-		case makerOrder.PriceType == models.PriceTypeMarket:
-			price = d.lastTradingPrice
+		// A market maker has no price before the first deal of a market
+		// without a reference price.
+		price := makerPrice(makerOrder, m.marketPrice())
+		if price == 0 || !isPriceMatch(takerOrder, price) {
+			break
 		}
 
-		if !isPriceMatch(takerOrder, price) {
-			break
+		if isSelfTrade(takerOrder, makerOrder) {
+			if !m.preventSelfTrade(takerOrder, makerOrder, makerBook, result, now) {
+				break
+			}
+			continue
 		}
 
 		var quantity uint
-		if takerOrder.RemainQuantity > makerOrder.RemainQuantity {
-			quantity = makerOrder.RemainQuantity
+		if takerOrder.RemainQuantity > makerOrder.VisibleQuantity() {
+			quantity = makerOrder.VisibleQuantity()
 		} else {
 			quantity = takerOrder.RemainQuantity
 		}
 
-		d.lastTradingPrice = price
-		deal := &models.Deal{
-			TakerOrderID: takerOrder.ID,
-			MakerOrderID: makerOrder.ID,
-			Quantity:     quantity,
-			Price:        price,
+		buyer := takerOrder
+		if takerOrder.OrderType == models.OrderTypeSell {
+			buyer = makerOrder
+		}
+		if affordable := affordableQuantity(buyer, price); affordable < quantity {
+			quantity = affordable
 		}
-		deals = append(deals, deal)
+		// A market buy that can not pay for one more unit is done.
+		if quantity == 0 {
+			if buyer == takerOrder {
+				exhausted = true
+				break
+			}
 
-		takerOrder.RemainQuantity -= quantity
-		makerOrder.RemainQuantity -= quantity
-		updateOrders = append(updateOrders, makerOrder)
+			makerBook.Dequeue()
+			result.cancel(makerOrder, models.CancelReasonInsufficientFunds, now)
+			result.orders = append(result.orders, makerOrder)
+			continue
+		}
+
+		if !slippage.contains(price) {
+			slipped = true
+			break
+		}
+		// A deal out of the band of the price before the order halts the
+		// market, and the rest of the order waits for it to resume.
+		if !band.contains(price) {
+			m.trip(result, now)
+			break
+		}
+
+		sliceFilled := quantity == makerOrder.VisibleQuantity()
+		m.trade(takerOrder, makerOrder, quantity, price, result, now)
+		result.orders = append(result.orders, makerOrder)
 		if makerOrder.RemainQuantity == 0 {
 			makerBook.Dequeue()
+		} else if sliceFilled {
+			m.replenish(makerOrder, makerBook)
 		}
 
 		if takerOrder.RemainQuantity == 0 {
@@ -100,16 +887,295 @@ func (d *Dealer) processOrder(ctx context.Context, takerOrder *models.Order, mak
 		}
 	}
 
-	updateOrders = append(updateOrders, takerOrder)
-	if takerOrder.RemainQuantity > 0 {
-		takerBook.AddOrder(takerOrder)
+	result.orders = append(result.orders, takerOrder)
+	switch {
+	case takerOrder.RemainQuantity == 0 || takerOrder.IsCancel:
+	case exhausted:
+		result.cancel(takerOrder, models.CancelReasonInsufficientFunds, now)
+	case takerOrder.TimeInForce == models.TimeInForceIOC:
+		result.cancel(takerOrder, models.CancelReasonIOC, now)
+	case slipped:
+		result.cancel(takerOrder, models.CancelReasonSlippage, now)
+	// A market order only waits in the book for an auction.
+	case takerOrder.MatchPriceType() == models.PriceTypeMarket && !m.phase.IsAuction():
+		result.cancel(takerOrder, models.CancelReasonUnfilled, now)
+	default:
+		m.enqueue(takerOrder, takerBook)
+	}
+}
+
+// postOnly keeps a post-only order from taking liquidity. An order that would
+// cross the book is cancelled, or repriced one tick passive of the best maker
+// price when its mode allows. It reports whether the order can rest in the
+// book.
+func (m *market) postOnly(order *models.Order, makerBook OrderBookInterface, result *matchResult, now time.Time) bool {
+	makerOrder := makerBook.Peek()
+	if makerOrder == nil {
+		return true
+	}
+
+	price := makerPrice(makerOrder, m.marketPrice())
+	if !isPriceMatch(order, price) {
+		return true
+	}
+
+	if order.PostOnly == models.PostOnlyReprice && makerOrder.MatchPriceType() == models.PriceTypeLimit {
+		if passive := m.passivePrice(order.OrderType, price); passive > 0 {
+			asset, before := reservation(m.instrument, order)
+			order.Price = passive
+			if _, after := reservation(m.instrument, order); after < before {
+				result.releases = append(result.releases, releaseEntries(order, asset, before-after)...)
+			}
+			return true
+		}
+	}
+
+	result.cancel(order, models.CancelReasonPostOnly, now)
+	return false
+}
+
+// passivePrice is one tick from price on the side that does not cross it.
+func (m *market) passivePrice(orderType models.OrderType, price models.Decimal) models.Decimal {
+	tick := m.instrument.TickSize
+	if tick <= 0 {
+		tick = 1
+	}
+
+	if orderType == models.OrderTypeBuy {
+		return price - tick
 	}
 
-	return d.recordDeal(ctx, deals, updateOrders)
+	return price + tick
 }
 
-func isPriceMatch(takerOrder *models.Order, price float64) bool {
-	if takerOrder.PriceType == models.PriceTypeLimit {
+// preventSelfTrade applies the self-trade prevention of the taker to the maker
+// at the head of the book, which belongs to the same user. It reports whether
+// the taker goes on matching. Decrement and cancel takes the smaller remain
+// quantity off both orders and cancels the orders left with nothing, and an
+// order without a mode is treated as cancel newest.
+func (m *market) preventSelfTrade(takerOrder, makerOrder *models.Order, makerBook OrderBookInterface, result *matchResult, now time.Time) bool {
+	mode := takerOrder.SelfTradePrevention
+	if mode == models.SelfTradePreventionDecrementAndCancel {
+		switch {
+		case takerOrder.RemainQuantity < makerOrder.RemainQuantity:
+			m.decrement(makerOrder, takerOrder.RemainQuantity, result, now)
+			result.orders = append(result.orders, makerOrder)
+			mode = models.SelfTradePreventionCancelNewest
+		case takerOrder.RemainQuantity > makerOrder.RemainQuantity:
+			m.decrement(takerOrder, makerOrder.RemainQuantity, result, now)
+			mode = models.SelfTradePreventionCancelOldest
+		default:
+			mode = models.SelfTradePreventionCancelBoth
+		}
+	}
+
+	if mode == models.SelfTradePreventionCancelOldest || mode == models.SelfTradePreventionCancelBoth {
+		makerBook.Dequeue()
+		result.cancel(makerOrder, models.CancelReasonSelfTrade, now)
+		result.orders = append(result.orders, makerOrder)
+	}
+	if mode != models.SelfTradePreventionCancelOldest {
+		result.cancel(takerOrder, models.CancelReasonSelfTrade, now)
+		return false
+	}
+
+	return true
+}
+
+// decrement takes quantity off an open order without a deal, and releases the
+// funds the order no longer needs.
+func (m *market) decrement(order *models.Order, quantity uint, result *matchResult, now time.Time) {
+	asset, before := reservation(m.instrument, order)
+	order.Quantity -= quantity
+	order.RemainQuantity -= quantity
+	if _, after := reservation(m.instrument, order); after < before {
+		result.releases = append(result.releases, releaseEntries(order, asset, before-after)...)
+	}
+
+	result.cancellations = append(result.cancellations, newCancellation(order, quantity, models.CancelReasonSelfTrade, now))
+}
+
+// trade fills quantity of both orders at price, and records the deal with its
+// settlement. The fees are charged when the result is recorded.
+func (m *market) trade(takerOrder, makerOrder *models.Order, quantity uint, price models.Decimal, result *matchResult, now time.Time) {
+	buyer, seller := takerOrder, makerOrder
+	if takerOrder.OrderType == models.OrderTypeSell {
+		buyer, seller = makerOrder, takerOrder
+	}
+
+	m.lastTradingPrice = price
+	deal := &models.Deal{
+		Symbol:       takerOrder.Symbol,
+		TakerOrderID: takerOrder.ID,
+		MakerOrderID: makerOrder.ID,
+		TakerUserID:  takerOrder.UserID,
+		MakerUserID:  makerOrder.UserID,
+		Quantity:     quantity,
+		Price:        price,
+		CreatedAt:    now,
+	}
+	deal.TakerFeeAsset = m.feeAsset(takerOrder)
+	deal.MakerFeeAsset = m.feeAsset(makerOrder)
+	result.deals = append(result.deals, deal)
+
+	reserved := notional(price, quantity)
+	if buyer.MatchPriceType() == models.PriceTypeMarket {
+		buyer.Budget -= reserved
+	} else {
+		reserved = notional(buyer.Price, quantity)
+	}
+	result.settlements = append(result.settlements, &settlement{
+		deal:     deal,
+		buyer:    buyer,
+		seller:   seller,
+		reserved: reserved,
+	})
+
+	takerOrder.Fill(quantity)
+	makerOrder.Fill(quantity)
+}
+
+// replenish shows the next slice of an iceberg order whose slice has been
+// filled, at the back of the queue of its price.
+func (m *market) replenish(order *models.Order, book OrderBookInterface) {
+	book.RemoveOrder(order.ID)
+	m.enqueue(order, book)
+}
+
+// feeAsset is the asset an order pays its fees in, which is the asset it
+// receives.
+func (m *market) feeAsset(order *models.Order) string {
+	if order.OrderType == models.OrderTypeBuy {
+		return m.instrument.BaseAsset
+	}
+
+	return m.instrument.QuoteAsset
+}
+
+// fee returns the fee of an order in a deal at rate.
+func fee(order *models.Order, deal *models.Deal, rate models.Decimal) models.Decimal {
+	if order.OrderType == models.OrderTypeBuy {
+		return models.NewDecimalFromInt(int64(deal.Quantity)).MulRate(rate)
+	}
+
+	return notional(deal.Price, deal.Quantity).MulRate(rate)
+}
+
+// triggerStopOrders releases the stop orders reached by the last trading
+// price into the matching flow. Buy stops are released before sell stops and
+// each stop book releases in stop price then ID order, and the loop goes on
+// while the released orders keep moving the price.
+func (m *market) triggerStopOrders(result *matchResult, now time.Time) {
+	for m.phase == models.TradingPhaseContinuous && m.lastTradingPrice > 0 {
+		triggered := m.buyStopBook.Trigger(m.lastTradingPrice)
+		triggered = append(triggered, m.sellStopBook.Trigger(m.lastTradingPrice)...)
+		if len(triggered) == 0 {
+			return
+		}
+
+		for _, order := range triggered {
+			// The orders triggered after a halt wait for the market to resume.
+			if m.phase != models.TradingPhaseContinuous {
+				m.stopBook(order.OrderType).AddOrder(order)
+				continue
+			}
+			order.TriggeredAt = &now
+			m.processOrder(order, result, now)
+		}
+	}
+}
+
+// affordableQuantity is the quantity a market buy can pay for at price out of
+// its budget, other buys are not limited.
+func affordableQuantity(buyer *models.Order, price models.Decimal) uint {
+	if buyer.MatchPriceType() != models.PriceTypeMarket || price == 0 {
+		return math.MaxUint
+	}
+
+	return uint(buyer.Budget / price)
+}
+
+// fillableQuantity is the quantity the taker can fill in the maker book
+// within the band and the budget of the buyer. The orders of the same user
+// are not counted, and the count stops at them when the self-trade prevention
+// cancels the taker.
+func fillableQuantity(takerOrder *models.Order, makerBook OrderBookInterface, lastTradingPrice models.Decimal, band priceBand) uint {
+	// The budget of a market buy taker is spent on a copy.
+	taker := *takerOrder
+	var fillable uint
+	makerBook.Range(func(makerOrder *models.Order) bool {
+		price := makerPrice(makerOrder, lastTradingPrice)
+		if price == 0 || !isPriceMatch(takerOrder, price) || !band.contains(price) {
+			return false
+		}
+
+		if isSelfTrade(takerOrder, makerOrder) {
+			return takerOrder.SelfTradePrevention == models.SelfTradePreventionCancelOldest ||
+				takerOrder.SelfTradePrevention == models.SelfTradePreventionDecrementAndCancel
+		}
+
+		buyer := &taker
+		if takerOrder.OrderType == models.OrderTypeSell {
+			buyer = makerOrder
+		}
+		quantity := makerOrder.RemainQuantity
+		if affordable := affordableQuantity(buyer, price); affordable < quantity {
+			quantity = affordable
+		}
+		if buyer == &taker && taker.MatchPriceType() == models.PriceTypeMarket {
+			taker.Budget -= notional(price, quantity)
+		}
+
+		lastTradingPrice = price
+		fillable += quantity
+		// A taker that can not pay for the whole maker can not fill more.
+		return (buyer != &taker || quantity == makerOrder.RemainQuantity) && fillable < takerOrder.RemainQuantity
+	})
+
+	return fillable
+}
+
+// isSelfTrade reports whether the orders belong to the same user.
+func isSelfTrade(takerOrder, makerOrder *models.Order) bool {
+	return takerOrder.UserID == makerOrder.UserID
+}
+
+func makerPrice(makerOrder *models.Order, lastTradingPrice models.Decimal) models.Decimal {
+	if makerOrder.MatchPriceType() == models.PriceTypeMarket {
+		return lastTradingPrice
+	}
+
+	return makerOrder.Price
+}
+
+func isExpired(order *models.Order, now time.Time) bool {
+	return order.TimeInForce == models.TimeInForceGTD && order.ExpireAt != nil && !order.ExpireAt.After(now)
+}
+
+// isAuctionOrder reports whether an order can wait in the book for an
+// auction. The orders that must match or not match on arrival can not.
+func isAuctionOrder(order *models.Order) bool {
+	if order.TimeInForce == models.TimeInForceIOC || order.TimeInForce == models.TimeInForceFOK {
+		return false
+	}
+
+	return order.PostOnly == 0 && order.MinQuantity == 0
+}
+
+func isStopTriggered(order *models.Order, lastTradingPrice models.Decimal) bool {
+	if lastTradingPrice == 0 {
+		return false
+	}
+
+	if order.OrderType == models.OrderTypeBuy {
+		return lastTradingPrice >= order.StopPrice
+	}
+
+	return lastTradingPrice <= order.StopPrice
+}
+
+func isPriceMatch(takerOrder *models.Order, price models.Decimal) bool {
+	if takerOrder.MatchPriceType() == models.PriceTypeLimit {
 		switch {
 		case takerOrder.OrderType == models.OrderTypeBuy && takerOrder.Price < price:
 			return false
@@ -121,21 +1187,140 @@ func isPriceMatch(takerOrder *models.Order, price float64) bool {
 	return true
 }
 
-func (d *Dealer) recordDeal(ctx context.Context, deals []*models.Deal, orders []*models.Order) error {
-	tx := d.db.Begin()
-	if len(orders) != 0 {
-		if err := d.orderDAO.BulkUpdate(ctx, tx, orders); err != nil {
+func (d *Dealer) recordDeal(ctx context.Context, result *matchResult) error {
+	return d.record(ctx, d.db.Begin(), result)
+}
+
+// record stores a result in tx, settling its deals and releasing the funds
+// of the orders it has closed.
+func (d *Dealer) record(ctx context.Context, tx *gorm.DB, result *matchResult) error {
+	d.settle(result)
+	entries := append(result.releases, d.releaseClosed(result.orders)...)
+	if len(result.orders) != 0 {
+		if err := d.orderDAO.BulkUpdate(ctx, tx, result.orders); err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
-	if len(deals) != 0 {
-		if err := d.dealDAO.Insert(ctx, tx, deals); err != nil {
+	if len(result.deals) != 0 {
+		if err := d.dealDAO.Insert(ctx, tx, result.deals); err != nil {
 			tx.Rollback()
 			return err
 		}
+
+		if err := d.candleDAO.Upsert(ctx, tx, aggregateCandles(result.deals)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for _, s := range result.settlements {
+		for _, entry := range s.entries {
+			entry.DealID = s.deal.ID
+		}
+		entries = append(entries, s.entries...)
 	}
 
-	return tx.Commit().Error
+	if len(entries) != 0 {
+		if err := d.ledger.Post(ctx, tx, entries); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if len(result.amendments) != 0 {
+		if err := d.amendmentDAO.Insert(ctx, tx, result.amendments); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if len(result.cancellations) != 0 {
+		if err := d.cancellationDAO.Insert(ctx, tx, result.cancellations); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if result.state != nil {
+		if err := d.marketStateDAO.Upsert(ctx, tx, result.state); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	d.fees.Record(result.deals)
+	d.publish(result)
+	return nil
+}
+
+// settle charges the fees of the deals at the rates of the accounts, and
+// builds the ledger entries settling the deals.
+func (d *Dealer) settle(result *matchResult) {
+	for _, s := range result.settlements {
+		instrument := d.markets[s.deal.Symbol].instrument
+		taker, maker := s.buyer, s.seller
+		if s.seller.ID == s.deal.TakerOrderID {
+			taker, maker = s.seller, s.buyer
+		}
+
+		makerRate, _ := d.fees.Rates(s.deal.MakerUserID, instrument.QuoteAsset)
+		_, takerRate := d.fees.Rates(s.deal.TakerUserID, instrument.QuoteAsset)
+		s.deal.TakerFee = fee(taker, s.deal, takerRate)
+		s.deal.MakerFee = fee(maker, s.deal, makerRate)
+		s.entries = settlementEntries(instrument, s.deal, s.buyer, s.seller, s.reserved)
+	}
+}
+
+// releaseClosed returns the entries releasing the funds still locked for the
+// closed orders, and clears the budgets of the closed market buys. An order
+// of an unknown symbol has been refused before any funds were locked.
+func (d *Dealer) releaseClosed(orders []*models.Order) []*models.LedgerEntry {
+	var entries []*models.LedgerEntry
+	released := make(map[int64]bool)
+	for _, order := range orders {
+		if !order.Status.IsFinal() || released[order.ID] {
+			continue
+		}
+		released[order.ID] = true
+
+		m, ok := d.markets[order.Symbol]
+		if !ok {
+			continue
+		}
+
+		asset, amount := reservation(m.instrument, order)
+		order.Budget = 0
+		if amount > 0 {
+			entries = append(entries, releaseEntries(order, asset, amount)...)
+		}
+	}
+
+	return entries
+}
+
+// publish sends a committed result to the market data subscribers.
+func (d *Dealer) publish(result *matchResult) {
+	var symbol string
+	switch {
+	case result.state != nil:
+		symbol = result.state.Symbol
+	case len(result.orders) != 0:
+		symbol = result.orders[0].Symbol
+	default:
+		return
+	}
+
+	update := &models.MarketUpdate{Deals: result.deals, Orders: result.orders}
+	if m, ok := d.markets[symbol]; ok {
+		update.Depth = m.depth(d.depthLimit)
+		update.LastPrice = m.lastTradingPrice
+		update.Status = m.status()
+	}
+	d.marketData.Publish(symbol, update)
 }
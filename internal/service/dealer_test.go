@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	mockDAO "dealer/internal/mock/dao"
 	mockService "dealer/internal/mock/service"
@@ -19,15 +21,21 @@ import (
 
 type DealerTestSuite struct {
 	suite.Suite
-	ctrl         *gomock.Controller
-	db           *sql.DB
-	mockDB       sqlmock.Sqlmock
-	mockGormDB   *gorm.DB
-	mockBuyBook  *mockService.MockOrderBookInterface
-	mockSellBook *mockService.MockOrderBookInterface
-	mockOrderDAO *mockDAO.MockOrderInterface
-	mockDealDAO  *mockDAO.MockDealInterface
-	svc          *Dealer
+	ctrl          *gomock.Controller
+	db            *sql.DB
+	mockDB        sqlmock.Sqlmock
+	mockGormDB    *gorm.DB
+	mockBuyBook   *mockService.MockOrderBookInterface
+	mockSellBook  *mockService.MockOrderBookInterface
+	mockOrderDAO  *mockDAO.MockOrderInterface
+	mockDealDAO   *mockDAO.MockDealInterface
+	mockAmendDAO  *mockDAO.MockAmendmentInterface
+	mockCancelDAO *mockDAO.MockCancellationInterface
+	mockCandleDAO *mockDAO.MockCandleInterface
+	mockStateDAO  *mockDAO.MockMarketStateInterface
+	mockLedger    *mockService.MockLedgerInterface
+	mockMarket    *mockService.MockMarketDataInterface
+	svc           *Dealer
 }
 
 func (t *DealerTestSuite) SetupTest() {
@@ -55,12 +63,43 @@ func (t *DealerTestSuite) SetupTest() {
 	t.mockSellBook = mockService.NewMockOrderBookInterface(t.ctrl)
 	t.mockOrderDAO = mockDAO.NewMockOrderInterface(t.ctrl)
 	t.mockDealDAO = mockDAO.NewMockDealInterface(t.ctrl)
+	t.mockAmendDAO = mockDAO.NewMockAmendmentInterface(t.ctrl)
+	t.mockCancelDAO = mockDAO.NewMockCancellationInterface(t.ctrl)
+	t.mockCancelDAO.EXPECT().Insert(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	t.mockCandleDAO = mockDAO.NewMockCandleInterface(t.ctrl)
+	t.mockStateDAO = mockDAO.NewMockMarketStateInterface(t.ctrl)
+	t.mockLedger = mockService.NewMockLedgerInterface(t.ctrl)
+	t.mockLedger.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	t.mockLedger.EXPECT().Lock(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	t.mockMarket = mockService.NewMockMarketDataInterface(t.ctrl)
+	t.mockMarket.EXPECT().Publish(gomock.Any(), gomock.Any()).AnyTimes()
+	t.mockBuyBook.EXPECT().Depth(gomock.Any()).AnyTimes()
+	t.mockSellBook.EXPECT().Depth(gomock.Any()).AnyTimes()
 	t.svc = &Dealer{
-		db:       t.mockGormDB,
-		orderDAO: t.mockOrderDAO,
-		dealDAO:  t.mockDealDAO,
-		buyBook:  t.mockBuyBook,
-		sellBook: t.mockSellBook,
+		db:              t.mockGormDB,
+		orderDAO:        t.mockOrderDAO,
+		dealDAO:         t.mockDealDAO,
+		amendmentDAO:    t.mockAmendDAO,
+		cancellationDAO: t.mockCancelDAO,
+		candleDAO:       t.mockCandleDAO,
+		marketStateDAO:  t.mockStateDAO,
+		ledger:          t.mockLedger,
+		fees:            testFees,
+		marketData:      t.mockMarket,
+		depthLimit:      10,
+		clock: func() time.Time {
+			return testNow
+		},
+		markets: map[string]*market{
+			testSymbol: {
+				instrument:   testInstrument,
+				phase:        models.TradingPhaseContinuous,
+				buyBook:      t.mockBuyBook,
+				sellBook:     t.mockSellBook,
+				buyStopBook:  NewStopBook(BuyStopComparator),
+				sellStopBook: NewStopBook(SellStopComparator),
+			},
+		},
 	}
 }
 
@@ -69,52 +108,93 @@ func (t *DealerTestSuite) TearDownTest() {
 	t.db.Close()
 }
 
+const testSymbol = "BTCUSD"
+
+var testInstrument = &models.Instrument{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD"}
+
+var testFees = NewFeeCalculator(nil, nil, nil, &models.FeeSchedule{}, time.Hour)
+
+var testNow = time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+
 func TestDealerTestSuite(t *testing.T) {
 	suite.Run(t, new(DealerTestSuite))
 }
 
 func (t *DealerTestSuite) TestProcessOrder() {
+	expired := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
 	tests := []struct {
 		name     string
 		order    *models.Order
 		fn       func()
 		hasError bool
 	}{
+		{
+			name:  "Process order unknown symbol",
+			order: &models.Order{ID: 1, Symbol: "UNKNOWN", OrderType: models.OrderTypeBuy},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
+						{ID: 1, Symbol: "UNKNOWN", OrderType: models.OrderTypeBuy, Status: models.OrderStatusRejected},
+					}).
+					Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: true,
+		},
 		{
 			name:  "Process order cancel",
-			order: &models.Order{ID: 1, IsCancel: true},
+			order: &models.Order{ID: 1, Symbol: testSymbol, IsCancel: true},
 			fn: func() {
+				order := &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 1, Status: models.OrderStatusPartiallyFilled}
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(order)
 				t.mockBuyBook.EXPECT().RemoveOrder(int64(1))
-				t.mockSellBook.EXPECT().RemoveOrder(int64(1))
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
+						{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 1, Status: models.OrderStatusCancelled, IsCancel: true},
+					}).
+					Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+		},
+		{
+			name:  "Process order cancel after filled",
+			order: &models.Order{ID: 1, Symbol: testSymbol, IsCancel: true},
+			fn: func() {
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(nil)
+				t.mockSellBook.EXPECT().Get(int64(1)).Return(nil)
 			},
+			hasError: true,
 		},
 		{
 			name: "Process buy order on market price not fulfil",
 			order: &models.Order{
 				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
 				OrderType:      models.OrderTypeBuy,
 				Quantity:       1,
 				RemainQuantity: 1,
 				PriceType:      models.PriceTypeMarket,
+				Budget:         100,
+				Status:         models.OrderStatusNew,
 			},
 			fn: func() {
 				t.mockSellBook.EXPECT().Peek().Return(nil)
-				t.mockBuyBook.EXPECT().AddOrder(&models.Order{
-					ID:             1,
-					OrderType:      models.OrderTypeBuy,
-					Quantity:       1,
-					RemainQuantity: 1,
-					PriceType:      models.PriceTypeMarket,
-				})
 				t.mockDB.ExpectBegin()
 				t.mockOrderDAO.EXPECT().
 					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
 						{
 							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeBuy,
 							Quantity:       1,
 							RemainQuantity: 1,
 							PriceType:      models.PriceTypeMarket,
+							IsCancel:       true,
+							Status:         models.OrderStatusCancelled,
 						},
 					}).
 					Return(nil)
@@ -126,6 +206,8 @@ func (t *DealerTestSuite) TestProcessOrder() {
 			name: "Process buy order on limit price not fulfil price not match",
 			order: &models.Order{
 				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
 				OrderType:      models.OrderTypeBuy,
 				Quantity:       1,
 				RemainQuantity: 1,
@@ -135,6 +217,8 @@ func (t *DealerTestSuite) TestProcessOrder() {
 			fn: func() {
 				t.mockSellBook.EXPECT().Peek().Return(&models.Order{
 					ID:             1,
+					UserID:         11,
+					Symbol:         testSymbol,
 					OrderType:      models.OrderTypeSell,
 					Quantity:       1,
 					RemainQuantity: 1,
@@ -143,22 +227,28 @@ func (t *DealerTestSuite) TestProcessOrder() {
 				})
 				t.mockBuyBook.EXPECT().AddOrder(&models.Order{
 					ID:             1,
+					UserID:         11,
+					Symbol:         testSymbol,
 					OrderType:      models.OrderTypeBuy,
 					Quantity:       1,
 					RemainQuantity: 1,
 					PriceType:      models.PriceTypeLimit,
 					Price:          5,
+					Priority:       1,
 				})
 				t.mockDB.ExpectBegin()
 				t.mockOrderDAO.EXPECT().
 					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
 						{
 							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeBuy,
 							Quantity:       1,
 							RemainQuantity: 1,
 							PriceType:      models.PriceTypeLimit,
 							Price:          5,
+							Priority:       1,
 						},
 					}).
 					Return(nil)
@@ -170,16 +260,21 @@ func (t *DealerTestSuite) TestProcessOrder() {
 			name: "Process buy order on market price fulfil seller partial fulfil",
 			order: &models.Order{
 				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
 				OrderType:      models.OrderTypeBuy,
 				Quantity:       1,
 				RemainQuantity: 1,
 				PriceType:      models.PriceTypeMarket,
+				Budget:         100,
 			},
 			fn: func() {
 				t.mockSellBook.EXPECT().
 					Peek().
 					Return(&models.Order{
 						ID:             2,
+						UserID:         12,
+						Symbol:         testSymbol,
 						OrderType:      models.OrderTypeSell,
 						Quantity:       2,
 						RemainQuantity: 2,
@@ -191,30 +286,43 @@ func (t *DealerTestSuite) TestProcessOrder() {
 					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
 						{
 							ID:             2,
+							UserID:         12,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeSell,
 							Quantity:       2,
 							RemainQuantity: 1,
 							PriceType:      models.PriceTypeLimit,
 							Price:          10,
+							Status:         models.OrderStatusPartiallyFilled,
 						},
 						{
 							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeBuy,
 							Quantity:       1,
 							RemainQuantity: 0,
 							PriceType:      models.PriceTypeMarket,
+							Status:         models.OrderStatusFilled,
 						},
 					}).
 					Return(nil)
 				t.mockDealDAO.EXPECT().
 					Insert(context.Background(), gomock.Any(), []*models.Deal{
 						{
-							TakerOrderID: 1,
-							MakerOrderID: 2,
-							Quantity:     1,
-							Price:        10,
+							Symbol:        testSymbol,
+							TakerOrderID:  1,
+							MakerOrderID:  2,
+							TakerUserID:   11,
+							MakerUserID:   12,
+							Quantity:      1,
+							Price:         10,
+							TakerFeeAsset: "BTC",
+							MakerFeeAsset: "USD",
+							CreatedAt:     testNow,
 						},
 					})
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
 				t.mockDB.ExpectCommit()
 			},
 			hasError: false,
@@ -223,16 +331,21 @@ func (t *DealerTestSuite) TestProcessOrder() {
 			name: "Process buy order on market price fulfil seller fulfil",
 			order: &models.Order{
 				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
 				OrderType:      models.OrderTypeBuy,
 				Quantity:       1,
 				RemainQuantity: 1,
 				PriceType:      models.PriceTypeMarket,
+				Budget:         100,
 			},
 			fn: func() {
 				t.mockSellBook.EXPECT().
 					Peek().
 					Return(&models.Order{
 						ID:             2,
+						UserID:         12,
+						Symbol:         testSymbol,
 						OrderType:      models.OrderTypeSell,
 						Quantity:       2,
 						RemainQuantity: 1,
@@ -242,6 +355,8 @@ func (t *DealerTestSuite) TestProcessOrder() {
 				t.mockSellBook.EXPECT().Dequeue().Return(
 					&models.Order{
 						ID:             2,
+						UserID:         12,
+						Symbol:         testSymbol,
 						OrderType:      models.OrderTypeSell,
 						Quantity:       2,
 						RemainQuantity: 0,
@@ -253,30 +368,43 @@ func (t *DealerTestSuite) TestProcessOrder() {
 					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
 						{
 							ID:             2,
+							UserID:         12,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeSell,
 							Quantity:       2,
 							RemainQuantity: 0,
 							PriceType:      models.PriceTypeLimit,
 							Price:          10,
+							Status:         models.OrderStatusFilled,
 						},
 						{
 							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeBuy,
 							Quantity:       1,
 							RemainQuantity: 0,
 							PriceType:      models.PriceTypeMarket,
+							Status:         models.OrderStatusFilled,
 						},
 					}).
 					Return(nil)
 				t.mockDealDAO.EXPECT().
 					Insert(context.Background(), gomock.Any(), []*models.Deal{
 						{
-							TakerOrderID: 1,
-							MakerOrderID: 2,
-							Quantity:     1,
-							Price:        10,
+							Symbol:        testSymbol,
+							TakerOrderID:  1,
+							MakerOrderID:  2,
+							TakerUserID:   11,
+							MakerUserID:   12,
+							Quantity:      1,
+							Price:         10,
+							TakerFeeAsset: "BTC",
+							MakerFeeAsset: "USD",
+							CreatedAt:     testNow,
 						},
 					})
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
 				t.mockDB.ExpectCommit()
 			},
 			hasError: false,
@@ -285,17 +413,22 @@ func (t *DealerTestSuite) TestProcessOrder() {
 			name: "Process buy order on market price fulfil seller market price partial fulfil",
 			order: &models.Order{
 				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
 				OrderType:      models.OrderTypeBuy,
 				Quantity:       1,
 				RemainQuantity: 1,
 				PriceType:      models.PriceTypeMarket,
+				Budget:         100,
 			},
 			fn: func() {
-				t.svc.lastTradingPrice = 20
+				t.svc.markets[testSymbol].lastTradingPrice = 20
 				t.mockSellBook.EXPECT().
 					Peek().
 					Return(&models.Order{
 						ID:             2,
+						UserID:         12,
+						Symbol:         testSymbol,
 						OrderType:      models.OrderTypeSell,
 						Quantity:       2,
 						RemainQuantity: 2,
@@ -306,29 +439,250 @@ func (t *DealerTestSuite) TestProcessOrder() {
 					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
 						{
 							ID:             2,
+							UserID:         12,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeSell,
 							Quantity:       2,
 							RemainQuantity: 1,
 							PriceType:      models.PriceTypeMarket,
+							Status:         models.OrderStatusPartiallyFilled,
 						},
 						{
 							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
 							OrderType:      models.OrderTypeBuy,
 							Quantity:       1,
 							RemainQuantity: 0,
 							PriceType:      models.PriceTypeMarket,
+							Status:         models.OrderStatusFilled,
+						},
+					}).
+					Return(nil)
+				t.mockDealDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), []*models.Deal{
+						{
+							Symbol:        testSymbol,
+							TakerOrderID:  1,
+							MakerOrderID:  2,
+							TakerUserID:   11,
+							MakerUserID:   12,
+							Quantity:      1,
+							Price:         20,
+							TakerFeeAsset: "BTC",
+							MakerFeeAsset: "USD",
+							CreatedAt:     testNow,
+						},
+					})
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Process buy order immediate or cancel remainder cancelled",
+			order: &models.Order{
+				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
+				OrderType:      models.OrderTypeBuy,
+				Quantity:       1,
+				RemainQuantity: 1,
+				PriceType:      models.PriceTypeLimit,
+				Price:          5,
+				TimeInForce:    models.TimeInForceIOC,
+			},
+			fn: func() {
+				t.mockSellBook.EXPECT().Peek().Return(&models.Order{
+					ID:             2,
+					UserID:         12,
+					Symbol:         testSymbol,
+					OrderType:      models.OrderTypeSell,
+					Quantity:       1,
+					RemainQuantity: 1,
+					PriceType:      models.PriceTypeLimit,
+					Price:          10,
+				})
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
+						{
+							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
+							OrderType:      models.OrderTypeBuy,
+							Quantity:       1,
+							RemainQuantity: 1,
+							PriceType:      models.PriceTypeLimit,
+							Price:          5,
+							IsCancel:       true,
+							TimeInForce:    models.TimeInForceIOC,
+							Status:         models.OrderStatusCancelled,
+						},
+					}).
+					Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Process buy order fill or kill killed",
+			order: &models.Order{
+				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
+				OrderType:      models.OrderTypeBuy,
+				Quantity:       2,
+				RemainQuantity: 2,
+				PriceType:      models.PriceTypeLimit,
+				Price:          10,
+				TimeInForce:    models.TimeInForceFOK,
+			},
+			fn: func() {
+				t.mockSellBook.EXPECT().Range(gomock.Any()).Do(func(fn func(*models.Order) bool) {
+					orders := []*models.Order{
+						{ID: 2, OrderType: models.OrderTypeSell, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 10},
+						{ID: 3, OrderType: models.OrderTypeSell, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 11},
+					}
+					for _, order := range orders {
+						if !fn(order) {
+							return
+						}
+					}
+				})
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
+						{
+							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
+							OrderType:      models.OrderTypeBuy,
+							Quantity:       2,
+							RemainQuantity: 2,
+							PriceType:      models.PriceTypeLimit,
+							Price:          10,
+							IsCancel:       true,
+							TimeInForce:    models.TimeInForceFOK,
+							Status:         models.OrderStatusCancelled,
+						},
+					}).
+					Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Process buy order fill or kill fulfil",
+			order: &models.Order{
+				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
+				OrderType:      models.OrderTypeBuy,
+				Quantity:       1,
+				RemainQuantity: 1,
+				PriceType:      models.PriceTypeLimit,
+				Price:          10,
+				TimeInForce:    models.TimeInForceFOK,
+			},
+			fn: func() {
+				makerOrder := &models.Order{
+					ID:             2,
+					UserID:         12,
+					Symbol:         testSymbol,
+					OrderType:      models.OrderTypeSell,
+					Quantity:       1,
+					RemainQuantity: 1,
+					PriceType:      models.PriceTypeLimit,
+					Price:          10,
+				}
+				t.mockSellBook.EXPECT().Range(gomock.Any()).Do(func(fn func(*models.Order) bool) {
+					fn(makerOrder)
+				})
+				t.mockSellBook.EXPECT().Peek().Return(makerOrder)
+				t.mockSellBook.EXPECT().Dequeue().Return(makerOrder)
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
+						{
+							ID:             2,
+							UserID:         12,
+							Symbol:         testSymbol,
+							OrderType:      models.OrderTypeSell,
+							Quantity:       1,
+							RemainQuantity: 0,
+							PriceType:      models.PriceTypeLimit,
+							Price:          10,
+							Status:         models.OrderStatusFilled,
+						},
+						{
+							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
+							OrderType:      models.OrderTypeBuy,
+							Quantity:       1,
+							RemainQuantity: 0,
+							PriceType:      models.PriceTypeLimit,
+							Price:          10,
+							TimeInForce:    models.TimeInForceFOK,
+							Status:         models.OrderStatusFilled,
 						},
 					}).
 					Return(nil)
 				t.mockDealDAO.EXPECT().
 					Insert(context.Background(), gomock.Any(), []*models.Deal{
 						{
-							TakerOrderID: 1,
-							MakerOrderID: 2,
-							Quantity:     1,
-							Price:        20,
+							Symbol:        testSymbol,
+							TakerOrderID:  1,
+							MakerOrderID:  2,
+							TakerUserID:   11,
+							MakerUserID:   12,
+							Quantity:      1,
+							Price:         10,
+							TakerFeeAsset: "BTC",
+							MakerFeeAsset: "USD",
+							CreatedAt:     testNow,
 						},
 					})
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "Process buy order good til date expired",
+			order: &models.Order{
+				ID:             1,
+				UserID:         11,
+				Symbol:         testSymbol,
+				OrderType:      models.OrderTypeBuy,
+				Quantity:       1,
+				RemainQuantity: 1,
+				PriceType:      models.PriceTypeLimit,
+				Price:          10,
+				TimeInForce:    models.TimeInForceGTD,
+				ExpireAt:       &expired,
+			},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					BulkUpdate(context.Background(), gomock.Any(), []*models.Order{
+						{
+							ID:             1,
+							UserID:         11,
+							Symbol:         testSymbol,
+							OrderType:      models.OrderTypeBuy,
+							Quantity:       1,
+							RemainQuantity: 1,
+							PriceType:      models.PriceTypeLimit,
+							Price:          10,
+							IsCancel:       true,
+							TimeInForce:    models.TimeInForceGTD,
+							ExpireAt:       &expired,
+							Status:         models.OrderStatusCancelled,
+						},
+					}).
+					Return(nil)
 				t.mockDB.ExpectCommit()
 			},
 			hasError: false,
@@ -348,7 +702,7 @@ func (t *DealerTestSuite) TestIsPriceMatch() {
 	tests := []struct {
 		name       string
 		takerOrder *models.Order
-		price      float64
+		price      models.Decimal
 		expected   bool
 	}{
 		{
@@ -436,3 +790,1556 @@ func (t *DealerTestSuite) TestIsPriceMatch() {
 		t.Equal(test.expected, actual)
 	}
 }
+
+func (t *DealerTestSuite) TestRecover() {
+	triggeredAt := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		fn               func()
+		buyOrders        []int64
+		sellOrders       []int64
+		buyStopOrders    []int64
+		lastTradingPrice models.Decimal
+		phase            models.TradingPhase
+		sequence         int64
+		lastSequence     int64
+		referencePrice   models.Decimal
+		hasError         bool
+	}{
+		{
+			name: "Recover order books",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListOpen(context.Background(), t.mockGormDB).
+					Return([]*models.Order{
+						{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 9},
+						{ID: 2, Symbol: testSymbol, OrderType: models.OrderTypeSell, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 11},
+						{ID: 3, Symbol: testSymbol, OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 10},
+						{ID: 4, Symbol: testSymbol, OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 9},
+						{ID: 5, Symbol: "UNKNOWN", OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 9},
+						{ID: 6, Symbol: testSymbol, OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 12},
+						{ID: 7, Symbol: testSymbol, OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeStopLimit, Price: 8, StopPrice: 10, TriggeredAt: &triggeredAt},
+						{ID: 8, Symbol: testSymbol, OrderType: models.OrderTypeSell, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 12, Status: models.OrderStatusRejected},
+						{ID: 9, Symbol: testSymbol, OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 10, Priority: 20},
+					}, nil)
+				t.mockOrderDAO.EXPECT().
+					MaxSequence(context.Background(), t.mockGormDB).
+					Return(int64(12), nil)
+				t.mockDealDAO.EXPECT().
+					Last(context.Background(), t.mockGormDB, testSymbol).
+					Return(&models.Deal{ID: 1, Symbol: testSymbol, Price: 10}, nil)
+				t.mockStateDAO.EXPECT().
+					List(context.Background(), t.mockGormDB).
+					Return([]*models.MarketState{
+						{Symbol: testSymbol, Phase: models.TradingPhaseAuction, Sequence: 9, ReferencePrice: 12},
+						{Symbol: "UNKNOWN", Phase: models.TradingPhaseClosed},
+					}, nil)
+			},
+			buyOrders:        []int64{9, 3, 1, 4, 7},
+			sellOrders:       []int64{2},
+			buyStopOrders:    []int64{6},
+			lastTradingPrice: 10,
+			phase:            models.TradingPhaseAuction,
+			sequence:         9,
+			lastSequence:     12,
+			referencePrice:   12,
+			hasError:         false,
+		},
+		{
+			name: "Recover without deal",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListOpen(context.Background(), t.mockGormDB).
+					Return(nil, nil)
+				t.mockOrderDAO.EXPECT().
+					MaxSequence(context.Background(), t.mockGormDB).
+					Return(int64(0), nil)
+				t.mockDealDAO.EXPECT().
+					Last(context.Background(), t.mockGormDB, testSymbol).
+					Return(nil, nil)
+				t.mockStateDAO.EXPECT().
+					List(context.Background(), t.mockGormDB).
+					Return(nil, nil)
+			},
+			lastTradingPrice: 0,
+			phase:            models.TradingPhaseContinuous,
+			referencePrice:   testInstrument.ReferencePrice,
+			hasError:         false,
+		},
+		{
+			name: "Recover list orders failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListOpen(context.Background(), t.mockGormDB).
+					Return(nil, errors.New(""))
+			},
+			hasError: true,
+		},
+		{
+			name: "Recover max sequence failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListOpen(context.Background(), t.mockGormDB).
+					Return(nil, nil)
+				t.mockOrderDAO.EXPECT().
+					MaxSequence(context.Background(), t.mockGormDB).
+					Return(int64(0), errors.New(""))
+			},
+			hasError: true,
+		},
+		{
+			name: "Recover last deal failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListOpen(context.Background(), t.mockGormDB).
+					Return(nil, nil)
+				t.mockOrderDAO.EXPECT().
+					MaxSequence(context.Background(), t.mockGormDB).
+					Return(int64(0), nil)
+				t.mockDealDAO.EXPECT().
+					Last(context.Background(), t.mockGormDB, testSymbol).
+					Return(nil, errors.New(""))
+			},
+			hasError: true,
+		},
+		{
+			name: "Recover market states failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListOpen(context.Background(), t.mockGormDB).
+					Return(nil, nil)
+				t.mockOrderDAO.EXPECT().
+					MaxSequence(context.Background(), t.mockGormDB).
+					Return(int64(0), nil)
+				t.mockDealDAO.EXPECT().
+					Last(context.Background(), t.mockGormDB, testSymbol).
+					Return(nil, nil)
+				t.mockStateDAO.EXPECT().
+					List(context.Background(), t.mockGormDB).
+					Return(nil, errors.New(""))
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.Recover(context.Background())
+			t.Equal(test.hasError, err != nil)
+			if test.hasError {
+				return
+			}
+
+			m := t.svc.markets[testSymbol]
+			t.Equal(test.buyOrders, drain(m.buyBook))
+			t.Equal(test.sellOrders, drain(m.sellBook))
+			t.Equal(test.buyStopOrders, stopOrderIDs(m.buyStopBook))
+			t.Equal(test.lastTradingPrice, m.lastTradingPrice)
+			t.Equal(test.phase, m.phase)
+			t.Equal(test.sequence, m.sequence)
+			t.Equal(test.lastSequence, t.svc.lastSequence)
+			t.Equal(test.referencePrice, m.referencePrice())
+		})
+	}
+}
+
+func stopOrderIDs(book StopBookInterface) []int64 {
+	var ids []int64
+	book.Range(func(order *models.Order) bool {
+		ids = append(ids, order.ID)
+		return true
+	})
+
+	return ids
+}
+
+func (t *DealerTestSuite) TestProcessStopOrder() {
+	tests := []struct {
+		name          string
+		orders        []*models.Order
+		fn            func()
+		deals         []*models.Deal
+		buyStopOrders []int64
+	}{
+		{
+			name: "Process stop order not triggered",
+			orders: []*models.Order{
+				{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 11, Budget: 100},
+			},
+			fn: func() {
+				t.expectRecordDeal()
+			},
+			buyStopOrders: []int64{1},
+		},
+		{
+			name: "Process stop order triggered on arrival",
+			orders: []*models.Order{
+				{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 12},
+				{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopLimit, Price: 12, StopPrice: 10},
+			},
+			fn: func() {
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+			},
+			deals: []*models.Deal{
+				{Symbol: testSymbol, TakerOrderID: 2, MakerOrderID: 1, TakerUserID: 12, MakerUserID: 11, Quantity: 1, Price: 12, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+			},
+		},
+		{
+			name: "Process stop order triggered by deal",
+			orders: []*models.Order{
+				{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 11},
+				{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 13},
+				{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 14, Budget: 100},
+				{ID: 4, UserID: 14, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 11, Budget: 100},
+				{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 11},
+			},
+			fn: func() {
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+			},
+			deals: []*models.Deal{
+				{Symbol: testSymbol, TakerOrderID: 5, MakerOrderID: 1, TakerUserID: 15, MakerUserID: 11, Quantity: 1, Price: 11, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+				{Symbol: testSymbol, TakerOrderID: 4, MakerOrderID: 2, TakerUserID: 14, MakerUserID: 12, Quantity: 1, Price: 13, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+			},
+			buyStopOrders: []int64{3},
+		},
+		{
+			name: "Process stop order cascade",
+			orders: []*models.Order{
+				{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 11},
+				{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 12},
+				{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 13},
+				{ID: 4, UserID: 14, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 12, Budget: 100},
+				{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 11, Budget: 100},
+				{ID: 6, UserID: 16, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 11},
+			},
+			fn: func() {
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+			},
+			deals: []*models.Deal{
+				{Symbol: testSymbol, TakerOrderID: 6, MakerOrderID: 1, TakerUserID: 16, MakerUserID: 11, Quantity: 1, Price: 11, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+				{Symbol: testSymbol, TakerOrderID: 5, MakerOrderID: 2, TakerUserID: 15, MakerUserID: 12, Quantity: 1, Price: 12, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+				{Symbol: testSymbol, TakerOrderID: 4, MakerOrderID: 3, TakerUserID: 14, MakerUserID: 13, Quantity: 1, Price: 13, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+			},
+		},
+		{
+			name: "Process stop order cancel",
+			orders: []*models.Order{
+				{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: 11, Budget: 100},
+				{ID: 1, Symbol: testSymbol, IsCancel: true},
+			},
+			fn: func() {
+				t.expectRecordDeal()
+				t.expectRecordDeal()
+			},
+		},
+	}
+
+	var deals []*models.Deal
+	t.mockDealDAO.EXPECT().
+		Insert(context.Background(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *gorm.DB, d []*models.Deal) error {
+			deals = append(deals, d...)
+			return nil
+		}).
+		AnyTimes()
+	t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(testInstrument)
+			m.lastTradingPrice = 10
+			t.svc.markets[testSymbol] = m
+			deals = nil
+			test.fn()
+
+			for _, order := range test.orders {
+				t.NoError(t.svc.ProcessOrder(context.Background(), order))
+			}
+			t.Equal(test.deals, deals)
+			t.Equal(test.buyStopOrders, stopOrderIDs(m.buyStopBook))
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *DealerTestSuite) expectRecordDeal() {
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDB.ExpectCommit()
+}
+
+func (t *DealerTestSuite) TestProcessMessage() {
+	errSQL := errors.New("")
+	tests := []struct {
+		name     string
+		message  *models.Message
+		fn       func()
+		expected error
+	}{
+		{
+			name:    "Process message cancel order",
+			message: &models.Message{Type: models.MessageTypeCancelOrder, Order: &models.Order{ID: 1, Symbol: testSymbol, IsCancel: true}},
+			fn: func() {
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(nil)
+				t.mockSellBook.EXPECT().Get(int64(1)).Return(nil)
+			},
+			expected: ErrOrderClosed,
+		},
+		{
+			name:    "Process message redelivered new order",
+			message: &models.Message{Sequence: 5, Type: models.MessageTypeNewOrder, Order: &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy}},
+			fn: func() {
+				t.svc.lastSequence = 7
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(&models.Order{ID: 1, Sequence: 5})
+			},
+			expected: nil,
+		},
+		{
+			name:    "Process message redelivered new order already closed",
+			message: &models.Message{Sequence: 5, Type: models.MessageTypeNewOrder, Order: &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy}},
+			fn: func() {
+				t.svc.lastSequence = 7
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(nil)
+				t.mockSellBook.EXPECT().Get(int64(1)).Return(nil)
+				t.mockOrderDAO.EXPECT().Get(context.Background(), t.mockGormDB, int64(1)).Return(&models.Order{ID: 1, Sequence: 7}, nil)
+			},
+			expected: nil,
+		},
+		{
+			name:    "Process message redelivered cancel order",
+			message: &models.Message{Sequence: 6, Type: models.MessageTypeCancelOrder, Order: &models.Order{ID: 1, Symbol: testSymbol, IsCancel: true}},
+			fn: func() {
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(&models.Order{ID: 1, Sequence: 6})
+			},
+			expected: nil,
+		},
+		{
+			name:    "Process message redelivered amend order",
+			message: &models.Message{Sequence: 6, Type: models.MessageTypeAmendOrder, Amendment: &models.Amendment{OrderID: 1, Symbol: testSymbol, Quantity: 1}},
+			fn: func() {
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(&models.Order{ID: 1, Sequence: 6})
+			},
+			expected: nil,
+		},
+		{
+			name:    "Process message get applied order failed",
+			message: &models.Message{Sequence: 5, Type: models.MessageTypeNewOrder, Order: &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy}},
+			fn: func() {
+				t.svc.lastSequence = 7
+				t.mockBuyBook.EXPECT().Get(int64(1)).Return(nil)
+				t.mockSellBook.EXPECT().Get(int64(1)).Return(nil)
+				t.mockOrderDAO.EXPECT().Get(context.Background(), t.mockGormDB, int64(1)).Return(nil, errSQL)
+			},
+			expected: errSQL,
+		},
+		{
+			name:    "Process message new order above last sequence",
+			message: &models.Message{Sequence: 8, Type: models.MessageTypeNewOrder, Order: &models.Order{ID: 1, Symbol: "UNKNOWN", OrderType: models.OrderTypeBuy}},
+			fn: func() {
+				t.svc.lastSequence = 7
+				t.expectRecordDeal()
+			},
+			expected: ErrUnknownSymbol,
+		},
+		{
+			name:     "Process message amend unknown symbol",
+			message:  &models.Message{Type: models.MessageTypeAmendOrder, Amendment: &models.Amendment{OrderID: 1, Symbol: "UNKNOWN"}},
+			fn:       func() {},
+			expected: ErrUnknownSymbol,
+		},
+		{
+			name:     "Process message without order",
+			message:  &models.Message{Type: models.MessageTypeNewOrder},
+			fn:       func() {},
+			expected: ErrInvalidMessage,
+		},
+		{
+			name:     "Process message halt without market",
+			message:  &models.Message{Type: models.MessageTypeHaltMarket},
+			fn:       func() {},
+			expected: ErrInvalidMessage,
+		},
+		{
+			name:     "Process message cancel all unknown symbol",
+			message:  &models.Message{Type: models.MessageTypeCancelAll, MassCancel: &models.MassCancel{Symbol: "UNKNOWN"}},
+			fn:       func() {},
+			expected: ErrUnknownSymbol,
+		},
+		{
+			name:     "Process message unknown type",
+			message:  &models.Message{},
+			fn:       func() {},
+			expected: ErrInvalidMessage,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			t.svc.lastSequence = 0
+			test.fn()
+			err := t.svc.ProcessMessage(context.Background(), test.message)
+			t.Equal(test.expected, err)
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestAmendOrder() {
+	tests := []struct {
+		name       string
+		amendment  *models.Amendment
+		fn         func()
+		expected   error
+		buyOrders  []int64
+		sellOrders []int64
+		deals      int
+	}{
+		{
+			name:      "Amend order quantity decrease keeps priority",
+			amendment: &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: 10, Quantity: 3},
+			fn: func() {
+				t.expectRecordDeal()
+				t.mockAmendDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), []*models.Amendment{
+						{OrderID: 1, Symbol: testSymbol, OldPrice: 10, Price: 10, OldQuantity: 5, Quantity: 3},
+					}).
+					Return(nil)
+			},
+			buyOrders:  []int64{1, 2},
+			sellOrders: []int64{3},
+		},
+		{
+			name:      "Amend order quantity increase loses priority",
+			amendment: &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: 10, Quantity: 6},
+			fn: func() {
+				t.expectRecordDeal()
+				t.mockAmendDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			buyOrders:  []int64{2, 1},
+			sellOrders: []int64{3},
+		},
+		{
+			name:      "Amend order price matches immediately",
+			amendment: &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: 11, Quantity: 5},
+			fn: func() {
+				t.expectRecordDeal()
+				t.mockDealDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), []*models.Deal{
+						{Symbol: testSymbol, TakerOrderID: 1, MakerOrderID: 3, TakerUserID: 11, MakerUserID: 13, Quantity: 5, Price: 11, TakerFeeAsset: "BTC", MakerFeeAsset: "USD", CreatedAt: testNow},
+					}).
+					Return(nil)
+				t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+				t.mockAmendDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			buyOrders: []int64{2},
+			deals:     1,
+		},
+		{
+			name:       "Amend order not found",
+			amendment:  &models.Amendment{OrderID: 4, Symbol: testSymbol, Price: 10, Quantity: 3},
+			fn:         func() {},
+			expected:   ErrOrderNotFound,
+			buyOrders:  []int64{1, 2},
+			sellOrders: []int64{3},
+		},
+		{
+			name:      "Amend order quantity below filled",
+			amendment: &models.Amendment{OrderID: 2, Symbol: testSymbol, Price: 10, Quantity: 1},
+			fn: func() {
+				t.expectRecordDeal()
+				t.mockAmendDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), []*models.Amendment{
+						{OrderID: 2, Symbol: testSymbol, OldPrice: 10, Price: 10, OldQuantity: 5, Quantity: 1, RejectReason: ErrQuantityFilled.Error()},
+					}).
+					Return(nil)
+			},
+			expected:   ErrQuantityFilled,
+			buyOrders:  []int64{1, 2},
+			sellOrders: []int64{3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(testInstrument)
+			m.lastTradingPrice = 10
+			m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 5, RemainQuantity: 5, PriceType: models.PriceTypeLimit, Price: 10})
+			m.buyBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 5, RemainQuantity: 4, PriceType: models.PriceTypeLimit, Price: 10})
+			m.sellBook.AddOrder(&models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 5, RemainQuantity: 5, PriceType: models.PriceTypeLimit, Price: 11})
+			m.lastQueuePosition = 3
+			t.svc.markets[testSymbol] = m
+			test.fn()
+
+			err := t.svc.AmendOrder(context.Background(), test.amendment)
+			t.Equal(test.expected, err)
+			t.Equal(test.buyOrders, drain(m.buyBook))
+			t.Equal(test.sellOrders, drain(m.sellBook))
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestSettlement() {
+	ledger := mockService.NewMockLedgerInterface(t.ctrl)
+	t.svc.ledger = ledger
+	m := newMarket(testInstrument)
+	m.sellBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 10})
+	m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: 12})
+	t.svc.markets[testSymbol] = m
+
+	order := &models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeMarket, Budget: 25}
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDealDAO.EXPECT().
+		Insert(context.Background(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *gorm.DB, deals []*models.Deal) error {
+			for i, deal := range deals {
+				deal.ID = int64(i + 1)
+			}
+			return nil
+		})
+	t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	ledger.EXPECT().
+		Post(context.Background(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *gorm.DB, entries []*models.LedgerEntry) error {
+			t.Equal([]*models.LedgerEntry{
+				{UserID: 13, Asset: "USD", Bucket: models.BalanceLocked, Amount: -3, Reason: models.LedgerReasonRelease, OrderID: 3},
+				{UserID: 13, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 3, Reason: models.LedgerReasonRelease, OrderID: 3},
+				{UserID: 13, Asset: "USD", Bucket: models.BalanceLocked, Amount: -10, Reason: models.LedgerReasonTrade, OrderID: 3, DealID: 1},
+				{UserID: 13, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 0, Reason: models.LedgerReasonTrade, OrderID: 3, DealID: 1},
+				{UserID: 11, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 10, Reason: models.LedgerReasonTrade, OrderID: 1, DealID: 1},
+				{UserID: 11, Asset: "BTC", Bucket: models.BalanceLocked, Amount: -models.DecimalScale, Reason: models.LedgerReasonTrade, OrderID: 1, DealID: 1},
+				{UserID: 13, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: models.DecimalScale, Reason: models.LedgerReasonTrade, OrderID: 3, DealID: 1},
+				{UserID: 13, Asset: "USD", Bucket: models.BalanceLocked, Amount: -12, Reason: models.LedgerReasonTrade, OrderID: 3, DealID: 2},
+				{UserID: 13, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 0, Reason: models.LedgerReasonTrade, OrderID: 3, DealID: 2},
+				{UserID: 12, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 12, Reason: models.LedgerReasonTrade, OrderID: 2, DealID: 2},
+				{UserID: 12, Asset: "BTC", Bucket: models.BalanceLocked, Amount: -models.DecimalScale, Reason: models.LedgerReasonTrade, OrderID: 2, DealID: 2},
+				{UserID: 13, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: models.DecimalScale, Reason: models.LedgerReasonTrade, OrderID: 3, DealID: 2},
+			}, entries)
+			return nil
+		})
+	t.mockDB.ExpectCommit()
+
+	t.NoError(t.svc.ProcessOrder(context.Background(), order))
+	t.Equal(models.OrderStatusCancelled, order.Status)
+	t.Equal(uint(1), order.RemainQuantity)
+	t.Equal(models.Decimal(0), order.Budget)
+	t.Equal([]int64{2}, drain(m.sellBook))
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestAmendOrderRejectedRecordsSequence() {
+	order := &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 5, RemainQuantity: 4, PriceType: models.PriceTypeLimit, Price: 10, Sequence: 3}
+	m := newMarket(testInstrument)
+	m.buyBook.AddOrder(order)
+	t.svc.markets[testSymbol] = m
+
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().
+		BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *gorm.DB, orders []*models.Order) error {
+			t.Equal(int64(4), orders[0].Sequence)
+			return nil
+		})
+	t.mockAmendDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDB.ExpectCommit()
+
+	amendment := &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: 10, Quantity: 1, Sequence: 4}
+	t.ErrorIs(t.svc.AmendOrder(context.Background(), amendment), ErrQuantityFilled)
+	t.NoError(t.svc.AmendOrder(context.Background(), amendment))
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestAmendOrderInsufficientFunds() {
+	ledger := mockService.NewMockLedgerInterface(t.ctrl)
+	t.svc.ledger = ledger
+	m := newMarket(testInstrument)
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 5, RemainQuantity: 5, PriceType: models.PriceTypeLimit, Price: 10})
+	t.svc.markets[testSymbol] = m
+
+	t.mockDB.ExpectBegin()
+	ledger.EXPECT().
+		Lock(context.Background(), gomock.Any(), int64(11), int64(1), "USD", models.Decimal(30)).
+		Return(ErrInsufficientFunds)
+	t.mockDB.ExpectRollback()
+	t.expectRecordDeal()
+	t.mockAmendDAO.EXPECT().
+		Insert(context.Background(), gomock.Any(), []*models.Amendment{
+			{OrderID: 1, Symbol: testSymbol, OldPrice: 10, Price: 10, OldQuantity: 5, Quantity: 8, RejectReason: ErrInsufficientFunds.Error(), Sequence: 4},
+		}).
+		Return(nil)
+
+	err := t.svc.AmendOrder(context.Background(), &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: 10, Quantity: 8, Sequence: 4})
+	t.Equal(ErrInsufficientFunds, err)
+	order, _ := m.findOrder(1)
+	t.Equal(uint(5), order.Quantity)
+	t.Equal(int64(4), order.Sequence)
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestDealFees() {
+	fees := NewFeeCalculator(nil, nil, nil, &models.FeeSchedule{MakerRate: 100000, TakerRate: 200000}, time.Hour)
+	t.svc.fees = fees
+	m := newMarket(testInstrument)
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100)})
+	t.svc.markets[testSymbol] = m
+
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDealDAO.EXPECT().
+		Insert(context.Background(), gomock.Any(), []*models.Deal{
+			{
+				Symbol:        testSymbol,
+				TakerOrderID:  2,
+				MakerOrderID:  1,
+				TakerUserID:   12,
+				MakerUserID:   11,
+				Quantity:      2,
+				Price:         models.NewDecimalFromInt(100),
+				TakerFee:      40000000,
+				TakerFeeAsset: "USD",
+				MakerFee:      200000,
+				MakerFeeAsset: "BTC",
+				CreatedAt:     testNow,
+			},
+		}).
+		Return(nil)
+	t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDB.ExpectCommit()
+
+	order := &models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100)}
+	t.NoError(t.svc.ProcessOrder(context.Background(), order))
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestSelfTradePrevention() {
+	price := models.NewDecimalFromInt(100)
+	tests := []struct {
+		name                  string
+		mode                  models.SelfTradePrevention
+		takerQuantity         uint
+		expectedCancellations []*models.Cancellation
+		expectedDeals         []uint
+		expectedTakerStatus   models.OrderStatus
+		expectedTakerQuantity uint
+		expectedMakerQuantity uint
+		expectedMakerInBook   bool
+	}{
+		{
+			name:          "Cancel newest",
+			mode:          models.SelfTradePreventionCancelNewest,
+			takerQuantity: 4,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 2, Symbol: testSymbol, UserID: 11, Quantity: 4, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+			},
+			expectedTakerStatus:   models.OrderStatusCancelled,
+			expectedTakerQuantity: 4,
+			expectedMakerQuantity: 2,
+			expectedMakerInBook:   true,
+		},
+		{
+			name:          "Unset mode cancels newest",
+			takerQuantity: 4,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 2, Symbol: testSymbol, UserID: 11, Quantity: 4, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+			},
+			expectedTakerStatus:   models.OrderStatusCancelled,
+			expectedTakerQuantity: 4,
+			expectedMakerQuantity: 2,
+			expectedMakerInBook:   true,
+		},
+		{
+			name:          "Cancel oldest",
+			mode:          models.SelfTradePreventionCancelOldest,
+			takerQuantity: 4,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 1, Symbol: testSymbol, UserID: 11, Quantity: 2, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+			},
+			expectedDeals:         []uint{3},
+			expectedTakerStatus:   models.OrderStatusPartiallyFilled,
+			expectedTakerQuantity: 4,
+			expectedMakerQuantity: 2,
+		},
+		{
+			name:          "Cancel both",
+			mode:          models.SelfTradePreventionCancelBoth,
+			takerQuantity: 4,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 1, Symbol: testSymbol, UserID: 11, Quantity: 2, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+				{OrderID: 2, Symbol: testSymbol, UserID: 11, Quantity: 4, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+			},
+			expectedTakerStatus:   models.OrderStatusCancelled,
+			expectedTakerQuantity: 4,
+			expectedMakerQuantity: 2,
+		},
+		{
+			name:          "Decrement and cancel the maker",
+			mode:          models.SelfTradePreventionDecrementAndCancel,
+			takerQuantity: 4,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 2, Symbol: testSymbol, UserID: 11, Quantity: 2, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+				{OrderID: 1, Symbol: testSymbol, UserID: 11, Quantity: 2, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+			},
+			expectedDeals:         []uint{2},
+			expectedTakerStatus:   models.OrderStatusFilled,
+			expectedTakerQuantity: 2,
+			expectedMakerQuantity: 2,
+		},
+		{
+			name:          "Decrement and cancel the taker",
+			mode:          models.SelfTradePreventionDecrementAndCancel,
+			takerQuantity: 1,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 1, Symbol: testSymbol, UserID: 11, Quantity: 1, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+				{OrderID: 2, Symbol: testSymbol, UserID: 11, Quantity: 1, Reason: models.CancelReasonSelfTrade, CreatedAt: testNow},
+			},
+			expectedTakerStatus:   models.OrderStatusCancelled,
+			expectedTakerQuantity: 1,
+			expectedMakerQuantity: 1,
+			expectedMakerInBook:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(testInstrument)
+			maker := &models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew}
+			m.sellBook.AddOrder(maker)
+			m.sellBook.AddOrder(&models.Order{ID: 3, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+
+			taker := &models.Order{ID: 2, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: test.takerQuantity, RemainQuantity: test.takerQuantity, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew, TimeInForce: models.TimeInForceGTC, SelfTradePrevention: test.mode}
+			result := &matchResult{}
+			m.processOrder(taker, result, testNow)
+
+			t.Equal(test.expectedCancellations, result.cancellations)
+			var deals []uint
+			for _, deal := range result.deals {
+				t.NotEqual(taker.UserID, deal.MakerUserID)
+				deals = append(deals, deal.Quantity)
+			}
+			t.Equal(test.expectedDeals, deals)
+			t.Equal(test.expectedTakerStatus, taker.Status)
+			t.Equal(test.expectedTakerQuantity, taker.Quantity)
+			t.Equal(test.expectedMakerQuantity, maker.Quantity)
+			t.Equal(test.expectedMakerInBook, m.sellBook.Get(maker.ID) != nil)
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestSelfTradePreventionReleasesDecrement() {
+	price := models.NewDecimalFromInt(100)
+	m := newMarket(testInstrument)
+	m.sellBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+
+	taker := &models.Order{ID: 2, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 5, RemainQuantity: 5, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew, TimeInForce: models.TimeInForceGTC, SelfTradePrevention: models.SelfTradePreventionDecrementAndCancel}
+	result := &matchResult{}
+	m.processOrder(taker, result, testNow)
+
+	t.Equal(releaseEntries(taker, "USD", notional(price, 3)), result.releases)
+	t.Equal(uint(2), taker.RemainQuantity)
+	t.NotNil(m.buyBook.Get(taker.ID))
+}
+
+func (t *DealerTestSuite) TestIcebergOrder() {
+	price := models.NewDecimalFromInt(100)
+	m := newMarket(testInstrument)
+	m.lastQueuePosition = 3
+	iceberg := &models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 10, RemainQuantity: 10, DisplayQuantity: 4, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew}
+	m.sellBook.AddOrder(iceberg)
+	m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+	t.Equal([]*models.PriceLevel{{Price: price, Quantity: 7, Count: 2}}, m.depth(10).Asks)
+
+	taker := &models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 6, RemainQuantity: 6, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew, TimeInForce: models.TimeInForceGTC}
+	result := &matchResult{}
+	m.processOrder(taker, result, testNow)
+
+	var deals [][2]int64
+	for _, deal := range result.deals {
+		deals = append(deals, [2]int64{deal.MakerOrderID, int64(deal.Quantity)})
+	}
+	t.Equal([][2]int64{{1, 4}, {2, 2}}, deals)
+	t.Equal(int64(4), iceberg.Priority)
+	t.Equal(uint(6), iceberg.RemainQuantity)
+	t.Equal([]*models.PriceLevel{{Price: price, Quantity: 5, Count: 2}}, m.depth(10).Asks)
+	t.Equal(int64(2), m.sellBook.Peek().ID)
+
+	// An order placed before the replenishment but processed after it still
+	// joins the queue behind the iceberg.
+	late := &models.Order{ID: 3, UserID: 14, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew, TimeInForce: models.TimeInForceGTC}
+	m.processOrder(late, &matchResult{}, testNow)
+	t.Equal(int64(5), late.Priority)
+	t.Equal([]int64{2, 1, 3}, drain(m.sellBook))
+}
+
+func (t *DealerTestSuite) TestPostOnly() {
+	price := models.NewDecimalFromInt(100)
+	tick := models.NewDecimalFromInt(1)
+	instrument := &models.Instrument{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD", TickSize: tick}
+	tests := []struct {
+		name                  string
+		postOnly              models.PostOnly
+		price                 models.Decimal
+		expectedStatus        models.OrderStatus
+		expectedPrice         models.Decimal
+		expectedCancellations []*models.Cancellation
+		expectedReleases      []*models.LedgerEntry
+	}{
+		{
+			name:           "Not crossing",
+			postOnly:       models.PostOnlyReject,
+			price:          price - tick,
+			expectedStatus: models.OrderStatusNew,
+			expectedPrice:  price - tick,
+		},
+		{
+			name:           "Reject crossing",
+			postOnly:       models.PostOnlyReject,
+			price:          price + tick,
+			expectedStatus: models.OrderStatusCancelled,
+			expectedPrice:  price + tick,
+			expectedCancellations: []*models.Cancellation{
+				{OrderID: 2, Symbol: testSymbol, UserID: 12, Quantity: 3, Reason: models.CancelReasonPostOnly, CreatedAt: testNow},
+			},
+		},
+		{
+			name:             "Reprice crossing",
+			postOnly:         models.PostOnlyReprice,
+			price:            price + tick,
+			expectedStatus:   models.OrderStatusNew,
+			expectedPrice:    price - tick,
+			expectedReleases: releaseEntries(&models.Order{ID: 2, UserID: 12}, "USD", notional(tick+tick, 3)),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(instrument)
+			m.sellBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+
+			order := &models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: test.price, Status: models.OrderStatusNew, TimeInForce: models.TimeInForceGTC, PostOnly: test.postOnly}
+			result := &matchResult{}
+			m.processOrder(order, result, testNow)
+
+			t.Empty(result.deals)
+			t.Equal(test.expectedStatus, order.Status)
+			t.Equal(test.expectedPrice, order.Price)
+			t.Equal(test.expectedCancellations, result.cancellations)
+			t.Equal(test.expectedReleases, result.releases)
+			t.Equal(test.expectedStatus == models.OrderStatusNew, m.buyBook.Get(order.ID) != nil)
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestMinQuantity() {
+	price := models.NewDecimalFromInt(100)
+	tests := []struct {
+		name           string
+		minQuantity    uint
+		expectedDeals  int
+		expectedStatus models.OrderStatus
+		expectedInBook bool
+	}{
+		{name: "Enough to fill", minQuantity: 2, expectedDeals: 1, expectedStatus: models.OrderStatusPartiallyFilled, expectedInBook: true},
+		{name: "Not enough to fill", minQuantity: 3, expectedStatus: models.OrderStatusCancelled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(testInstrument)
+			m.sellBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+
+			order := &models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 5, RemainQuantity: 5, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew, TimeInForce: models.TimeInForceGTC, MinQuantity: test.minQuantity}
+			result := &matchResult{}
+			m.processOrder(order, result, testNow)
+
+			t.Len(result.deals, test.expectedDeals)
+			t.Equal(test.expectedStatus, order.Status)
+			t.Equal(test.expectedInBook, m.buyBook.Get(order.ID) != nil)
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestFillableWithinBudget() {
+	price := models.NewDecimalFromInt(100)
+	tests := []struct {
+		name           string
+		timeInForce    models.TimeInForce
+		minQuantity    uint
+		budget         models.Decimal
+		expectedDeals  int
+		expectedStatus models.OrderStatus
+		expectedReason models.CancelReason
+	}{
+		{name: "FOK within budget", timeInForce: models.TimeInForceFOK, budget: models.NewDecimalFromInt(200), expectedDeals: 2, expectedStatus: models.OrderStatusFilled},
+		{name: "FOK over budget", timeInForce: models.TimeInForceFOK, budget: models.NewDecimalFromInt(150), expectedStatus: models.OrderStatusCancelled, expectedReason: models.CancelReasonFOK},
+		{name: "Min quantity over budget", timeInForce: models.TimeInForceIOC, minQuantity: 2, budget: models.NewDecimalFromInt(150), expectedStatus: models.OrderStatusCancelled, expectedReason: models.CancelReasonMinQuantity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(testInstrument)
+			m.sellBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+			m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew})
+
+			order := &models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeMarket, Budget: test.budget, Status: models.OrderStatusNew, TimeInForce: test.timeInForce, MinQuantity: test.minQuantity}
+			result := &matchResult{}
+			m.processOrder(order, result, testNow)
+
+			t.Len(result.deals, test.expectedDeals)
+			t.Equal(test.expectedStatus, order.Status)
+			if test.expectedReason != "" {
+				t.Len(result.cancellations, 1)
+				t.Equal(test.expectedReason, result.cancellations[0].Reason)
+			}
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestEquilibrium() {
+	limit := func(id int64, orderType models.OrderType, quantity uint, price int64) *models.Order {
+		return &models.Order{ID: id, UserID: id + 10, Symbol: testSymbol, OrderType: orderType, Quantity: quantity, RemainQuantity: quantity, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(price)}
+	}
+	market := func(id int64, orderType models.OrderType, quantity uint) *models.Order {
+		return &models.Order{ID: id, UserID: id + 10, Symbol: testSymbol, OrderType: orderType, Quantity: quantity, RemainQuantity: quantity, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000)}
+	}
+
+	tests := []struct {
+		name             string
+		orders           []*models.Order
+		lastTradingPrice models.Decimal
+		expectedPrice    models.Decimal
+		expectedVolume   uint
+	}{
+		{
+			name:          "No orders",
+			expectedPrice: 0,
+		},
+		{
+			name:          "Books not crossing",
+			orders:        []*models.Order{limit(1, models.OrderTypeBuy, 2, 99), limit(2, models.OrderTypeSell, 2, 100)},
+			expectedPrice: 0,
+		},
+		{
+			name: "Maximum volume",
+			orders: []*models.Order{
+				limit(1, models.OrderTypeBuy, 3, 102), limit(2, models.OrderTypeBuy, 2, 100),
+				limit(3, models.OrderTypeSell, 2, 99), limit(4, models.OrderTypeSell, 1, 101),
+			},
+			expectedPrice:  models.NewDecimalFromInt(101),
+			expectedVolume: 3,
+		},
+		{
+			name: "Minimum surplus",
+			orders: []*models.Order{
+				limit(1, models.OrderTypeBuy, 2, 102), limit(2, models.OrderTypeBuy, 2, 100),
+				limit(3, models.OrderTypeSell, 2, 100), limit(4, models.OrderTypeSell, 3, 101),
+			},
+			expectedPrice:  models.NewDecimalFromInt(100),
+			expectedVolume: 2,
+		},
+		{
+			name:           "Buy pressure",
+			orders:         []*models.Order{limit(1, models.OrderTypeBuy, 5, 102), limit(2, models.OrderTypeSell, 3, 100)},
+			expectedPrice:  models.NewDecimalFromInt(102),
+			expectedVolume: 3,
+		},
+		{
+			name:           "Sell pressure",
+			orders:         []*models.Order{limit(1, models.OrderTypeBuy, 3, 102), limit(2, models.OrderTypeSell, 5, 100)},
+			expectedPrice:  models.NewDecimalFromInt(100),
+			expectedVolume: 3,
+		},
+		{
+			name:             "Closest to last trading price",
+			orders:           []*models.Order{limit(1, models.OrderTypeBuy, 2, 102), limit(2, models.OrderTypeSell, 2, 100)},
+			lastTradingPrice: models.NewDecimalFromInt(103),
+			expectedPrice:    models.NewDecimalFromInt(102),
+			expectedVolume:   2,
+		},
+		{
+			name:             "Lower price when equally close to last trading price",
+			orders:           []*models.Order{limit(1, models.OrderTypeBuy, 2, 102), limit(2, models.OrderTypeSell, 2, 100)},
+			lastTradingPrice: models.NewDecimalFromInt(101),
+			expectedPrice:    models.NewDecimalFromInt(100),
+			expectedVolume:   2,
+		},
+		{
+			name:             "Market orders only",
+			orders:           []*models.Order{market(1, models.OrderTypeBuy, 2), market(2, models.OrderTypeSell, 3)},
+			lastTradingPrice: models.NewDecimalFromInt(100),
+			expectedPrice:    models.NewDecimalFromInt(100),
+			expectedVolume:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newMarket(testInstrument)
+			m.phase = models.TradingPhaseAuction
+			m.lastTradingPrice = test.lastTradingPrice
+			for _, order := range test.orders {
+				_, book := m.books(order.OrderType)
+				book.AddOrder(order)
+			}
+
+			price, volume := m.equilibrium()
+			t.Equal(test.expectedPrice, price)
+			t.Equal(test.expectedVolume, volume)
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestUncross() {
+	m := newMarket(testInstrument)
+	m.phase = models.TradingPhaseAuction
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(102)})
+	m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100)})
+	m.buyBook.AddOrder(&models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(101)})
+
+	result := &matchResult{}
+	m.uncross(result, testNow)
+
+	price := models.NewDecimalFromInt(101)
+	t.Require().Len(result.deals, 2)
+	t.Equal([]int64{2, 1}, []int64{result.deals[0].TakerOrderID, result.deals[0].MakerOrderID})
+	t.Equal([]int64{3, 2}, []int64{result.deals[1].TakerOrderID, result.deals[1].MakerOrderID})
+	for _, deal := range result.deals {
+		t.Equal(price, deal.Price)
+	}
+	t.Equal(uint(2), result.deals[0].Quantity)
+	t.Equal(uint(1), result.deals[1].Quantity)
+	t.Equal(price, m.lastTradingPrice)
+	t.Equal([]int64{3}, drain(m.buyBook))
+	t.Nil(m.sellBook.Peek())
+}
+
+func (t *DealerTestSuite) TestChangePhase() {
+	m := newMarket(testInstrument)
+	m.phase = models.TradingPhasePreOpen
+	t.svc.markets[testSymbol] = m
+
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	stop := &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopMarket, StopPrice: models.NewDecimalFromInt(100), Budget: models.NewDecimalFromInt(200), Status: models.OrderStatusNew}
+	ioc := &models.Order{ID: 6, UserID: 16, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), TimeInForce: models.TimeInForceIOC, Status: models.OrderStatusNew}
+	orders := []*models.Order{
+		{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(101), Status: models.OrderStatusNew},
+		{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew},
+	}
+	for i := 0; i < len(orders)+2; i++ {
+		t.mockDB.ExpectBegin()
+		t.mockDB.ExpectCommit()
+	}
+
+	t.NoError(t.svc.ProcessOrder(context.Background(), stop))
+	t.Equal(ErrContinuousOnly, t.svc.ProcessOrder(context.Background(), ioc))
+	for _, order := range orders {
+		t.NoError(t.svc.ProcessOrder(context.Background(), order))
+		t.Equal(models.OrderStatusNew, order.Status)
+	}
+
+	t.mockDB.ExpectBegin()
+	t.mockDealDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseContinuous, ReferencePrice: models.NewDecimalFromInt(100), UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.ChangePhase(context.Background(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseContinuous}))
+
+	t.Equal(models.TradingPhaseContinuous, m.phase)
+	t.Equal(models.NewDecimalFromInt(100), m.lastTradingPrice)
+	t.NotNil(stop.TriggeredAt)
+	t.Equal(models.OrderStatusFilled, stop.Status)
+	t.Empty(stopOrderIDs(m.buyStopBook))
+	t.NoError(t.mockDB.ExpectationsWereMet())
+
+	t.Equal(ErrInvalidPhase, t.svc.ChangePhase(context.Background(), &models.MarketState{Symbol: testSymbol, Phase: "lunch"}))
+	t.Equal(ErrUnknownSymbol, t.svc.ChangePhase(context.Background(), &models.MarketState{Symbol: "UNKNOWN", Phase: models.TradingPhaseClosed}))
+}
+
+func (t *DealerTestSuite) TestProcessOrderMarketClosed() {
+	t.svc.markets[testSymbol].phase = models.TradingPhaseClosed
+	order := &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Status: models.OrderStatusNew}
+	t.expectRecordDeal()
+
+	t.Equal(ErrMarketClosed, t.svc.ProcessOrder(context.Background(), order))
+	t.Equal(models.OrderStatusRejected, order.Status)
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func newBandMarket(breaker models.TradingPhase) *market {
+	instrument := *testInstrument
+	instrument.PriceBand = models.PriceBand{
+		Static:   models.DecimalScale / 10,
+		Dynamic:  models.DecimalScale / 20,
+		Breaker:  breaker,
+		Cooldown: 5 * time.Minute,
+	}
+	instrument.ReferencePrice = models.NewDecimalFromInt(100)
+	m := newMarket(&instrument)
+	m.lastTradingPrice = models.NewDecimalFromInt(100)
+
+	return m
+}
+
+func (t *DealerTestSuite) TestStaticPriceBand() {
+	tests := []struct {
+		name     string
+		order    *models.Order
+		expected error
+	}{
+		{
+			name:     "Limit price in band",
+			order:    &models.Order{ID: 1, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(110), Status: models.OrderStatusNew},
+			expected: nil,
+		},
+		{
+			name:     "Limit price out of band",
+			order:    &models.Order{ID: 2, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(89), Status: models.OrderStatusNew},
+			expected: ErrOutsidePriceBand,
+		},
+		{
+			name:     "Stop limit price out of band",
+			order:    &models.Order{ID: 3, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopLimit, Price: models.NewDecimalFromInt(111), StopPrice: models.NewDecimalFromInt(105), Status: models.OrderStatusNew},
+			expected: ErrOutsidePriceBand,
+		},
+		{
+			name:     "Market order",
+			order:    &models.Order{ID: 4, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeMarket, Status: models.OrderStatusNew},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			t.svc.markets[testSymbol] = newBandMarket(models.TradingPhaseVolatilityAuction)
+			t.expectRecordDeal()
+
+			t.Equal(test.expected, t.svc.ProcessOrder(context.Background(), test.order))
+			t.Equal(test.expected != nil, test.order.Status == models.OrderStatusRejected)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestDynamicPriceBand() {
+	resumeAt := testNow.Add(5 * time.Minute)
+	tests := []struct {
+		name           string
+		breaker        models.TradingPhase
+		order          *models.Order
+		expectedDeals  int
+		expectedPhase  models.TradingPhase
+		expectedStatus models.OrderStatus
+		expectedInBook bool
+	}{
+		{
+			name:           "Deal out of band starts volatility auction",
+			breaker:        models.TradingPhaseVolatilityAuction,
+			order:          &models.Order{ID: 4, UserID: 14, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), Status: models.OrderStatusNew},
+			expectedDeals:  2,
+			expectedPhase:  models.TradingPhaseVolatilityAuction,
+			expectedStatus: models.OrderStatusPartiallyFilled,
+			expectedInBook: true,
+		},
+		{
+			name:           "Deal out of band halts",
+			breaker:        models.TradingPhaseHalted,
+			order:          &models.Order{ID: 4, UserID: 14, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(110), TimeInForce: models.TimeInForceIOC, Status: models.OrderStatusNew},
+			expectedDeals:  2,
+			expectedPhase:  models.TradingPhaseHalted,
+			expectedStatus: models.OrderStatusCancelled,
+		},
+		{
+			name:           "FOK not fillable in band",
+			breaker:        models.TradingPhaseVolatilityAuction,
+			order:          &models.Order{ID: 4, UserID: 14, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(110), TimeInForce: models.TimeInForceFOK, Status: models.OrderStatusNew},
+			expectedPhase:  models.TradingPhaseContinuous,
+			expectedStatus: models.OrderStatusCancelled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newBandMarket(test.breaker)
+			m.sellBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew})
+			m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(104), Status: models.OrderStatusNew})
+			m.sellBook.AddOrder(&models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(106), Status: models.OrderStatusNew})
+
+			result := &matchResult{}
+			m.processOrder(test.order, result, testNow)
+
+			t.Len(result.deals, test.expectedDeals)
+			t.Equal(test.expectedPhase, m.phase)
+			t.Equal(test.expectedStatus, test.order.Status)
+			t.Equal(test.expectedInBook, m.buyBook.Get(test.order.ID) != nil)
+			if test.expectedPhase == models.TradingPhaseContinuous {
+				t.Nil(result.state)
+				t.Nil(m.resumeAt)
+			} else {
+				t.Equal(&models.MarketState{Symbol: testSymbol, Phase: test.expectedPhase, ResumeAt: &resumeAt, UpdatedAt: testNow}, result.state)
+				t.Equal(models.NewDecimalFromInt(104), m.lastTradingPrice)
+			}
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestHaltedMarket() {
+	m := newBandMarket(models.TradingPhaseHalted)
+	m.phase = models.TradingPhaseHalted
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew})
+	t.svc.markets[testSymbol] = m
+
+	order := &models.Order{ID: 2, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew}
+	t.expectRecordDeal()
+	t.Equal(ErrMarketHalted, t.svc.ProcessOrder(context.Background(), order))
+	t.Equal(models.OrderStatusRejected, order.Status)
+
+	t.expectRecordDeal()
+	t.mockAmendDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	t.expectRecordDeal()
+	t.Equal(ErrMarketHalted, t.svc.AmendOrder(context.Background(), &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: models.NewDecimalFromInt(101), Quantity: 2}))
+	t.Equal(ErrOutsidePriceBand, t.svc.AmendOrder(context.Background(), &models.Amendment{OrderID: 1, Symbol: testSymbol, Price: models.NewDecimalFromInt(120), Quantity: 2}))
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestResumeMarket() {
+	resumeAt := testNow.Add(-time.Minute)
+	m := newBandMarket(models.TradingPhaseVolatilityAuction)
+	m.phase = models.TradingPhaseVolatilityAuction
+	m.resumeAt = &resumeAt
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(108), Status: models.OrderStatusNew})
+	m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(108), Status: models.OrderStatusNew})
+	t.svc.markets[testSymbol] = m
+
+	earlier := resumeAt.Add(-time.Minute)
+	t.NoError(t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: testSymbol, ResumeAt: &earlier}))
+	t.Equal(models.TradingPhaseVolatilityAuction, m.phase)
+
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDealDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseContinuous, ReferencePrice: models.NewDecimalFromInt(108), UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: testSymbol, ResumeAt: &resumeAt}))
+
+	t.Equal(models.TradingPhaseContinuous, m.phase)
+	t.Nil(m.resumeAt)
+	t.Equal(models.NewDecimalFromInt(108), m.lastTradingPrice)
+	t.Equal(models.NewDecimalFromInt(108), m.auctionPrice)
+	t.NoError(t.mockDB.ExpectationsWereMet())
+
+	t.NoError(t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: testSymbol}))
+	t.Equal(ErrUnknownSymbol, t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: "UNKNOWN"}))
+}
+
+func (t *DealerTestSuite) TestMarketOrderProtection() {
+	price := models.NewDecimalFromInt(100)
+	marketSell := func() *models.Order {
+		return &models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeMarket, Status: models.OrderStatusNew}
+	}
+	limitSells := func() []*models.Order {
+		var orders []*models.Order
+		for i, p := range []int64{100, 104, 106} {
+			orders = append(orders, &models.Order{ID: int64(i + 1), UserID: int64(i + 11), Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(p), Status: models.OrderStatusNew})
+		}
+		return orders
+	}
+
+	tests := []struct {
+		name           string
+		referencePrice models.Decimal
+		maxSlippage    models.Decimal
+		makers         []*models.Order
+		order          *models.Order
+		expectedPrices []models.Decimal
+		expectedStatus models.OrderStatus
+		expectedReason models.CancelReason
+		expectedInBook bool
+	}{
+		{
+			name:           "Market orders without a price",
+			makers:         []*models.Order{marketSell()},
+			order:          &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), Status: models.OrderStatusNew},
+			expectedStatus: models.OrderStatusCancelled,
+			expectedReason: models.CancelReasonUnfilled,
+		},
+		{
+			name:           "Market orders at the reference price",
+			referencePrice: price,
+			makers:         []*models.Order{marketSell()},
+			order:          &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), Status: models.OrderStatusNew},
+			expectedPrices: []models.Decimal{price},
+			expectedStatus: models.OrderStatusFilled,
+		},
+		{
+			name:           "Limit order with a market order without a price",
+			makers:         []*models.Order{marketSell()},
+			order:          &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: price, Status: models.OrderStatusNew},
+			expectedStatus: models.OrderStatusNew,
+			expectedInBook: true,
+		},
+		{
+			name:           "Market remainder cancelled",
+			makers:         limitSells()[:1],
+			order:          &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), Status: models.OrderStatusNew},
+			expectedPrices: []models.Decimal{price},
+			expectedStatus: models.OrderStatusCancelled,
+			expectedReason: models.CancelReasonUnfilled,
+		},
+		{
+			name:           "Market order stopped by max slippage",
+			maxSlippage:    models.DecimalScale / 20,
+			makers:         limitSells(),
+			order:          &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), Status: models.OrderStatusNew},
+			expectedPrices: []models.Decimal{price, models.NewDecimalFromInt(104)},
+			expectedStatus: models.OrderStatusCancelled,
+			expectedReason: models.CancelReasonSlippage,
+		},
+		{
+			name:           "FOK market order not fillable within max slippage",
+			maxSlippage:    models.DecimalScale / 20,
+			makers:         limitSells(),
+			order:          &models.Order{ID: 5, UserID: 15, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 3, RemainQuantity: 3, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), TimeInForce: models.TimeInForceFOK, Status: models.OrderStatusNew},
+			expectedStatus: models.OrderStatusCancelled,
+			expectedReason: models.CancelReasonFOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			instrument := *testInstrument
+			instrument.ReferencePrice = test.referencePrice
+			instrument.MaxSlippage = test.maxSlippage
+			m := newMarket(&instrument)
+			for _, maker := range test.makers {
+				m.sellBook.AddOrder(maker)
+			}
+
+			result := &matchResult{}
+			m.processOrder(test.order, result, testNow)
+
+			var prices []models.Decimal
+			for _, deal := range result.deals {
+				prices = append(prices, deal.Price)
+			}
+			t.Equal(test.expectedPrices, prices)
+			t.Equal(test.expectedStatus, test.order.Status)
+			t.Equal(test.expectedInBook, m.buyBook.Get(test.order.ID) != nil)
+			if test.expectedReason != "" {
+				t.Require().NotEmpty(result.cancellations)
+				t.Equal(test.expectedReason, result.cancellations[len(result.cancellations)-1].Reason)
+			}
+		})
+	}
+}
+
+func (t *DealerTestSuite) TestEndAuctionCancelsMarketOrders() {
+	m := newMarket(testInstrument)
+	m.phase = models.TradingPhaseAuction
+	buy := &models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 2, RemainQuantity: 2, PriceType: models.PriceTypeMarket, Budget: models.NewDecimalFromInt(1000), Status: models.OrderStatusNew}
+	m.buyBook.AddOrder(buy)
+	m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew})
+	m.buyBook.AddOrder(&models.Order{ID: 3, UserID: 13, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(90), Status: models.OrderStatusNew})
+
+	result := &matchResult{}
+	m.endAuction(result, testNow)
+
+	t.Require().Len(result.deals, 1)
+	t.Equal(models.NewDecimalFromInt(100), result.deals[0].Price)
+	t.Equal(models.OrderStatusCancelled, buy.Status)
+	t.Equal(uint(1), buy.RemainQuantity)
+	t.Equal([]*models.Cancellation{newCancellation(buy, 1, models.CancelReasonUnfilled, testNow)}, result.cancellations)
+	t.Equal([]int64{3}, drain(m.buyBook))
+}
+
+func (t *DealerTestSuite) TestHaltMarket() {
+	m := newMarket(testInstrument)
+	m.phase = models.TradingPhaseAuction
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(101), Status: models.OrderStatusNew})
+	m.sellBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew})
+	t.svc.markets[testSymbol] = m
+
+	t.mockDB.ExpectBegin()
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseHalted, ResumePhase: models.TradingPhaseAuction, UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.HaltMarket(context.Background(), &models.MarketState{Symbol: testSymbol}))
+	t.Equal(models.TradingPhaseHalted, m.phase)
+	t.NoError(t.svc.HaltMarket(context.Background(), &models.MarketState{Symbol: testSymbol}))
+
+	order := &models.Order{ID: 3, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(100), Status: models.OrderStatusNew}
+	t.expectRecordDeal()
+	t.Equal(ErrMarketHalted, t.svc.ProcessOrder(context.Background(), order))
+
+	t.mockDB.ExpectBegin()
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseHalted, ResumePhase: models.TradingPhaseContinuous, UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.ChangePhase(context.Background(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseContinuous}))
+	t.Equal(models.TradingPhaseHalted, m.phase)
+	t.Zero(m.lastTradingPrice)
+
+	cooldown := testNow.Add(time.Minute)
+	t.NoError(t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: testSymbol, ResumeAt: &cooldown}))
+	t.Equal(models.TradingPhaseHalted, m.phase)
+
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockDealDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockCandleDAO.EXPECT().Upsert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseContinuous, ReferencePrice: models.NewDecimalFromInt(100), UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: testSymbol}))
+
+	t.Equal(models.TradingPhaseContinuous, m.phase)
+	t.Empty(m.resumePhase)
+	t.Equal(models.NewDecimalFromInt(100), m.lastTradingPrice)
+	t.NoError(t.mockDB.ExpectationsWereMet())
+
+	t.Equal(ErrUnknownSymbol, t.svc.HaltMarket(context.Background(), &models.MarketState{Symbol: "UNKNOWN"}))
+}
+
+func (t *DealerTestSuite) TestHaltMarketTakesOverBreaker() {
+	resumeAt := testNow.Add(time.Minute)
+	m := newBandMarket(models.TradingPhaseVolatilityAuction)
+	m.phase = models.TradingPhaseVolatilityAuction
+	m.resumeAt = &resumeAt
+	t.svc.markets[testSymbol] = m
+
+	t.mockDB.ExpectBegin()
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseHalted, UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.HaltMarket(context.Background(), &models.MarketState{Symbol: testSymbol}))
+
+	t.Equal(models.TradingPhaseHalted, m.phase)
+	t.Nil(m.resumeAt)
+	t.NoError(t.svc.ResumeMarket(context.Background(), &models.MarketState{Symbol: testSymbol, ResumeAt: &resumeAt}))
+	t.Equal(models.TradingPhaseHalted, m.phase)
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestSkipAppliedMarketMessage() {
+	m := newMarket(testInstrument)
+	m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(99), Status: models.OrderStatusNew})
+	t.svc.markets[testSymbol] = m
+
+	halt := &models.Message{Sequence: 5, Type: models.MessageTypeHaltMarket, Market: &models.MarketState{Symbol: testSymbol}}
+	t.mockDB.ExpectBegin()
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseHalted, ResumePhase: models.TradingPhaseContinuous, Sequence: 5, UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.ProcessMessage(context.Background(), halt))
+	t.NoError(t.svc.ProcessMessage(context.Background(), halt))
+
+	cancelAll := &models.Message{Sequence: 6, Type: models.MessageTypeCancelAll, MassCancel: &models.MassCancel{Symbol: testSymbol}}
+	t.mockDB.ExpectBegin()
+	t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+	t.mockStateDAO.EXPECT().
+		Upsert(context.Background(), gomock.Any(), &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseHalted, ResumePhase: models.TradingPhaseContinuous, Sequence: 6, UpdatedAt: testNow}).
+		Return(nil)
+	t.mockDB.ExpectCommit()
+	t.NoError(t.svc.ProcessMessage(context.Background(), cancelAll))
+	t.Nil(m.buyBook.Get(1))
+
+	m.buyBook.AddOrder(&models.Order{ID: 2, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(99), Status: models.OrderStatusNew})
+	t.NoError(t.svc.ProcessMessage(context.Background(), cancelAll))
+	t.NotNil(m.buyBook.Get(2))
+
+	t.NoError(t.svc.ProcessMessage(context.Background(), &models.Message{Sequence: 4, Type: models.MessageTypeChangePhase, Market: &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseClosed}}))
+	t.NoError(t.svc.ProcessMessage(context.Background(), &models.Message{Sequence: 6, Type: models.MessageTypeResumeMarket, Market: &models.MarketState{Symbol: testSymbol}}))
+	t.Equal(models.TradingPhaseHalted, m.phase)
+	t.Equal(models.TradingPhaseContinuous, m.resumePhase)
+	t.Equal(int64(6), m.sequence)
+	t.NoError(t.mockDB.ExpectationsWereMet())
+}
+
+func (t *DealerTestSuite) TestCancelAll() {
+	newTestMarket := func() *market {
+		m := newMarket(testInstrument)
+		m.buyBook.AddOrder(&models.Order{ID: 1, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(99), Status: models.OrderStatusNew})
+		m.buyBook.AddOrder(&models.Order{ID: 2, UserID: 12, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(98), Status: models.OrderStatusNew})
+		m.sellBook.AddOrder(&models.Order{ID: 3, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeSell, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: models.NewDecimalFromInt(101), Status: models.OrderStatusNew})
+		m.buyStopBook.AddOrder(&models.Order{ID: 4, UserID: 11, Symbol: testSymbol, OrderType: models.OrderTypeBuy, Quantity: 1, RemainQuantity: 1, PriceType: models.PriceTypeStopLimit, Price: models.NewDecimalFromInt(110), StopPrice: models.NewDecimalFromInt(105), Status: models.OrderStatusNew})
+		return m
+	}
+
+	tests := []struct {
+		name       string
+		massCancel *models.MassCancel
+		expected   []int64
+	}{
+		{
+			name:       "Cancel all orders",
+			massCancel: &models.MassCancel{Symbol: testSymbol},
+			expected:   []int64{1, 2, 3, 4},
+		},
+		{
+			name:       "Cancel buy orders",
+			massCancel: &models.MassCancel{Symbol: testSymbol, OrderType: models.OrderTypeBuy},
+			expected:   []int64{1, 2, 4},
+		},
+		{
+			name:       "Cancel orders of user",
+			massCancel: &models.MassCancel{Symbol: testSymbol, UserID: 11},
+			expected:   []int64{1, 3, 4},
+		},
+		{
+			name:       "Cancel no order",
+			massCancel: &models.MassCancel{Symbol: testSymbol, OrderType: models.OrderTypeSell, UserID: 12},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			m := newTestMarket()
+			t.svc.markets[testSymbol] = m
+
+			var cancelled []*models.Order
+			if len(test.expected) != 0 {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().BulkUpdate(context.Background(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ *gorm.DB, orders []*models.Order) error {
+						cancelled = orders
+						return nil
+					})
+				t.mockDB.ExpectCommit()
+			}
+			t.NoError(t.svc.CancelAll(context.Background(), test.massCancel))
+
+			var ids []int64
+			for _, order := range cancelled {
+				t.Equal(models.OrderStatusCancelled, order.Status)
+				t.Nil(m.buyBook.Get(order.ID))
+				t.Nil(m.sellBook.Get(order.ID))
+				t.Nil(m.buyStopBook.Get(order.ID))
+				ids = append(ids, order.ID)
+			}
+			t.Equal(test.expected, ids)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+
+	t.Equal(ErrInvalidOrderType, t.svc.CancelAll(context.Background(), &models.MassCancel{Symbol: testSymbol, OrderType: 3}))
+	t.Equal(ErrUnknownSymbol, t.svc.CancelAll(context.Background(), &models.MassCancel{Symbol: "UNKNOWN"}))
+}
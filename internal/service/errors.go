@@ -0,0 +1,42 @@
+package service
+
+import "errors"
+
+var (
+	ErrUnauthorized               = errors.New("invalid API key or signature")
+	ErrRequestExpired             = errors.New("request timestamp is out of the allowed window")
+	ErrForbidden                  = errors.New("admin API is only open to admin users")
+	ErrUnknownSymbol              = errors.New("unknown symbol")
+	ErrUnknownChannel             = errors.New("unknown channel")
+	ErrUnknownStreamOp            = errors.New("op must be subscribe or unsubscribe")
+	ErrSlowSubscriber             = errors.New("subscriber fell behind, subscribe again for a new snapshot")
+	ErrInvalidMessage             = errors.New("invalid message")
+	ErrPublishNotConfirmed        = errors.New("message is not confirmed by the broker")
+	ErrOrderNotFound              = errors.New("order not found")
+	ErrOrderClosed                = errors.New("order is already filled or cancelled")
+	ErrInvalidOrderType           = errors.New("invalid order type")
+	ErrInvalidPriceType           = errors.New("invalid price type")
+	ErrInvalidQuantity            = errors.New("quantity must be a positive multiple of the lot size, not above the max quantity")
+	ErrInvalidPrice               = errors.New("price must be a positive multiple of the tick size, not above the max price")
+	ErrInvalidStopPrice           = errors.New("stop_price must be a positive multiple of the tick size, not above the max price")
+	ErrInvalidBudget              = errors.New("budget must be positive and not above quantity times the max price for a market or stop market buy, and is only for them")
+	ErrInvalidTimeInForce         = errors.New("invalid time in force")
+	ErrInvalidSelfTradePrevention = errors.New("invalid self-trade prevention")
+	ErrInvalidDisplayQuantity     = errors.New("display_quantity must be a multiple of the lot size less than quantity, for a GTC or GTD limit order")
+	ErrInvalidPostOnly            = errors.New("post_only must be 1 or 2, for a GTC or GTD limit order")
+	ErrInvalidMinQuantity         = errors.New("min_quantity must be a multiple of the lot size not greater than quantity, without post_only")
+	ErrEmptyAmendment             = errors.New("amendment must change price or quantity")
+	ErrPriceNotAmendable          = errors.New("price of market order can not be amended")
+	ErrQuantityFilled             = errors.New("quantity must be greater than the filled quantity")
+	ErrInvalidStatus              = errors.New("invalid order status")
+	ErrInvalidExpireAt            = errors.New("expire_at must be a future time for GTD order")
+	ErrInvalidInterval            = errors.New("interval must be one of 1m, 5m, 1h and 1d")
+	ErrInsufficientFunds          = errors.New("insufficient funds")
+	ErrInvalidAmount              = errors.New("amount must be positive")
+	ErrMarketClosed               = errors.New("market is closed")
+	ErrContinuousOnly             = errors.New("IOC, FOK, post-only and min_quantity orders are only accepted in continuous trading")
+	ErrInvalidPhase               = errors.New("invalid trading phase")
+	ErrMarketHalted               = errors.New("market is halted")
+	ErrOutsidePriceBand           = errors.New("price is outside the price band")
+	ErrUnbalancedPosting          = errors.New("ledger entries do not sum to zero")
+)
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/dao"
+	"dealer/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FeeVolumePeriod is the period of the volume the fee tier of an account is
+// based on.
+const FeeVolumePeriod = 30 * 24 * time.Hour
+
+type FeeCalculatorInterface interface {
+	Refresh(ctx context.Context, now time.Time) error
+	Record(deals []*models.Deal)
+	Rates(userID int64, quoteAsset string) (maker, taker models.Decimal)
+}
+
+// FeeCalculator picks the fee rates of the accounts by their volumes, which
+// are kept in memory so the matching does not wait for the DB. The volumes
+// are loaded from the deals of the last FeeVolumePeriod every refresh
+// interval, and the deals recorded in between are added to them. The volume
+// of an account is kept for every quote asset, and a deal picks the tier by
+// the volume in the quote asset of its market.
+type FeeCalculator struct {
+	db              *gorm.DB
+	dealDAO         dao.DealInterface
+	registry        InstrumentRegistryInterface
+	schedule        *models.FeeSchedule
+	refreshInterval time.Duration
+	volumes         map[accountAsset]models.Decimal
+	refreshedAt     time.Time
+}
+
+type accountAsset struct {
+	userID int64
+	asset  string
+}
+
+var _ FeeCalculatorInterface = (*FeeCalculator)(nil)
+
+func NewFeeCalculator(db *gorm.DB, dealDAO dao.DealInterface, registry InstrumentRegistryInterface, schedule *models.FeeSchedule, refreshInterval time.Duration) *FeeCalculator {
+	return &FeeCalculator{
+		db:              db,
+		dealDAO:         dealDAO,
+		registry:        registry,
+		schedule:        schedule,
+		refreshInterval: refreshInterval,
+		volumes:         make(map[accountAsset]models.Decimal),
+	}
+}
+
+// Refresh reloads the volumes when the refresh interval has passed. Without
+// tiers the volumes are not needed and never loaded.
+func (c *FeeCalculator) Refresh(ctx context.Context, now time.Time) error {
+	if len(c.schedule.Tiers) == 0 || !c.refreshedAt.IsZero() && now.Before(c.refreshedAt.Add(c.refreshInterval)) {
+		return nil
+	}
+
+	volumes, err := c.dealDAO.AccountVolumes(ctx, c.db, now.Add(-FeeVolumePeriod))
+	if err != nil {
+		return err
+	}
+
+	c.volumes = make(map[accountAsset]models.Decimal, len(volumes))
+	for _, volume := range volumes {
+		if instrument, ok := c.registry.Get(volume.Symbol); ok {
+			c.volumes[accountAsset{volume.UserID, instrument.QuoteAsset}] += volume.Volume
+		}
+	}
+	c.refreshedAt = now
+	return nil
+}
+
+// Record adds the committed deals to the volumes of their accounts.
+func (c *FeeCalculator) Record(deals []*models.Deal) {
+	if len(c.schedule.Tiers) == 0 {
+		return
+	}
+
+	for _, deal := range deals {
+		instrument, ok := c.registry.Get(deal.Symbol)
+		if !ok {
+			continue
+		}
+
+		volume := notional(deal.Price, deal.Quantity)
+		c.volumes[accountAsset{deal.TakerUserID, instrument.QuoteAsset}] += volume
+		c.volumes[accountAsset{deal.MakerUserID, instrument.QuoteAsset}] += volume
+	}
+}
+
+func (c *FeeCalculator) Rates(userID int64, quoteAsset string) (maker, taker models.Decimal) {
+	return c.schedule.Rates(c.volumes[accountAsset{userID, quoteAsset}])
+}
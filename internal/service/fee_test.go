@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockDAO "dealer/internal/mock/dao"
+	"dealer/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeCalculator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dealDAO := mockDAO.NewMockDealInterface(ctrl)
+	schedule := &models.FeeSchedule{
+		MakerRate: 100000,
+		TakerRate: 200000,
+		Tiers:     []models.FeeTier{{Volume: 100, MakerRate: 0, TakerRate: 100000}},
+	}
+	registry := NewInstrumentRegistry([]*models.Instrument{
+		{Symbol: "BTCUSD", BaseAsset: "BTC", QuoteAsset: "USD"},
+		{Symbol: "ETHUSD", BaseAsset: "ETH", QuoteAsset: "USD"},
+		{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC"},
+	})
+	calculator := NewFeeCalculator(nil, dealDAO, registry, schedule, time.Hour)
+
+	dealDAO.EXPECT().
+		AccountVolumes(context.Background(), gomock.Any(), testNow.Add(-FeeVolumePeriod)).
+		Return([]*models.AccountVolume{
+			{UserID: 1, Symbol: "BTCUSD", Volume: 60},
+			{UserID: 1, Symbol: "ETHUSD", Volume: 40},
+			{UserID: 2, Symbol: "ETHBTC", Volume: 100},
+			{UserID: 2, Symbol: "UNKNOWN", Volume: 100},
+		}, nil)
+	assert.NoError(t, calculator.Refresh(context.Background(), testNow))
+	assert.NoError(t, calculator.Refresh(context.Background(), testNow.Add(time.Minute)))
+
+	maker, taker := calculator.Rates(1, "USD")
+	assert.Equal(t, models.Decimal(0), maker)
+	assert.Equal(t, models.Decimal(100000), taker)
+	maker, taker = calculator.Rates(2, "USD")
+	assert.Equal(t, models.Decimal(100000), maker)
+	assert.Equal(t, models.Decimal(200000), taker)
+	maker, _ = calculator.Rates(2, "BTC")
+	assert.Equal(t, models.Decimal(0), maker)
+
+	calculator.Record([]*models.Deal{{Symbol: "ETHBTC", TakerUserID: 3, MakerUserID: 4, Quantity: 10, Price: 10}})
+	maker, _ = calculator.Rates(3, "USD")
+	assert.Equal(t, models.Decimal(100000), maker)
+	maker, _ = calculator.Rates(3, "BTC")
+	assert.Equal(t, models.Decimal(0), maker)
+
+	dealDAO.EXPECT().
+		AccountVolumes(context.Background(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New(""))
+	assert.Error(t, calculator.Refresh(context.Background(), testNow.Add(time.Hour)))
+}
+
+func TestFeeCalculatorWithoutTiers(t *testing.T) {
+	calculator := NewFeeCalculator(nil, nil, nil, &models.FeeSchedule{MakerRate: 100000, TakerRate: 200000}, time.Hour)
+	assert.NoError(t, calculator.Refresh(context.Background(), testNow))
+	calculator.Record([]*models.Deal{{Symbol: "BTCUSD", TakerUserID: 1, MakerUserID: 2, Quantity: 10, Price: 10}})
+
+	maker, taker := calculator.Rates(1, "USD")
+	assert.Equal(t, models.Decimal(100000), maker)
+	assert.Equal(t, models.Decimal(200000), taker)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"dealer/internal/models"
+)
+
+type InstrumentRegistryInterface interface {
+	Get(string) (*models.Instrument, bool)
+	List() []*models.Instrument
+}
+
+type InstrumentRegistry struct {
+	instruments []*models.Instrument
+	symbols     map[string]*models.Instrument
+}
+
+var _ InstrumentRegistryInterface = (*InstrumentRegistry)(nil)
+
+func NewInstrumentRegistry(instruments []*models.Instrument) *InstrumentRegistry {
+	registry := &InstrumentRegistry{
+		symbols: make(map[string]*models.Instrument, len(instruments)),
+	}
+	for _, instrument := range instruments {
+		if _, ok := registry.symbols[instrument.Symbol]; ok {
+			continue
+		}
+
+		registry.symbols[instrument.Symbol] = instrument
+		registry.instruments = append(registry.instruments, instrument)
+	}
+
+	return registry
+}
+
+func (r *InstrumentRegistry) Get(symbol string) (*models.Instrument, bool) {
+	instrument, ok := r.symbols[symbol]
+	return instrument, ok
+}
+
+func (r *InstrumentRegistry) List() []*models.Instrument {
+	return r.instruments
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentRegistry(t *testing.T) {
+	registry := NewInstrumentRegistry([]*models.Instrument{
+		{Symbol: "BTCUSD"},
+		{Symbol: "ETHUSD"},
+		{Symbol: "BTCUSD"},
+	})
+
+	tests := []struct {
+		name     string
+		symbol   string
+		expected *models.Instrument
+		ok       bool
+	}{
+		{
+			name:     "Get instrument",
+			symbol:   "ETHUSD",
+			expected: &models.Instrument{Symbol: "ETHUSD"},
+			ok:       true,
+		},
+		{
+			name:     "Get unknown instrument",
+			symbol:   "DOGEUSD",
+			expected: nil,
+			ok:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, ok := registry.Get(test.symbol)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+
+	assert.Equal(t, []*models.Instrument{{Symbol: "BTCUSD"}, {Symbol: "ETHUSD"}}, registry.List())
+}
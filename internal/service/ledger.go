@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/dao"
+	"dealer/internal/models"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type LedgerInterface interface {
+	Lock(ctx context.Context, tx *gorm.DB, userID, orderID int64, asset string, amount models.Decimal) error
+	Post(ctx context.Context, tx *gorm.DB, entries []*models.LedgerEntry) error
+	Deposit(ctx context.Context, userID int64, asset string, amount models.Decimal) error
+}
+
+// Ledger keeps the balances by double-entry bookkeeping, every change of a
+// balance is recorded as a ledger entry and the entries of a posting sum to
+// zero for every asset.
+type Ledger struct {
+	db         *gorm.DB
+	balanceDAO dao.BalanceInterface
+	ledgerDAO  dao.LedgerInterface
+}
+
+var _ LedgerInterface = (*Ledger)(nil)
+
+func NewLedger(db *gorm.DB, balanceDAO dao.BalanceInterface, ledgerDAO dao.LedgerInterface) *Ledger {
+	return &Ledger{
+		db:         db,
+		balanceDAO: balanceDAO,
+		ledgerDAO:  ledgerDAO,
+	}
+}
+
+// Lock reserves amount of the available funds of the user for an order, or
+// returns ErrInsufficientFunds when the available funds are less.
+func (l *Ledger) Lock(ctx context.Context, tx *gorm.DB, userID, orderID int64, asset string, amount models.Decimal) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	ok, err := l.balanceDAO.Lock(ctx, tx, userID, asset, amount)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrInsufficientFunds
+	}
+
+	return l.ledgerDAO.Insert(ctx, tx, []*models.LedgerEntry{
+		{UserID: userID, Asset: asset, Bucket: models.BalanceAvailable, Amount: -amount, Reason: models.LedgerReasonLock, OrderID: orderID},
+		{UserID: userID, Asset: asset, Bucket: models.BalanceLocked, Amount: amount, Reason: models.LedgerReasonLock, OrderID: orderID},
+	})
+}
+
+// Post applies the entries to the balances and records them. Entries of zero
+// amount are dropped.
+func (l *Ledger) Post(ctx context.Context, tx *gorm.DB, entries []*models.LedgerEntry) error {
+	type key struct {
+		userID int64
+		asset  string
+	}
+
+	var posted []*models.LedgerEntry
+	var deltas []*models.Balance
+	index := make(map[key]*models.Balance)
+	sums := make(map[string]models.Decimal)
+	for _, entry := range entries {
+		if entry.Amount == 0 {
+			continue
+		}
+
+		k := key{userID: entry.UserID, asset: entry.Asset}
+		delta, ok := index[k]
+		if !ok {
+			delta = &models.Balance{UserID: entry.UserID, Asset: entry.Asset}
+			index[k] = delta
+			deltas = append(deltas, delta)
+		}
+
+		if entry.Bucket == models.BalanceLocked {
+			delta.Locked += entry.Amount
+		} else {
+			delta.Available += entry.Amount
+		}
+		sums[entry.Asset] += entry.Amount
+		posted = append(posted, entry)
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedPosting
+		}
+	}
+
+	if err := l.balanceDAO.Add(ctx, tx, deltas); err != nil {
+		return err
+	}
+
+	return l.ledgerDAO.Insert(ctx, tx, posted)
+}
+
+// Deposit credits the available funds of the user against the system
+// account.
+func (l *Ledger) Deposit(ctx context.Context, userID int64, asset string, amount models.Decimal) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	tx := l.db.Begin()
+	if err := l.Post(ctx, tx, []*models.LedgerEntry{
+		{UserID: userID, Asset: asset, Bucket: models.BalanceAvailable, Amount: amount, Reason: models.LedgerReasonDeposit},
+		{UserID: models.SystemUserID, Asset: asset, Bucket: models.BalanceAvailable, Amount: -amount, Reason: models.LedgerReasonDeposit},
+	}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// reservation returns the asset and amount locked for the remain quantity of
+// an order. A sell locks the base asset, a limit buy locks its price for every
+// unit and a market buy locks its budget.
+func reservation(instrument *models.Instrument, order *models.Order) (string, models.Decimal) {
+	switch {
+	case order.OrderType == models.OrderTypeSell:
+		return instrument.BaseAsset, models.NewDecimalFromInt(int64(order.RemainQuantity))
+	case order.MatchPriceType() == models.PriceTypeMarket:
+		return instrument.QuoteAsset, order.Budget
+	default:
+		return instrument.QuoteAsset, notional(order.Price, order.RemainQuantity)
+	}
+}
+
+// notional returns the value of quantity units at price. ValidateOrder keeps
+// the orders within the limits of the instrument, whose product fits in a
+// Decimal, and a value that overflows anyway saturates at the largest
+// Decimal, which no balance can afford, instead of wrapping to a negative
+// amount.
+func notional(price models.Decimal, quantity uint) models.Decimal {
+	value, err := price.Mul(quantity)
+	if err != nil {
+		return math.MaxInt64
+	}
+
+	return value
+}
+
+// releaseEntries moves amount of the locked funds of an order back to the
+// available funds. A non-positive amount releases nothing.
+func releaseEntries(order *models.Order, asset string, amount models.Decimal) []*models.LedgerEntry {
+	if amount <= 0 {
+		return nil
+	}
+
+	return []*models.LedgerEntry{
+		{UserID: order.UserID, Asset: asset, Bucket: models.BalanceLocked, Amount: -amount, Reason: models.LedgerReasonRelease, OrderID: order.ID},
+		{UserID: order.UserID, Asset: asset, Bucket: models.BalanceAvailable, Amount: amount, Reason: models.LedgerReasonRelease, OrderID: order.ID},
+	}
+}
+
+// settlementEntries exchanges the assets of a deal between the buyer and the
+// seller out of their locked funds, and pays the fees of the deal to the
+// system account. reserved is the quote the buyer locked for the deal
+// quantity, the part above the cost is released.
+func settlementEntries(instrument *models.Instrument, deal *models.Deal, buyer, seller *models.Order, reserved models.Decimal) []*models.LedgerEntry {
+	cost := notional(deal.Price, deal.Quantity)
+	quantity := models.NewDecimalFromInt(int64(deal.Quantity))
+	entries := []*models.LedgerEntry{
+		{UserID: buyer.UserID, Asset: instrument.QuoteAsset, Bucket: models.BalanceLocked, Amount: -reserved, Reason: models.LedgerReasonTrade, OrderID: buyer.ID},
+		{UserID: buyer.UserID, Asset: instrument.QuoteAsset, Bucket: models.BalanceAvailable, Amount: reserved - cost, Reason: models.LedgerReasonTrade, OrderID: buyer.ID},
+		{UserID: seller.UserID, Asset: instrument.QuoteAsset, Bucket: models.BalanceAvailable, Amount: cost, Reason: models.LedgerReasonTrade, OrderID: seller.ID},
+		{UserID: seller.UserID, Asset: instrument.BaseAsset, Bucket: models.BalanceLocked, Amount: -quantity, Reason: models.LedgerReasonTrade, OrderID: seller.ID},
+		{UserID: buyer.UserID, Asset: instrument.BaseAsset, Bucket: models.BalanceAvailable, Amount: quantity, Reason: models.LedgerReasonTrade, OrderID: buyer.ID},
+	}
+
+	for _, order := range []*models.Order{buyer, seller} {
+		fee, asset := deal.MakerFee, deal.MakerFeeAsset
+		if order.ID == deal.TakerOrderID {
+			fee, asset = deal.TakerFee, deal.TakerFeeAsset
+		}
+		if fee == 0 {
+			continue
+		}
+
+		entries = append(entries,
+			&models.LedgerEntry{UserID: order.UserID, Asset: asset, Bucket: models.BalanceAvailable, Amount: -fee, Reason: models.LedgerReasonFee, OrderID: order.ID},
+			&models.LedgerEntry{UserID: models.SystemUserID, Asset: asset, Bucket: models.BalanceAvailable, Amount: fee, Reason: models.LedgerReasonFee, OrderID: order.ID},
+		)
+	}
+
+	return entries
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	mockDAO "dealer/internal/mock/dao"
+	"dealer/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type LedgerTestSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	db             *sql.DB
+	mockDB         sqlmock.Sqlmock
+	mockGormDB     *gorm.DB
+	mockBalanceDAO *mockDAO.MockBalanceInterface
+	mockLedgerDAO  *mockDAO.MockLedgerInterface
+	svc            *Ledger
+}
+
+func (t *LedgerTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockBalanceDAO = mockDAO.NewMockBalanceInterface(t.ctrl)
+	t.mockLedgerDAO = mockDAO.NewMockLedgerInterface(t.ctrl)
+	t.svc = NewLedger(t.mockGormDB, t.mockBalanceDAO, t.mockLedgerDAO)
+}
+
+func (t *LedgerTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestLedgerTestSuite(t *testing.T) {
+	suite.Run(t, new(LedgerTestSuite))
+}
+
+func (t *LedgerTestSuite) TestLock() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected error
+		hasError bool
+	}{
+		{
+			name: "Lock funds",
+			fn: func() {
+				t.mockBalanceDAO.EXPECT().Lock(context.Background(), gomock.Any(), int64(1), "USD", models.Decimal(10)).Return(true, nil)
+				t.mockLedgerDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), []*models.LedgerEntry{
+						{UserID: 1, Asset: "USD", Bucket: models.BalanceAvailable, Amount: -10, Reason: models.LedgerReasonLock, OrderID: 2},
+						{UserID: 1, Asset: "USD", Bucket: models.BalanceLocked, Amount: 10, Reason: models.LedgerReasonLock, OrderID: 2},
+					}).
+					Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Lock insufficient funds",
+			fn: func() {
+				t.mockBalanceDAO.EXPECT().Lock(context.Background(), gomock.Any(), int64(1), "USD", models.Decimal(10)).Return(false, nil)
+			},
+			expected: ErrInsufficientFunds,
+			hasError: true,
+		},
+		{
+			name: "Lock failed",
+			fn: func() {
+				t.mockBalanceDAO.EXPECT().Lock(context.Background(), gomock.Any(), int64(1), "USD", models.Decimal(10)).Return(false, errors.New(""))
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.Lock(context.Background(), t.mockGormDB, 1, 2, "USD", 10)
+			t.Equal(test.hasError, err != nil)
+			if test.expected != nil {
+				t.Equal(test.expected, err)
+			}
+		})
+	}
+
+	t.Equal(ErrInvalidAmount, t.svc.Lock(context.Background(), t.mockGormDB, 1, 2, "USD", 0))
+	t.Equal(ErrInvalidAmount, t.svc.Lock(context.Background(), t.mockGormDB, 1, 2, "USD", -10))
+}
+
+func (t *LedgerTestSuite) TestPost() {
+	tests := []struct {
+		name     string
+		entries  []*models.LedgerEntry
+		fn       func()
+		expected error
+	}{
+		{
+			name: "Post balanced entries",
+			entries: []*models.LedgerEntry{
+				{UserID: 1, Asset: "USD", Bucket: models.BalanceLocked, Amount: -10},
+				{UserID: 1, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 0},
+				{UserID: 2, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 10},
+				{UserID: 2, Asset: "BTC", Bucket: models.BalanceLocked, Amount: -1},
+				{UserID: 1, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: 1},
+			},
+			fn: func() {
+				t.mockBalanceDAO.EXPECT().
+					Add(context.Background(), gomock.Any(), []*models.Balance{
+						{UserID: 1, Asset: "USD", Locked: -10},
+						{UserID: 2, Asset: "USD", Available: 10},
+						{UserID: 2, Asset: "BTC", Locked: -1},
+						{UserID: 1, Asset: "BTC", Available: 1},
+					}).
+					Return(nil)
+				t.mockLedgerDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), []*models.LedgerEntry{
+						{UserID: 1, Asset: "USD", Bucket: models.BalanceLocked, Amount: -10},
+						{UserID: 2, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 10},
+						{UserID: 2, Asset: "BTC", Bucket: models.BalanceLocked, Amount: -1},
+						{UserID: 1, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: 1},
+					}).
+					Return(nil)
+			},
+			expected: nil,
+		},
+		{
+			name: "Post unbalanced entries",
+			entries: []*models.LedgerEntry{
+				{UserID: 1, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 10},
+			},
+			fn:       func() {},
+			expected: ErrUnbalancedPosting,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			t.Equal(test.expected, t.svc.Post(context.Background(), t.mockGormDB, test.entries))
+		})
+	}
+}
+
+func (t *LedgerTestSuite) TestDeposit() {
+	tests := []struct {
+		name     string
+		amount   models.Decimal
+		fn       func()
+		hasError bool
+	}{
+		{
+			name:   "Deposit success",
+			amount: 10,
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockBalanceDAO.EXPECT().
+					Add(context.Background(), gomock.Any(), []*models.Balance{
+						{UserID: 1, Asset: "USD", Available: 10},
+						{UserID: models.SystemUserID, Asset: "USD", Available: -10},
+					}).
+					Return(nil)
+				t.mockLedgerDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name:   "Deposit failed",
+			amount: 10,
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockBalanceDAO.EXPECT().Add(context.Background(), gomock.Any(), gomock.Any()).Return(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+		{
+			name:     "Deposit invalid amount",
+			amount:   0,
+			fn:       func() {},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.Deposit(context.Background(), 1, "USD", test.amount)
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReservation(t *testing.T) {
+	instrument := &models.Instrument{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD"}
+	tests := []struct {
+		name   string
+		order  *models.Order
+		asset  string
+		amount models.Decimal
+	}{
+		{
+			name:   "Sell order locks base asset",
+			order:  &models.Order{OrderType: models.OrderTypeSell, PriceType: models.PriceTypeLimit, Price: 10, RemainQuantity: 2},
+			asset:  "BTC",
+			amount: 2 * models.DecimalScale,
+		},
+		{
+			name:   "Limit buy order locks price of remain quantity",
+			order:  &models.Order{OrderType: models.OrderTypeBuy, PriceType: models.PriceTypeStopLimit, Price: 10, RemainQuantity: 2},
+			asset:  "USD",
+			amount: 20,
+		},
+		{
+			name:   "Market buy order locks budget",
+			order:  &models.Order{OrderType: models.OrderTypeBuy, PriceType: models.PriceTypeStopMarket, Budget: 15, RemainQuantity: 2},
+			asset:  "USD",
+			amount: 15,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			asset, amount := reservation(instrument, test.order)
+			assert.Equal(t, test.asset, asset)
+			assert.Equal(t, test.amount, amount)
+		})
+	}
+}
+
+func TestSettlementEntries(t *testing.T) {
+	instrument := &models.Instrument{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD"}
+	deal := &models.Deal{ID: 5, Quantity: 2, Price: 9}
+	buyer := &models.Order{ID: 1, UserID: 11}
+	seller := &models.Order{ID: 2, UserID: 12}
+
+	entries := settlementEntries(instrument, deal, buyer, seller, 20)
+	assert.Equal(t, []*models.LedgerEntry{
+		{UserID: 11, Asset: "USD", Bucket: models.BalanceLocked, Amount: -20, Reason: models.LedgerReasonTrade, OrderID: 1},
+		{UserID: 11, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 2, Reason: models.LedgerReasonTrade, OrderID: 1},
+		{UserID: 12, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 18, Reason: models.LedgerReasonTrade, OrderID: 2},
+		{UserID: 12, Asset: "BTC", Bucket: models.BalanceLocked, Amount: -2 * models.DecimalScale, Reason: models.LedgerReasonTrade, OrderID: 2},
+		{UserID: 11, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: 2 * models.DecimalScale, Reason: models.LedgerReasonTrade, OrderID: 1},
+	}, entries)
+}
+
+func TestSettlementEntriesWithFees(t *testing.T) {
+	instrument := &models.Instrument{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD"}
+	deal := &models.Deal{ID: 5, TakerOrderID: 2, MakerOrderID: 1, Quantity: 1, Price: 10, TakerFee: 1, TakerFeeAsset: "USD", MakerFee: 3, MakerFeeAsset: "BTC"}
+	buyer := &models.Order{ID: 1, UserID: 11}
+	seller := &models.Order{ID: 2, UserID: 12}
+
+	entries := settlementEntries(instrument, deal, buyer, seller, 10)
+	assert.Equal(t, []*models.LedgerEntry{
+		{UserID: 11, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: -3, Reason: models.LedgerReasonFee, OrderID: 1},
+		{UserID: models.SystemUserID, Asset: "BTC", Bucket: models.BalanceAvailable, Amount: 3, Reason: models.LedgerReasonFee, OrderID: 1},
+		{UserID: 12, Asset: "USD", Bucket: models.BalanceAvailable, Amount: -1, Reason: models.LedgerReasonFee, OrderID: 2},
+		{UserID: models.SystemUserID, Asset: "USD", Bucket: models.BalanceAvailable, Amount: 1, Reason: models.LedgerReasonFee, OrderID: 2},
+	}, entries[5:])
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"dealer/internal/logger"
+	"dealer/internal/models"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	tradesChannelPrefix = "trades:"
+	depthChannelPrefix  = "depth:"
+	ordersChannelPrefix = "orders:"
+	statusChannelPrefix = "status:"
+)
+
+// MarketDataInterface delivers the encoded events of a channel to the
+// subscribed Go channels. A subscribed Go channel must be buffered and empty,
+// and it is closed on unsubscribe or when the subscriber falls behind by more
+// than its buffer, in which case it has to subscribe again to get a new
+// snapshot.
+type MarketDataInterface interface {
+	Publish(symbol string, update *models.MarketUpdate)
+	Subscribe(channel string, c chan []byte) error
+	Unsubscribe(channel string, c chan []byte)
+	Depth(symbol string, levels int) (*models.Depth, error)
+	Ticker(symbol string) (*models.Ticker, error)
+}
+
+type marketChannel struct {
+	sequence    int64
+	subscribers map[chan []byte]struct{}
+}
+
+// MarketData fans the market updates of the dealer out to the subscribers of
+// the trades:<symbol>, depth:<symbol>, status:<symbol> and orders:<order id>
+// channels. Every channel has its own sequence, so subscribers can detect a
+// gap and resync from a snapshot. It also keeps the last depth, trading price
+// and status of every market, which are never modified once published, so the
+// HTTP side reads a consistent snapshot without touching the books of the
+// dealer.
+type MarketData struct {
+	mu         sync.Mutex
+	channels   map[string]*marketChannel
+	depths     map[string]*models.Depth
+	lastPrices map[string]models.Decimal
+	statuses   map[string]*models.MarketStatus
+}
+
+var _ MarketDataInterface = (*MarketData)(nil)
+
+func NewMarketData(registry InstrumentRegistryInterface) *MarketData {
+	channels := make(map[string]*marketChannel)
+	for _, instrument := range registry.List() {
+		channels[tradesChannelPrefix+instrument.Symbol] = newMarketChannel()
+		channels[depthChannelPrefix+instrument.Symbol] = newMarketChannel()
+		channels[statusChannelPrefix+instrument.Symbol] = newMarketChannel()
+	}
+
+	return &MarketData{
+		channels:   channels,
+		depths:     make(map[string]*models.Depth),
+		lastPrices: make(map[string]models.Decimal),
+		statuses:   make(map[string]*models.MarketStatus),
+	}
+}
+
+func newMarketChannel() *marketChannel {
+	return &marketChannel{
+		subscribers: make(map[chan []byte]struct{}),
+	}
+}
+
+func (md *MarketData) Publish(symbol string, update *models.MarketUpdate) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for _, deal := range update.Deals {
+		md.broadcast(tradesChannelPrefix+symbol, deal)
+	}
+
+	published := make(map[int64]bool, len(update.Orders))
+	for i := len(update.Orders) - 1; i >= 0; i-- {
+		order := update.Orders[i]
+		if published[order.ID] {
+			continue
+		}
+		published[order.ID] = true
+		md.broadcast(ordersChannelPrefix+strconv.FormatInt(order.ID, 10), order)
+	}
+
+	if update.LastPrice != 0 {
+		md.lastPrices[symbol] = update.LastPrice
+	}
+
+	if update.Depth != nil {
+		if diff := diffDepth(md.depths[symbol], update.Depth); diff != nil {
+			md.broadcast(depthChannelPrefix+symbol, diff)
+		}
+		md.depths[symbol] = update.Depth
+	}
+
+	if update.Status != nil {
+		if old := md.statuses[symbol]; old == nil || !old.Equal(update.Status) {
+			md.broadcast(statusChannelPrefix+symbol, update.Status)
+		}
+		md.statuses[symbol] = update.Status
+	}
+}
+
+func (md *MarketData) Subscribe(channel string, c chan []byte) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	mc, ok := md.channels[channel]
+	if !ok {
+		if !isOrdersChannel(channel) {
+			return ErrUnknownChannel
+		}
+		mc = newMarketChannel()
+		md.channels[channel] = mc
+	}
+
+	event := &models.MarketEvent{Channel: channel, Type: models.MarketEventSnapshot, Sequence: mc.sequence}
+	switch {
+	case strings.HasPrefix(channel, depthChannelPrefix):
+		depth := md.depths[strings.TrimPrefix(channel, depthChannelPrefix)]
+		if depth == nil {
+			depth = &models.Depth{}
+		}
+		event.Data = depth
+	case strings.HasPrefix(channel, statusChannelPrefix):
+		status := md.statuses[strings.TrimPrefix(channel, statusChannelPrefix)]
+		if status == nil {
+			status = &models.MarketStatus{}
+		}
+		event.Data = status
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	c <- payload
+	mc.subscribers[c] = struct{}{}
+	return nil
+}
+
+func (md *MarketData) Unsubscribe(channel string, c chan []byte) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.remove(channel, c)
+}
+
+// Depth returns up to levels price levels of each side of the last published
+// depth.
+func (md *MarketData) Depth(symbol string, levels int) (*models.Depth, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if _, ok := md.channels[depthChannelPrefix+symbol]; !ok {
+		return nil, ErrUnknownSymbol
+	}
+
+	depth := &models.Depth{}
+	if d, ok := md.depths[symbol]; ok {
+		depth.Bids = topLevels(d.Bids, levels)
+		depth.Asks = topLevels(d.Asks, levels)
+	}
+
+	return depth, nil
+}
+
+// Ticker returns the best prices, the last trading price and the status of a
+// market.
+func (md *MarketData) Ticker(symbol string) (*models.Ticker, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if _, ok := md.channels[depthChannelPrefix+symbol]; !ok {
+		return nil, ErrUnknownSymbol
+	}
+
+	ticker := &models.Ticker{Symbol: symbol, LastPrice: md.lastPrices[symbol]}
+	if d, ok := md.depths[symbol]; ok {
+		if len(d.Bids) != 0 {
+			ticker.BestBid = d.Bids[0].Price
+		}
+		if len(d.Asks) != 0 {
+			ticker.BestAsk = d.Asks[0].Price
+		}
+	}
+	if status, ok := md.statuses[symbol]; ok {
+		ticker.Phase = status.Phase
+		ticker.IndicativePrice = status.IndicativePrice
+		ticker.IndicativeVolume = status.IndicativeVolume
+		ticker.ResumeAt = status.ResumeAt
+	}
+
+	return ticker, nil
+}
+
+func (md *MarketData) broadcast(channel string, data interface{}) {
+	mc, ok := md.channels[channel]
+	if !ok {
+		return
+	}
+
+	mc.sequence++
+	if len(mc.subscribers) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(&models.MarketEvent{Channel: channel, Type: models.MarketEventUpdate, Sequence: mc.sequence, Data: data})
+	if err != nil {
+		logger.GetLogger().Error(err.Error())
+		return
+	}
+
+	for c := range mc.subscribers {
+		select {
+		case c <- payload:
+		default:
+			md.remove(channel, c)
+		}
+	}
+}
+
+func (md *MarketData) remove(channel string, c chan []byte) {
+	mc, ok := md.channels[channel]
+	if !ok {
+		return
+	}
+	if _, ok := mc.subscribers[c]; !ok {
+		return
+	}
+
+	delete(mc.subscribers, c)
+	close(c)
+	if len(mc.subscribers) == 0 && isOrdersChannel(channel) {
+		delete(md.channels, channel)
+	}
+}
+
+func topLevels(levels []*models.PriceLevel, n int) []*models.PriceLevel {
+	if len(levels) > n {
+		return levels[:n]
+	}
+
+	return levels
+}
+
+func isOrdersChannel(channel string) bool {
+	_, ok := ParseOrdersChannel(channel)
+	return ok
+}
+
+// ParseOrdersChannel returns the order ID of an orders:<order id> channel.
+func ParseOrdersChannel(channel string) (int64, bool) {
+	if !strings.HasPrefix(channel, ordersChannelPrefix) {
+		return 0, false
+	}
+
+	param := strings.TrimPrefix(channel, ordersChannelPrefix)
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || id <= 0 || strconv.FormatInt(id, 10) != param {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// diffDepth returns the levels changed from old to current, or nil when
+// nothing changed. A removed level is returned with zero quantity.
+func diffDepth(old, current *models.Depth) *models.Depth {
+	if old == nil {
+		old = &models.Depth{}
+	}
+
+	diff := &models.Depth{
+		Bids: diffLevels(old.Bids, current.Bids),
+		Asks: diffLevels(old.Asks, current.Asks),
+	}
+	if len(diff.Bids) == 0 && len(diff.Asks) == 0 {
+		return nil
+	}
+
+	return diff
+}
+
+func diffLevels(old, current []*models.PriceLevel) []*models.PriceLevel {
+	levels := make(map[models.Decimal]*models.PriceLevel, len(old))
+	for _, level := range old {
+		levels[level.Price] = level
+	}
+
+	var diff []*models.PriceLevel
+	for _, level := range current {
+		if previous, ok := levels[level.Price]; !ok || *previous != *level {
+			diff = append(diff, level)
+		}
+		delete(levels, level.Price)
+	}
+
+	for _, level := range old {
+		if _, ok := levels[level.Price]; ok {
+			diff = append(diff, &models.PriceLevel{Price: level.Price})
+		}
+	}
+
+	return diff
+}
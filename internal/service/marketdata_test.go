@@ -0,0 +1,191 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMarketData() *MarketData {
+	return NewMarketData(NewInstrumentRegistry([]*models.Instrument{{Symbol: testSymbol}}))
+}
+
+func receive(t *testing.T, c chan []byte) map[string]interface{} {
+	select {
+	case payload := <-c:
+		var event map[string]interface{}
+		assert.NoError(t, json.Unmarshal(payload, &event))
+		return event
+	default:
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestMarketDataSubscribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		channel  string
+		expected error
+	}{
+		{name: "Subscribe trades", channel: "trades:BTCUSD", expected: nil},
+		{name: "Subscribe depth", channel: "depth:BTCUSD", expected: nil},
+		{name: "Subscribe status", channel: "status:BTCUSD", expected: nil},
+		{name: "Subscribe orders", channel: "orders:1", expected: nil},
+		{name: "Subscribe unknown symbol", channel: "trades:UNKNOWN", expected: ErrUnknownChannel},
+		{name: "Subscribe invalid order ID", channel: "orders:01", expected: ErrUnknownChannel},
+		{name: "Subscribe unknown channel", channel: "candles:BTCUSD", expected: ErrUnknownChannel},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			md := newTestMarketData()
+			c := make(chan []byte, 1)
+			assert.Equal(t, test.expected, md.Subscribe(test.channel, c))
+			if test.expected == nil {
+				event := receive(t, c)
+				assert.Equal(t, models.MarketEventSnapshot, event["type"])
+				assert.Equal(t, float64(0), event["sequence"])
+			}
+		})
+	}
+}
+
+func TestMarketDataPublish(t *testing.T) {
+	md := newTestMarketData()
+	md.Publish(testSymbol, &models.MarketUpdate{
+		Deals: []*models.Deal{{ID: 1, Symbol: testSymbol}},
+		Depth: &models.Depth{Bids: []*models.PriceLevel{{Price: 10 * models.DecimalScale, Quantity: 1, Count: 1}}},
+	})
+
+	trades := make(chan []byte, 4)
+	depth := make(chan []byte, 4)
+	orders := make(chan []byte, 4)
+	assert.NoError(t, md.Subscribe("trades:BTCUSD", trades))
+	assert.NoError(t, md.Subscribe("depth:BTCUSD", depth))
+	assert.NoError(t, md.Subscribe("orders:2", orders))
+	assert.Equal(t, float64(1), receive(t, trades)["sequence"])
+	snapshot := receive(t, depth)
+	assert.Equal(t, float64(1), snapshot["sequence"])
+	assert.Equal(t, map[string]interface{}{"bids": []interface{}{map[string]interface{}{"price": float64(10), "quantity": float64(1), "count": float64(1)}}, "asks": nil}, snapshot["data"])
+	receive(t, orders)
+
+	md.Publish(testSymbol, &models.MarketUpdate{
+		Deals: []*models.Deal{{ID: 2, Symbol: testSymbol}, {ID: 3, Symbol: testSymbol}},
+		Orders: []*models.Order{
+			{ID: 2, RemainQuantity: 2},
+			{ID: 2, RemainQuantity: 1},
+		},
+		Depth: &models.Depth{Asks: []*models.PriceLevel{{Price: 11 * models.DecimalScale, Quantity: 1, Count: 1}}},
+	})
+
+	event := receive(t, trades)
+	assert.Equal(t, float64(2), event["sequence"])
+	assert.Equal(t, float64(2), event["data"].(map[string]interface{})["id"])
+	event = receive(t, trades)
+	assert.Equal(t, float64(3), event["sequence"])
+	assert.Equal(t, float64(3), event["data"].(map[string]interface{})["id"])
+
+	event = receive(t, orders)
+	assert.Equal(t, models.MarketEventUpdate, event["type"])
+	assert.Equal(t, float64(1), event["sequence"])
+	assert.Equal(t, float64(1), event["data"].(map[string]interface{})["remain_quantity"])
+	assert.Empty(t, orders)
+
+	event = receive(t, depth)
+	assert.Equal(t, float64(2), event["sequence"])
+	assert.Equal(t, map[string]interface{}{
+		"bids": []interface{}{map[string]interface{}{"price": float64(10), "quantity": float64(0), "count": float64(0)}},
+		"asks": []interface{}{map[string]interface{}{"price": float64(11), "quantity": float64(1), "count": float64(1)}},
+	}, event["data"])
+
+	md.Publish(testSymbol, &models.MarketUpdate{Depth: &models.Depth{Asks: []*models.PriceLevel{{Price: 11 * models.DecimalScale, Quantity: 1, Count: 1}}}})
+	assert.Empty(t, depth)
+}
+
+func TestMarketDataSlowSubscriber(t *testing.T) {
+	md := newTestMarketData()
+	c := make(chan []byte, 1)
+	assert.NoError(t, md.Subscribe("trades:BTCUSD", c))
+	md.Publish(testSymbol, &models.MarketUpdate{Deals: []*models.Deal{{ID: 1}}})
+
+	receive(t, c)
+	_, ok := <-c
+	assert.False(t, ok)
+}
+
+func TestMarketDataUnsubscribe(t *testing.T) {
+	md := newTestMarketData()
+	c := make(chan []byte, 1)
+	assert.NoError(t, md.Subscribe("orders:1", c))
+	receive(t, c)
+
+	md.Unsubscribe("orders:1", c)
+	_, ok := <-c
+	assert.False(t, ok)
+	assert.NotContains(t, md.channels, "orders:1")
+
+	md.Unsubscribe("orders:1", c)
+}
+
+func TestMarketDataDepthAndTicker(t *testing.T) {
+	md := newTestMarketData()
+	depth, err := md.Depth(testSymbol, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Depth{}, depth)
+	ticker, err := md.Ticker(testSymbol)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Ticker{Symbol: testSymbol}, ticker)
+
+	md.Publish(testSymbol, &models.MarketUpdate{
+		Depth: &models.Depth{
+			Bids: []*models.PriceLevel{{Price: 10, Quantity: 1, Count: 1}, {Price: 9, Quantity: 2, Count: 1}},
+			Asks: []*models.PriceLevel{{Price: 11, Quantity: 3, Count: 2}},
+		},
+		LastPrice: 10,
+	})
+	md.Publish(testSymbol, &models.MarketUpdate{Orders: []*models.Order{{ID: 1}}})
+
+	depth, err = md.Depth(testSymbol, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Depth{
+		Bids: []*models.PriceLevel{{Price: 10, Quantity: 1, Count: 1}},
+		Asks: []*models.PriceLevel{{Price: 11, Quantity: 3, Count: 2}},
+	}, depth)
+	ticker, err = md.Ticker(testSymbol)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Ticker{Symbol: testSymbol, BestBid: 10, BestAsk: 11, LastPrice: 10}, ticker)
+
+	_, err = md.Depth("UNKNOWN", 1)
+	assert.Equal(t, ErrUnknownSymbol, err)
+	_, err = md.Ticker("UNKNOWN")
+	assert.Equal(t, ErrUnknownSymbol, err)
+}
+
+func TestMarketDataStatus(t *testing.T) {
+	md := newTestMarketData()
+	auction := &models.MarketStatus{Phase: models.TradingPhaseAuction, IndicativePrice: models.NewDecimalFromInt(10), IndicativeVolume: 3}
+	md.Publish(testSymbol, &models.MarketUpdate{Status: auction})
+
+	c := make(chan []byte, 2)
+	assert.NoError(t, md.Subscribe("status:BTCUSD", c))
+	event := receive(t, c)
+	assert.Equal(t, float64(1), event["sequence"])
+	assert.Equal(t, map[string]interface{}{"phase": "auction", "indicative_price": float64(10), "indicative_volume": float64(3)}, event["data"])
+
+	md.Publish(testSymbol, &models.MarketUpdate{Status: &models.MarketStatus{Phase: models.TradingPhaseAuction, IndicativePrice: models.NewDecimalFromInt(10), IndicativeVolume: 3}})
+	assert.Empty(t, c)
+
+	md.Publish(testSymbol, &models.MarketUpdate{Status: &models.MarketStatus{Phase: models.TradingPhaseContinuous}})
+	event = receive(t, c)
+	assert.Equal(t, models.MarketEventUpdate, event["type"])
+	assert.Equal(t, float64(2), event["sequence"])
+	assert.Equal(t, map[string]interface{}{"phase": "continuous"}, event["data"])
+
+	md.Publish(testSymbol, &models.MarketUpdate{Status: auction})
+	ticker, err := md.Ticker(testSymbol)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Ticker{Symbol: testSymbol, Phase: models.TradingPhaseAuction, IndicativePrice: models.NewDecimalFromInt(10), IndicativeVolume: 3}, ticker)
+}
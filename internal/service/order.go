@@ -4,59 +4,216 @@ import (
 	"context"
 	"dealer/internal/dao"
 	"dealer/internal/models"
-	"dealer/internal/sdk"
+	"time"
 
 	"github.com/goccy/go-json"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"gorm.io/gorm"
 )
 
 type OrderProcessorInterface interface {
 	NewOrder(context.Context, *models.Order) error
-	CancelOrder(context.Context, int64) error
+	CancelOrder(context.Context, int64, string, int64) error
+	AmendOrder(context.Context, int64, *models.Amendment) error
+	ChangePhase(context.Context, string, models.TradingPhase) error
+	ResumeMarket(context.Context, string, *time.Time) error
+	HaltMarket(context.Context, string) error
+	CancelAll(context.Context, *models.MassCancel) ([]*models.MassCancel, error)
 }
 
+// OrderProcessor stores the requests of the clients and leaves their messages
+// in the outbox, which the OutboxRelay publishes to the order queue.
 type OrderProcessor struct {
-	queueName string
-	ch        sdk.AMQPChannel
 	db        *gorm.DB
 	orderDAO  dao.OrderInterface
+	outboxDAO dao.OutboxInterface
+	registry  InstrumentRegistryInterface
+	ledger    LedgerInterface
 }
 
 var _ OrderProcessorInterface = (*OrderProcessor)(nil)
 
-func NewOrderProcessor(ch sdk.AMQPChannel, queueName string, db *gorm.DB, orderDAO dao.OrderInterface) *OrderProcessor {
+func NewOrderProcessor(db *gorm.DB, orderDAO dao.OrderInterface, outboxDAO dao.OutboxInterface, registry InstrumentRegistryInterface, ledger LedgerInterface) *OrderProcessor {
 	return &OrderProcessor{
-		ch:        ch,
-		queueName: queueName,
 		db:        db,
 		orderDAO:  orderDAO,
+		outboxDAO: outboxDAO,
+		registry:  registry,
+		ledger:    ledger,
 	}
 }
 
+// NewOrder stores an order and locks the funds it may spend, which is the
+// budget of a market buy.
 func (p *OrderProcessor) NewOrder(ctx context.Context, order *models.Order) error {
-	if err := p.orderDAO.Insert(ctx, p.db, order); err != nil {
+	instrument, ok := p.registry.Get(order.Symbol)
+	if !ok {
+		return ErrUnknownSymbol
+	}
+
+	tx := p.db.Begin()
+	if err := p.orderDAO.Insert(ctx, tx, order); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	asset, amount := reservation(instrument, order)
+	if err := p.ledger.Lock(ctx, tx, order.UserID, order.ID, asset, amount); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := p.enqueue(ctx, tx, &models.Message{Type: models.MessageTypeNewOrder, Order: order}); err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	data, err := json.Marshal(order)
+	return tx.Commit().Error
+}
+
+// CancelOrder publishes the cancellation of an order of the user.
+func (p *OrderProcessor) CancelOrder(ctx context.Context, userID int64, symbol string, orderID int64) error {
+	order, err := p.orderDAO.Get(ctx, p.db, orderID)
 	if err != nil {
 		return err
 	}
 
-	return p.ch.PublishWithContext(ctx, "", p.queueName, false, false, amqp.Publishing{ContentType: "application/json", Body: data})
+	if order == nil || order.UserID != userID || order.Symbol != symbol {
+		return ErrOrderNotFound
+	}
+
+	if isClosed(order) {
+		return ErrOrderClosed
+	}
+
+	return p.enqueue(ctx, p.db, &models.Message{
+		Type:  models.MessageTypeCancelOrder,
+		Order: &models.Order{ID: orderID, Symbol: symbol, IsCancel: true},
+	})
 }
 
-func (p *OrderProcessor) CancelOrder(ctx context.Context, orderID int64) error {
-	order := &models.Order{ID: orderID, IsCancel: true}
-	if err := p.orderDAO.Update(ctx, p.db, order); err != nil {
+// AmendOrder checks the amendment against the stored order of the user and
+// publishes it. A zero price or quantity keeps the current value of the order.
+func (p *OrderProcessor) AmendOrder(ctx context.Context, userID int64, amendment *models.Amendment) error {
+	order, err := p.orderDAO.Get(ctx, p.db, amendment.OrderID)
+	if err != nil {
 		return err
 	}
 
-	data, err := json.Marshal(order)
+	if order == nil || order.UserID != userID || order.Symbol != amendment.Symbol {
+		return ErrOrderNotFound
+	}
+
+	if isClosed(order) {
+		return ErrOrderClosed
+	}
+
+	if amendment.Price == 0 {
+		amendment.Price = order.Price
+	}
+	if amendment.Quantity == 0 {
+		amendment.Quantity = order.Quantity
+	}
+
+	if amendment.Price != order.Price && order.MatchPriceType() != models.PriceTypeLimit {
+		return ErrPriceNotAmendable
+	}
+
+	if amendment.Quantity <= order.Quantity-order.RemainQuantity {
+		return ErrQuantityFilled
+	}
+
+	return p.enqueue(ctx, p.db, &models.Message{Type: models.MessageTypeAmendOrder, Amendment: amendment})
+}
+
+// ChangePhase publishes the change of the trading phase of a market, which the
+// dealer applies after the orders committed before it.
+func (p *OrderProcessor) ChangePhase(ctx context.Context, symbol string, phase models.TradingPhase) error {
+	if _, ok := p.registry.Get(symbol); !ok {
+		return ErrUnknownSymbol
+	}
+
+	if !phase.IsValid() {
+		return ErrInvalidPhase
+	}
+
+	return p.enqueue(ctx, p.db, &models.Message{
+		Type:   models.MessageTypeChangePhase,
+		Market: &models.MarketState{Symbol: symbol, Phase: phase},
+	})
+}
+
+// ResumeMarket publishes the end of the halt of a market, which resumes the
+// halt whose cooldown ends at resumeAt, or any halt when resumeAt is nil.
+func (p *OrderProcessor) ResumeMarket(ctx context.Context, symbol string, resumeAt *time.Time) error {
+	if _, ok := p.registry.Get(symbol); !ok {
+		return ErrUnknownSymbol
+	}
+
+	return p.enqueue(ctx, p.db, &models.Message{
+		Type:   models.MessageTypeResumeMarket,
+		Market: &models.MarketState{Symbol: symbol, ResumeAt: resumeAt},
+	})
+}
+
+// HaltMarket publishes the halt of a market by an operator, which lasts until
+// the market is resumed.
+func (p *OrderProcessor) HaltMarket(ctx context.Context, symbol string) error {
+	if _, ok := p.registry.Get(symbol); !ok {
+		return ErrUnknownSymbol
+	}
+
+	return p.enqueue(ctx, p.db, &models.Message{
+		Type:   models.MessageTypeHaltMarket,
+		Market: &models.MarketState{Symbol: symbol},
+	})
+}
+
+// CancelAll publishes the cancellation of the resting orders selected by the
+// mass cancel, one for every market when its symbol is empty, and returns the
+// published mass cancels.
+func (p *OrderProcessor) CancelAll(ctx context.Context, massCancel *models.MassCancel) ([]*models.MassCancel, error) {
+	if massCancel.OrderType != 0 && massCancel.OrderType != models.OrderTypeBuy && massCancel.OrderType != models.OrderTypeSell {
+		return nil, ErrInvalidOrderType
+	}
+
+	var symbols []string
+	if massCancel.Symbol == "" {
+		for _, instrument := range p.registry.List() {
+			symbols = append(symbols, instrument.Symbol)
+		}
+	} else if _, ok := p.registry.Get(massCancel.Symbol); ok {
+		symbols = append(symbols, massCancel.Symbol)
+	} else {
+		return nil, ErrUnknownSymbol
+	}
+
+	massCancels := make([]*models.MassCancel, 0, len(symbols))
+	tx := p.db.Begin()
+	for _, symbol := range symbols {
+		m := &models.MassCancel{Symbol: symbol, OrderType: massCancel.OrderType, UserID: massCancel.UserID}
+		if err := p.enqueue(ctx, tx, &models.Message{Type: models.MessageTypeCancelAll, MassCancel: m}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		massCancels = append(massCancels, m)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return massCancels, nil
+}
+
+func (p *OrderProcessor) enqueue(ctx context.Context, tx *gorm.DB, message *models.Message) error {
+	data, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
 
-	return p.ch.PublishWithContext(ctx, "", p.queueName, false, false, amqp.Publishing{ContentType: "application/json", Body: data})
+	return p.outboxDAO.Insert(ctx, tx, &models.Outbox{Payload: data})
+}
+
+func isClosed(order *models.Order) bool {
+	return order.Status.IsFinal() || order.IsCancel || order.RemainQuantity == 0
 }
@@ -2,47 +2,43 @@ package service
 
 import (
 	"dealer/internal/models"
-	"math"
 	"sort"
 )
 
-const (
-	TOLERANCE = 0.000001
-)
-
 type Comparator func(*models.Order, *models.Order) bool
 
 var BuyComparator Comparator = func(o1, o2 *models.Order) bool {
-	if int(o1.PriceType) != int(o2.PriceType) {
-		return int(o1.PriceType) < int(o2.PriceType)
+	if o1.MatchPriceType() != o2.MatchPriceType() {
+		return o1.MatchPriceType() < o2.MatchPriceType()
 	}
 
-	diff := o1.Price - o2.Price
-	if o1.PriceType == models.PriceTypeLimit && math.Abs(diff) > TOLERANCE {
-		return diff < 0
+	if o1.MatchPriceType() == models.PriceTypeLimit && o1.Price != o2.Price {
+		return o1.Price < o2.Price
 	}
 
-	return o1.ID > o2.ID
+	return o2.Before(o1)
 }
 
 var SellComparator Comparator = func(o1, o2 *models.Order) bool {
-	if int(o1.PriceType) != int(o2.PriceType) {
-		return int(o1.PriceType) < int(o2.PriceType)
+	if o1.MatchPriceType() != o2.MatchPriceType() {
+		return o1.MatchPriceType() < o2.MatchPriceType()
 	}
 
-	diff := o1.Price - o2.Price
-	if o1.PriceType == models.PriceTypeLimit && math.Abs(diff) > TOLERANCE {
-		return diff > 0
+	if o1.MatchPriceType() == models.PriceTypeLimit && o1.Price != o2.Price {
+		return o1.Price > o2.Price
 	}
 
-	return o1.ID > o2.ID
+	return o2.Before(o1)
 }
 
 type OrderBookInterface interface {
 	AddOrder(*models.Order)
 	Peek() *models.Order
+	Get(int64) *models.Order
 	Dequeue() *models.Order
 	RemoveOrder(int64)
+	Range(func(*models.Order) bool)
+	Depth(int) []*models.PriceLevel
 }
 
 type OrderBook struct {
@@ -74,6 +70,16 @@ func (book *OrderBook) Peek() *models.Order {
 	return book.orders[length-1]
 }
 
+func (book *OrderBook) Get(orderID int64) *models.Order {
+	for _, order := range book.orders {
+		if order.ID == orderID {
+			return order
+		}
+	}
+
+	return nil
+}
+
 func (book *OrderBook) Dequeue() *models.Order {
 	length := len(book.orders)
 	if length == 0 {
@@ -94,6 +100,38 @@ func (book *OrderBook) RemoveOrder(orderID int64) {
 	}
 }
 
+func (book *OrderBook) Range(fn func(*models.Order) bool) {
+	for i := len(book.orders) - 1; i >= 0; i-- {
+		if !fn(book.orders[i]) {
+			return
+		}
+	}
+}
+
+func (book *OrderBook) Depth(limit int) []*models.PriceLevel {
+	var levels []*models.PriceLevel
+	book.Range(func(order *models.Order) bool {
+		if order.MatchPriceType() != models.PriceTypeLimit {
+			return true
+		}
+
+		if n := len(levels); n != 0 && levels[n-1].Price == order.Price {
+			levels[n-1].Quantity += order.VisibleQuantity()
+			levels[n-1].Count++
+			return true
+		}
+
+		if len(levels) == limit {
+			return false
+		}
+
+		levels = append(levels, &models.PriceLevel{Price: order.Price, Quantity: order.VisibleQuantity(), Count: 1})
+		return true
+	})
+
+	return levels
+}
+
 func (book *OrderBook) remove(index int) {
 	book.orders = append(book.orders[:index], book.orders[index+1:]...)
 }
@@ -7,420 +7,296 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// orderBooks are the OrderBookInterface implementations the book tests run
+// against, built for the side of the given order type.
+var orderBooks = []struct {
+	name    string
+	newBook func(models.OrderType) OrderBookInterface
+}{
+	{
+		name: "OrderBook",
+		newBook: func(orderType models.OrderType) OrderBookInterface {
+			if orderType == models.OrderTypeBuy {
+				return NewOrderBook(BuyComparator)
+			}
+			return NewOrderBook(SellComparator)
+		},
+	},
+	{
+		name: "PriceLevelOrderBook",
+		newBook: func(orderType models.OrderType) OrderBookInterface {
+			if orderType == models.OrderTypeBuy {
+				return NewPriceLevelOrderBook(BuyPriceComparator)
+			}
+			return NewPriceLevelOrderBook(SellPriceComparator)
+		},
+	},
+}
+
+func drain(book OrderBookInterface) []int64 {
+	var ids []int64
+	for order := book.Dequeue(); order != nil; order = book.Dequeue() {
+		ids = append(ids, order.ID)
+	}
+
+	return ids
+}
+
 func TestAddOrder(t *testing.T) {
 	tests := []struct {
 		name      string
-		orderBook *OrderBook
+		orderType models.OrderType
 		orders    []*models.Order
-		expected  []*models.Order
+		expected  []int64
 	}{
 		{
 			name:      "Sell limit price lower price first",
-			orderBook: NewOrderBook(SellComparator),
+			orderType: models.OrderTypeSell,
 			orders: []*models.Order{
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     3,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     3,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 3},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 1},
 			},
+			expected: []int64{3, 1, 2},
 		},
 		{
 			name:      "Sell market price market price first",
-			orderBook: NewOrderBook(SellComparator),
+			orderType: models.OrderTypeSell,
 			orders: []*models.Order{
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-				},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 2, PriceType: models.PriceTypeMarket},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 1},
 			},
+			expected: []int64{2, 3, 1},
 		},
 		{
 			name:      "Sell limit price eariler first",
-			orderBook: NewOrderBook(SellComparator),
+			orderType: models.OrderTypeSell,
 			orders: []*models.Order{
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 2},
 			},
+			expected: []int64{1, 2, 3},
 		},
 		{
 			name:      "Sell market price eariler first",
-			orderBook: NewOrderBook(SellComparator),
+			orderType: models.OrderTypeSell,
 			orders: []*models.Order{
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        3,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
+				{ID: 2, PriceType: models.PriceTypeMarket, Price: 2},
+				{ID: 1, PriceType: models.PriceTypeMarket, Price: 2},
+				{ID: 3, PriceType: models.PriceTypeMarket, Price: 2},
 			},
+			expected: []int64{1, 2, 3},
 		},
 		{
 			name:      "Buy limit price higher price first",
-			orderBook: NewOrderBook(BuyComparator),
+			orderType: models.OrderTypeBuy,
 			orders: []*models.Order{
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     3,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				}, {
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     3,
-				},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 3},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 1},
 			},
+			expected: []int64{2, 1, 3},
 		},
 		{
 			name:      "Buy limit price eariler first",
-			orderBook: NewOrderBook(BuyComparator),
+			orderType: models.OrderTypeBuy,
 			orders: []*models.Order{
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 2},
 			},
+			expected: []int64{1, 2, 3},
 		},
 		{
 			name:      "Buy market price eariler first",
-			orderBook: NewOrderBook(BuyComparator),
+			orderType: models.OrderTypeBuy,
 			orders: []*models.Order{
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        3,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeMarket,
-					Price:     2,
-				},
+				{ID: 2, PriceType: models.PriceTypeMarket, Price: 2},
+				{ID: 1, PriceType: models.PriceTypeMarket, Price: 2},
+				{ID: 3, PriceType: models.PriceTypeMarket, Price: 2},
 			},
+			expected: []int64{1, 2, 3},
 		},
 		{
 			name:      "Buy market price market price first",
-			orderBook: NewOrderBook(BuyComparator),
+			orderType: models.OrderTypeBuy,
 			orders: []*models.Order{
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-				},
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-			},
-			expected: []*models.Order{
-				{
-					ID:        3,
-					PriceType: models.PriceTypeLimit,
-					Price:     1,
-				},
-				{
-					ID:        1,
-					PriceType: models.PriceTypeLimit,
-					Price:     2,
-				},
-				{
-					ID:        2,
-					PriceType: models.PriceTypeMarket,
-				},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 2, PriceType: models.PriceTypeMarket},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 1},
 			},
+			expected: []int64{2, 1, 3},
 		},
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			for _, order := range test.orders {
-				test.orderBook.AddOrder(order)
-			}
+	for _, orderBook := range orderBooks {
+		for _, test := range tests {
+			t.Run(orderBook.name+" "+test.name, func(t *testing.T) {
+				book := orderBook.newBook(test.orderType)
+				for _, order := range test.orders {
+					book.AddOrder(order)
+				}
 
-			assert.Equal(t, test.expected, test.orderBook.orders)
-		})
+				assert.Equal(t, test.expected, drain(book))
+			})
+		}
 	}
 }
 
 func TestPeek(t *testing.T) {
 	tests := []struct {
-		name      string
-		orderBook *OrderBook
-		expected  *models.Order
+		name     string
+		orders   []*models.Order
+		expected *models.Order
 	}{
 		{
 			name: "Peek order",
-			orderBook: &OrderBook{
-				orders: []*models.Order{
-					{ID: 1},
-					{ID: 2},
-				},
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 1},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
 			},
-			expected: &models.Order{ID: 2},
+			expected: &models.Order{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
 		},
 		{
-			name:      "Peek order nil",
-			orderBook: &OrderBook{},
-			expected:  nil,
+			name:     "Peek order nil",
+			expected: nil,
 		},
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			actual := test.orderBook.Peek()
-			assert.Equal(t, test.expected, actual)
-		})
+	for _, orderBook := range orderBooks {
+		for _, test := range tests {
+			t.Run(orderBook.name+" "+test.name, func(t *testing.T) {
+				book := orderBook.newBook(models.OrderTypeBuy)
+				for _, order := range test.orders {
+					book.AddOrder(order)
+				}
+
+				assert.Equal(t, test.expected, book.Peek())
+				assert.Len(t, drain(book), len(test.orders))
+			})
+		}
 	}
 }
 
 func TestDequeue(t *testing.T) {
 	tests := []struct {
-		name      string
-		orderBook *OrderBook
-		length    int
-		expected  *models.Order
+		name     string
+		orders   []*models.Order
+		expected *models.Order
+		rest     []int64
 	}{
 		{
 			name: "Dequeue order",
-			orderBook: &OrderBook{
-				orders: []*models.Order{
-					{ID: 1},
-					{ID: 2},
-				},
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 1},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			expected: &models.Order{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			rest:     []int64{1},
+		},
+		{
+			name: "Dequeue order same price",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
 			},
-			length:   1,
-			expected: &models.Order{ID: 2},
+			expected: &models.Order{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+			rest:     []int64{2},
 		},
 		{
-			name:      "Dequeue order nil",
-			orderBook: &OrderBook{},
-			length:    0,
-			expected:  nil,
+			name:     "Dequeue order nil",
+			expected: nil,
+			rest:     nil,
 		},
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			actual := test.orderBook.Dequeue()
-			assert.Equal(t, test.expected, actual)
-			assert.Len(t, test.orderBook.orders, test.length)
-		})
+	for _, orderBook := range orderBooks {
+		for _, test := range tests {
+			t.Run(orderBook.name+" "+test.name, func(t *testing.T) {
+				book := orderBook.newBook(models.OrderTypeBuy)
+				for _, order := range test.orders {
+					book.AddOrder(order)
+				}
+
+				assert.Equal(t, test.expected, book.Dequeue())
+				assert.Equal(t, test.rest, drain(book))
+			})
+		}
 	}
 }
 
 func TestRemoveOrder(t *testing.T) {
 	tests := []struct {
-		name      string
-		orderBook *OrderBook
-		expected  []*models.Order
+		name     string
+		orders   []*models.Order
+		expected []int64
 	}{
 		{
-			name: "Remove order",
-			orderBook: &OrderBook{
-				orders: []*models.Order{
-					{ID: 1},
-					{ID: 2},
-				},
+			name: "Remove limit order",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 1},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
 			},
-			expected: []*models.Order{
-				{ID: 2},
+			expected: []int64{2},
+		},
+		{
+			name: "Remove market order",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeMarket},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			expected: []int64{2},
+		},
+		{
+			name: "Remove order in the middle of price",
+			orders: []*models.Order{
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 2},
 			},
+			expected: []int64{2, 3},
 		},
 		{
-			name:      "Remove no order",
-			orderBook: &OrderBook{},
-			expected:  nil,
+			name:     "Remove no order",
+			expected: nil,
 		},
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			test.orderBook.RemoveOrder(1)
-			assert.Equal(t, test.expected, test.orderBook.orders)
+	for _, orderBook := range orderBooks {
+		for _, test := range tests {
+			t.Run(orderBook.name+" "+test.name, func(t *testing.T) {
+				book := orderBook.newBook(models.OrderTypeBuy)
+				for _, order := range test.orders {
+					book.AddOrder(order)
+				}
+
+				book.RemoveOrder(1)
+				assert.Equal(t, test.expected, drain(book))
+			})
+		}
+	}
+}
+
+func TestDepth(t *testing.T) {
+	for _, orderBook := range orderBooks {
+		t.Run(orderBook.name, func(t *testing.T) {
+			book := orderBook.newBook(models.OrderTypeSell)
+			for _, order := range []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeMarket, RemainQuantity: 9},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 3, RemainQuantity: 1},
+				{ID: 3, PriceType: models.PriceTypeLimit, Price: 1, RemainQuantity: 2},
+				{ID: 4, PriceType: models.PriceTypeLimit, Price: 2, RemainQuantity: 3},
+				{ID: 5, PriceType: models.PriceTypeLimit, Price: 1, RemainQuantity: 4},
+				{ID: 6, PriceType: models.PriceTypeLimit, Price: 2, Quantity: 10, RemainQuantity: 10, DisplayQuantity: 4},
+			} {
+				book.AddOrder(order)
+			}
+
+			assert.Equal(t, []*models.PriceLevel{{Price: 1, Quantity: 6, Count: 2}, {Price: 2, Quantity: 7, Count: 2}}, book.Depth(2))
+			assert.Equal(t, []*models.PriceLevel{{Price: 1, Quantity: 6, Count: 2}, {Price: 2, Quantity: 7, Count: 2}, {Price: 3, Quantity: 1, Count: 1}}, book.Depth(10))
+			assert.Nil(t, book.Depth(0))
 		})
 	}
 }
@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
 	"gorm.io/driver/mysql"
@@ -16,19 +16,20 @@ import (
 	"gorm.io/gorm/schema"
 
 	mockDAO "dealer/internal/mock/dao"
-	mockSDK "dealer/internal/mock/sdk"
+	mockService "dealer/internal/mock/service"
 	"dealer/internal/models"
 )
 
 type OrderTestSuite struct {
 	suite.Suite
-	ctrl         *gomock.Controller
-	db           *sql.DB
-	mockDB       sqlmock.Sqlmock
-	mockGormDB   *gorm.DB
-	mockOrderDAO *mockDAO.MockOrderInterface
-	mockChannel  *mockSDK.MockAMQPChannel
-	svc          *OrderProcessor
+	ctrl          *gomock.Controller
+	db            *sql.DB
+	mockDB        sqlmock.Sqlmock
+	mockGormDB    *gorm.DB
+	mockOrderDAO  *mockDAO.MockOrderInterface
+	mockOutboxDAO *mockDAO.MockOutboxInterface
+	mockLedger    *mockService.MockLedgerInterface
+	svc           *OrderProcessor
 }
 
 func (t *OrderTestSuite) SetupTest() {
@@ -53,8 +54,10 @@ func (t *OrderTestSuite) SetupTest() {
 	}
 
 	t.mockOrderDAO = mockDAO.NewMockOrderInterface(t.ctrl)
-	t.mockChannel = mockSDK.NewMockAMQPChannel(t.ctrl)
-	t.svc = NewOrderProcessor(t.mockChannel, "name", t.mockGormDB, t.mockOrderDAO)
+	t.mockOutboxDAO = mockDAO.NewMockOutboxInterface(t.ctrl)
+	t.mockLedger = mockService.NewMockLedgerInterface(t.ctrl)
+	registry := NewInstrumentRegistry([]*models.Instrument{{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD"}})
+	t.svc = NewOrderProcessor(t.mockGormDB, t.mockOrderDAO, t.mockOutboxDAO, registry, t.mockLedger)
 }
 
 func (t *OrderTestSuite) TearDownTest() {
@@ -67,53 +70,169 @@ func TestOrderTestSuite(t *testing.T) {
 }
 
 func (t *OrderTestSuite) TestNewOrder() {
-	order := &models.Order{
-		ID:             1,
-		OrderType:      models.OrderTypeBuy,
-		Quantity:       10,
-		RemainQuantity: 10,
-		PriceType:      models.PriceTypeLimit,
-		Price:          10,
-		IsCancel:       false,
+	newOrder := func() *models.Order {
+		return &models.Order{
+			ID:             1,
+			UserID:         1,
+			Symbol:         testSymbol,
+			OrderType:      models.OrderTypeBuy,
+			Quantity:       10,
+			RemainQuantity: 10,
+			PriceType:      models.PriceTypeLimit,
+			Price:          10,
+			IsCancel:       false,
+		}
 	}
 
 	tests := []struct {
 		name     string
-		fn       func()
+		order    func() *models.Order
+		fn       func(*models.Order)
+		expected error
 		hasError bool
 	}{
 		{
-			name: "New order normal",
-			fn: func() {
+			name:  "New order normal",
+			order: newOrder,
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
 				t.mockOrderDAO.EXPECT().
-					Insert(context.Background(), t.mockGormDB, order).
+					Insert(context.Background(), gomock.Any(), order).
 					Return(nil)
-				data, _ := json.Marshal(order)
-				t.mockChannel.EXPECT().
-					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{ContentType: "application/json", Body: data}).
+				t.mockLedger.EXPECT().
+					Lock(context.Background(), gomock.Any(), int64(1), int64(1), "USD", models.Decimal(100)).
 					Return(nil)
+				data, _ := json.Marshal(&models.Message{Type: models.MessageTypeNewOrder, Order: order})
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), &models.Outbox{Payload: data}).
+					Return(nil)
+				t.mockDB.ExpectCommit()
 			},
 			hasError: false,
 		},
 		{
-			name: "New order insert database failed",
-			fn: func() {
+			name: "New sell order locks base asset",
+			order: func() *models.Order {
+				order := newOrder()
+				order.OrderType = models.OrderTypeSell
+				return order
+			},
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
 				t.mockOrderDAO.EXPECT().
-					Insert(context.Background(), t.mockGormDB, order).
+					Insert(context.Background(), gomock.Any(), order).
+					Return(nil)
+				t.mockLedger.EXPECT().
+					Lock(context.Background(), gomock.Any(), int64(1), int64(1), "BTC", models.NewDecimalFromInt(10)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "New market buy order locks budget",
+			order: func() *models.Order {
+				order := newOrder()
+				order.PriceType = models.PriceTypeMarket
+				order.Price = 0
+				order.Budget = 500
+				return order
+			},
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), order).
+					Return(nil)
+				t.mockLedger.EXPECT().
+					Lock(context.Background(), gomock.Any(), int64(1), int64(1), "USD", models.Decimal(500)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			hasError: false,
+		},
+		{
+			name: "New market buy order without funds",
+			order: func() *models.Order {
+				order := newOrder()
+				order.PriceType = models.PriceTypeMarket
+				order.Price = 0
+				order.Budget = 500
+				return order
+			},
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), order).
+					Return(nil)
+				t.mockLedger.EXPECT().
+					Lock(context.Background(), gomock.Any(), int64(1), int64(1), "USD", models.Decimal(500)).
+					Return(ErrInsufficientFunds)
+				t.mockDB.ExpectRollback()
+			},
+			expected: ErrInsufficientFunds,
+			hasError: true,
+		},
+		{
+			name: "New order of unknown symbol",
+			order: func() *models.Order {
+				order := newOrder()
+				order.Symbol = "UNKNOWN"
+				return order
+			},
+			fn:       func(*models.Order) {},
+			expected: ErrUnknownSymbol,
+			hasError: true,
+		},
+		{
+			name:  "New order insert database failed",
+			order: newOrder,
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), order).
 					Return(errors.New(""))
+				t.mockDB.ExpectRollback()
 			},
 			hasError: true,
 		},
 		{
-			name: "New order publish message queue failed",
-			fn: func() {
+			name:  "New order insufficient funds",
+			order: newOrder,
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
+				t.mockOrderDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), order).
+					Return(nil)
+				t.mockLedger.EXPECT().
+					Lock(context.Background(), gomock.Any(), int64(1), int64(1), "USD", models.Decimal(100)).
+					Return(ErrInsufficientFunds)
+				t.mockDB.ExpectRollback()
+			},
+			expected: ErrInsufficientFunds,
+			hasError: true,
+		},
+		{
+			name:  "New order insert outbox failed",
+			order: newOrder,
+			fn: func(order *models.Order) {
+				t.mockDB.ExpectBegin()
 				t.mockOrderDAO.EXPECT().
-					Insert(context.Background(), t.mockGormDB, order).
+					Insert(context.Background(), gomock.Any(), order).
 					Return(nil)
-				data, _ := json.Marshal(order)
-				t.mockChannel.EXPECT().
-					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{ContentType: "application/json", Body: data}).
+				t.mockLedger.EXPECT().
+					Lock(context.Background(), gomock.Any(), int64(1), int64(1), "USD", models.Decimal(100)).
+					Return(nil)
+				data, _ := json.Marshal(&models.Message{Type: models.MessageTypeNewOrder, Order: order})
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), gomock.Any(), &models.Outbox{Payload: data}).
 					Return(errors.New(""))
+				t.mockDB.ExpectRollback()
 			},
 			hasError: true,
 		},
@@ -121,15 +240,23 @@ func (t *OrderTestSuite) TestNewOrder() {
 
 	for _, test := range tests {
 		t.Run(test.name, func() {
-			test.fn()
+			order := test.order()
+			test.fn(order)
 			err := t.svc.NewOrder(context.Background(), order)
 			t.Equal(test.hasError, err != nil)
+			if test.expected != nil {
+				t.Equal(test.expected, err)
+			}
+			t.NoError(t.mockDB.ExpectationsWereMet())
 		})
 	}
 }
 
 func (t *OrderTestSuite) TestCancelOrder() {
-	order := &models.Order{ID: 1, IsCancel: true}
+	message := &models.Message{
+		Type:  models.MessageTypeCancelOrder,
+		Order: &models.Order{ID: 1, Symbol: "BTCUSD", IsCancel: true},
+	}
 	tests := []struct {
 		name     string
 		fn       func()
@@ -139,33 +266,69 @@ func (t *OrderTestSuite) TestCancelOrder() {
 			name: "Cancel order normal",
 			fn: func() {
 				t.mockOrderDAO.EXPECT().
-					Update(context.Background(), t.mockGormDB, order).
-					Return(nil)
-				data, _ := json.Marshal(order)
-				t.mockChannel.EXPECT().
-					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{ContentType: "application/json", Body: data}).
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD", Quantity: 1, RemainQuantity: 1, Status: models.OrderStatusNew}, nil)
+				data, _ := json.Marshal(message)
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: data}).
 					Return(nil)
 			},
 			hasError: false,
 		},
 		{
-			name: "Cancel order update database failed",
+			name: "Cancel order get order failed",
 			fn: func() {
 				t.mockOrderDAO.EXPECT().
-					Update(context.Background(), t.mockGormDB, order).
-					Return(errors.New(""))
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(nil, errors.New(""))
 			},
 			hasError: true,
 		},
 		{
-			name: "Cancel order send message queue failed",
+			name: "Cancel order not found",
 			fn: func() {
 				t.mockOrderDAO.EXPECT().
-					Update(context.Background(), t.mockGormDB, order).
-					Return(nil)
-				data, _ := json.Marshal(order)
-				t.mockChannel.EXPECT().
-					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{ContentType: "application/json", Body: data}).
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(nil, nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Cancel order of another user",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 8, Symbol: "BTCUSD", Quantity: 1, RemainQuantity: 1, Status: models.OrderStatusNew}, nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Cancel order symbol not match",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, Symbol: "ETHUSD"}, nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Cancel order already filled",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD", Quantity: 1, Status: models.OrderStatusFilled}, nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Cancel order insert outbox failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD", Quantity: 1, RemainQuantity: 1, Status: models.OrderStatusNew}, nil)
+				data, _ := json.Marshal(message)
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: data}).
 					Return(errors.New(""))
 			},
 			hasError: true,
@@ -175,8 +338,218 @@ func (t *OrderTestSuite) TestCancelOrder() {
 	for _, test := range tests {
 		t.Run(test.name, func() {
 			test.fn()
-			err := t.svc.CancelOrder(context.Background(), 1)
+			err := t.svc.CancelOrder(context.Background(), 7, "BTCUSD", 1)
 			t.Equal(test.hasError, err != nil)
 		})
 	}
 }
+
+func (t *OrderTestSuite) TestAmendOrder() {
+	openOrder := &models.Order{
+		ID:             1,
+		UserID:         7,
+		Symbol:         "BTCUSD",
+		Quantity:       10,
+		RemainQuantity: 6,
+		PriceType:      models.PriceTypeLimit,
+		Price:          10,
+	}
+	tests := []struct {
+		name      string
+		amendment *models.Amendment
+		fn        func()
+		expected  error
+	}{
+		{
+			name:      "Amend order price",
+			amendment: &models.Amendment{OrderID: 1, Symbol: "BTCUSD", Price: 11},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(openOrder, nil)
+				data, _ := json.Marshal(&models.Message{
+					Type:      models.MessageTypeAmendOrder,
+					Amendment: &models.Amendment{OrderID: 1, Symbol: "BTCUSD", Price: 11, Quantity: 10},
+				})
+				t.mockOutboxDAO.EXPECT().
+					Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: data}).
+					Return(nil)
+			},
+			expected: nil,
+		},
+		{
+			name:      "Amend order not found",
+			amendment: &models.Amendment{OrderID: 1, Symbol: "ETHUSD", Quantity: 5},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(openOrder, nil)
+			},
+			expected: ErrOrderNotFound,
+		},
+		{
+			name:      "Amend order of another user",
+			amendment: &models.Amendment{OrderID: 1, Symbol: "BTCUSD", Quantity: 5},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 8, Symbol: "BTCUSD", Quantity: 10, RemainQuantity: 10}, nil)
+			},
+			expected: ErrOrderNotFound,
+		},
+		{
+			name:      "Amend order closed",
+			amendment: &models.Amendment{OrderID: 1, Symbol: "BTCUSD", Quantity: 5},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD", Quantity: 10, IsCancel: true}, nil)
+			},
+			expected: ErrOrderClosed,
+		},
+		{
+			name:      "Amend market order price",
+			amendment: &models.Amendment{OrderID: 1, Symbol: "BTCUSD", Price: 11},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD", Quantity: 10, RemainQuantity: 10, PriceType: models.PriceTypeMarket}, nil)
+			},
+			expected: ErrPriceNotAmendable,
+		},
+		{
+			name:      "Amend order quantity below filled",
+			amendment: &models.Amendment{OrderID: 1, Symbol: "BTCUSD", Quantity: 4},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(openOrder, nil)
+			},
+			expected: ErrQuantityFilled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.AmendOrder(context.Background(), 7, test.amendment)
+			t.Equal(test.expected, err)
+		})
+	}
+}
+
+func (t *OrderTestSuite) TestChangePhase() {
+	data, _ := json.Marshal(&models.Message{
+		Type:   models.MessageTypeChangePhase,
+		Market: &models.MarketState{Symbol: testSymbol, Phase: models.TradingPhaseAuction},
+	})
+	t.mockOutboxDAO.EXPECT().
+		Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: data}).
+		Return(nil)
+
+	t.NoError(t.svc.ChangePhase(context.Background(), testSymbol, models.TradingPhaseAuction))
+	t.Equal(ErrUnknownSymbol, t.svc.ChangePhase(context.Background(), "UNKNOWN", models.TradingPhaseAuction))
+	t.Equal(ErrInvalidPhase, t.svc.ChangePhase(context.Background(), testSymbol, "lunch"))
+}
+
+func (t *OrderTestSuite) TestResumeMarket() {
+	resumeAt := time.Date(2022, 8, 1, 0, 5, 0, 0, time.UTC)
+	data, _ := json.Marshal(&models.Message{
+		Type:   models.MessageTypeResumeMarket,
+		Market: &models.MarketState{Symbol: testSymbol, ResumeAt: &resumeAt},
+	})
+	t.mockOutboxDAO.EXPECT().
+		Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: data}).
+		Return(nil)
+
+	t.NoError(t.svc.ResumeMarket(context.Background(), testSymbol, &resumeAt))
+	t.Equal(ErrUnknownSymbol, t.svc.ResumeMarket(context.Background(), "UNKNOWN", nil))
+}
+
+func (t *OrderTestSuite) TestHaltMarket() {
+	data, _ := json.Marshal(&models.Message{
+		Type:   models.MessageTypeHaltMarket,
+		Market: &models.MarketState{Symbol: testSymbol},
+	})
+	t.mockOutboxDAO.EXPECT().
+		Insert(context.Background(), t.mockGormDB, &models.Outbox{Payload: data}).
+		Return(nil)
+
+	t.NoError(t.svc.HaltMarket(context.Background(), testSymbol))
+	t.Equal(ErrUnknownSymbol, t.svc.HaltMarket(context.Background(), "UNKNOWN"))
+}
+
+func (t *OrderTestSuite) TestCancelAll() {
+	errSQL := errors.New("")
+	t.svc.registry = NewInstrumentRegistry([]*models.Instrument{
+		{Symbol: testSymbol, BaseAsset: "BTC", QuoteAsset: "USD"},
+		{Symbol: "ETHUSD", BaseAsset: "ETH", QuoteAsset: "USD"},
+	})
+	payload := func(massCancel *models.MassCancel) *models.Outbox {
+		data, _ := json.Marshal(&models.Message{Type: models.MessageTypeCancelAll, MassCancel: massCancel})
+		return &models.Outbox{Payload: data}
+	}
+
+	tests := []struct {
+		name       string
+		massCancel *models.MassCancel
+		fn         func()
+		expected   []*models.MassCancel
+		err        error
+	}{
+		{
+			name:       "Cancel all of one market",
+			massCancel: &models.MassCancel{Symbol: testSymbol, OrderType: models.OrderTypeSell},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().Insert(context.Background(), gomock.Any(), payload(&models.MassCancel{Symbol: testSymbol, OrderType: models.OrderTypeSell})).Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			expected: []*models.MassCancel{{Symbol: testSymbol, OrderType: models.OrderTypeSell}},
+		},
+		{
+			name:       "Cancel all of every market",
+			massCancel: &models.MassCancel{UserID: 11},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().Insert(context.Background(), gomock.Any(), payload(&models.MassCancel{Symbol: testSymbol, UserID: 11})).Return(nil)
+				t.mockOutboxDAO.EXPECT().Insert(context.Background(), gomock.Any(), payload(&models.MassCancel{Symbol: "ETHUSD", UserID: 11})).Return(nil)
+				t.mockDB.ExpectCommit()
+			},
+			expected: []*models.MassCancel{{Symbol: testSymbol, UserID: 11}, {Symbol: "ETHUSD", UserID: 11}},
+		},
+		{
+			name:       "Cancel all enqueue failed",
+			massCancel: &models.MassCancel{},
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(nil)
+				t.mockOutboxDAO.EXPECT().Insert(context.Background(), gomock.Any(), gomock.Any()).Return(errSQL)
+				t.mockDB.ExpectRollback()
+			},
+			err: errSQL,
+		},
+		{
+			name:       "Cancel all unknown symbol",
+			massCancel: &models.MassCancel{Symbol: "UNKNOWN"},
+			fn:         func() {},
+			err:        ErrUnknownSymbol,
+		},
+		{
+			name:       "Cancel all invalid order type",
+			massCancel: &models.MassCancel{Symbol: testSymbol, OrderType: 3},
+			fn:         func() {},
+			err:        ErrInvalidOrderType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			massCancels, err := t.svc.CancelAll(context.Background(), test.massCancel)
+			t.Equal(test.err, err)
+			t.Equal(test.expected, massCancels)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
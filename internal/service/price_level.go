@@ -0,0 +1,133 @@
+package service
+
+import (
+	"container/list"
+	"dealer/internal/models"
+	"math/rand"
+)
+
+const (
+	skipListMaxHeight   = 32
+	skipListProbability = 0.25
+)
+
+type priceLevel struct {
+	price  models.Decimal
+	orders *list.List
+}
+
+func newPriceLevel(price models.Decimal) *priceLevel {
+	return &priceLevel{
+		price:  price,
+		orders: list.New(),
+	}
+}
+
+// insert keeps the queue ordered by queue position. New orders normally carry
+// the largest position so the walk from the back stops immediately.
+func (level *priceLevel) insert(order *models.Order) *list.Element {
+	return insertByQueuePosition(level.orders, order)
+}
+
+func insertByQueuePosition(queue *list.List, order *models.Order) *list.Element {
+	for e := queue.Back(); e != nil; e = e.Prev() {
+		if e.Value.(*models.Order).Before(order) {
+			return queue.InsertAfter(order, e)
+		}
+	}
+
+	return queue.PushFront(order)
+}
+
+type skipListNode struct {
+	level *priceLevel
+	next  []*skipListNode
+}
+
+// skipList keeps price levels ordered by the given PriceComparator, so the
+// first node is always the best price of the book.
+type skipList struct {
+	head       *skipListNode
+	height     int
+	comparator PriceComparator
+	random     *rand.Rand
+}
+
+func newSkipList(comparator PriceComparator) *skipList {
+	return &skipList{
+		head:       &skipListNode{next: make([]*skipListNode, skipListMaxHeight)},
+		height:     1,
+		comparator: comparator,
+		random:     rand.New(rand.NewSource(1)),
+	}
+}
+
+func (s *skipList) randomHeight() int {
+	height := 1
+	for height < skipListMaxHeight && s.random.Float64() < skipListProbability {
+		height++
+	}
+
+	return height
+}
+
+func (s *skipList) findPredecessors(price models.Decimal) []*skipListNode {
+	predecessors := make([]*skipListNode, skipListMaxHeight)
+	node := s.head
+	for i := s.height - 1; i >= 0; i-- {
+		for node.next[i] != nil && s.comparator(node.next[i].level.price, price) {
+			node = node.next[i]
+		}
+		predecessors[i] = node
+	}
+
+	return predecessors
+}
+
+func (s *skipList) getOrInsert(price models.Decimal) *priceLevel {
+	predecessors := s.findPredecessors(price)
+	if node := predecessors[0].next[0]; node != nil && node.level.price == price {
+		return node.level
+	}
+
+	height := s.randomHeight()
+	for i := s.height; i < height; i++ {
+		predecessors[i] = s.head
+	}
+	if height > s.height {
+		s.height = height
+	}
+
+	node := &skipListNode{
+		level: newPriceLevel(price),
+		next:  make([]*skipListNode, height),
+	}
+	for i := 0; i < height; i++ {
+		node.next[i] = predecessors[i].next[i]
+		predecessors[i].next[i] = node
+	}
+	return node.level
+}
+
+func (s *skipList) delete(price models.Decimal) {
+	predecessors := s.findPredecessors(price)
+	node := predecessors[0].next[0]
+	if node == nil || node.level.price != price {
+		return
+	}
+
+	for i := 0; i < len(node.next); i++ {
+		predecessors[i].next[i] = node.next[i]
+	}
+	for s.height > 1 && s.head.next[s.height-1] == nil {
+		s.height--
+	}
+}
+
+func (s *skipList) first() *priceLevel {
+	if node := s.head.next[0]; node != nil {
+		return node.level
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"container/list"
+	"dealer/internal/models"
+)
+
+type PriceComparator func(models.Decimal, models.Decimal) bool
+
+var BuyPriceComparator PriceComparator = func(p1, p2 models.Decimal) bool {
+	return p1 > p2
+}
+
+var SellPriceComparator PriceComparator = func(p1, p2 models.Decimal) bool {
+	return p1 < p2
+}
+
+type bookEntry struct {
+	level   *priceLevel
+	element *list.Element
+}
+
+// PriceLevelOrderBook keeps market orders in a FIFO queue ahead of the limit
+// orders, which are grouped into price levels held in a skip list.
+type PriceLevelOrderBook struct {
+	marketOrders *list.List
+	levels       *skipList
+	index        map[int64]*bookEntry
+}
+
+var _ OrderBookInterface = (*PriceLevelOrderBook)(nil)
+
+func NewPriceLevelOrderBook(comparator PriceComparator) *PriceLevelOrderBook {
+	return &PriceLevelOrderBook{
+		marketOrders: list.New(),
+		levels:       newSkipList(comparator),
+		index:        make(map[int64]*bookEntry),
+	}
+}
+
+func (book *PriceLevelOrderBook) AddOrder(order *models.Order) {
+	if _, ok := book.index[order.ID]; ok {
+		return
+	}
+
+	if order.MatchPriceType() == models.PriceTypeMarket {
+		book.index[order.ID] = &bookEntry{element: insertByQueuePosition(book.marketOrders, order)}
+		return
+	}
+
+	level := book.levels.getOrInsert(order.Price)
+	book.index[order.ID] = &bookEntry{level: level, element: level.insert(order)}
+}
+
+func (book *PriceLevelOrderBook) Peek() *models.Order {
+	if e := book.front(); e != nil {
+		return e.Value.(*models.Order)
+	}
+
+	return nil
+}
+
+func (book *PriceLevelOrderBook) Get(orderID int64) *models.Order {
+	if entry, ok := book.index[orderID]; ok {
+		return entry.element.Value.(*models.Order)
+	}
+
+	return nil
+}
+
+func (book *PriceLevelOrderBook) Dequeue() *models.Order {
+	e := book.front()
+	if e == nil {
+		return nil
+	}
+
+	order := e.Value.(*models.Order)
+	book.RemoveOrder(order.ID)
+	return order
+}
+
+func (book *PriceLevelOrderBook) RemoveOrder(orderID int64) {
+	entry, ok := book.index[orderID]
+	if !ok {
+		return
+	}
+	delete(book.index, orderID)
+
+	if entry.level == nil {
+		book.marketOrders.Remove(entry.element)
+		return
+	}
+
+	entry.level.orders.Remove(entry.element)
+	if entry.level.orders.Len() == 0 {
+		book.levels.delete(entry.level.price)
+	}
+}
+
+func (book *PriceLevelOrderBook) Range(fn func(*models.Order) bool) {
+	for e := book.marketOrders.Front(); e != nil; e = e.Next() {
+		if !fn(e.Value.(*models.Order)) {
+			return
+		}
+	}
+
+	for node := book.levels.head.next[0]; node != nil; node = node.next[0] {
+		for e := node.level.orders.Front(); e != nil; e = e.Next() {
+			if !fn(e.Value.(*models.Order)) {
+				return
+			}
+		}
+	}
+}
+
+// Depth returns the total visible quantity and the number of orders of up to
+// limit price levels, leaving out the market orders.
+func (book *PriceLevelOrderBook) Depth(limit int) []*models.PriceLevel {
+	var levels []*models.PriceLevel
+	for node := book.levels.head.next[0]; node != nil && len(levels) < limit; node = node.next[0] {
+		level := &models.PriceLevel{Price: node.level.price, Count: uint(node.level.orders.Len())}
+		for e := node.level.orders.Front(); e != nil; e = e.Next() {
+			level.Quantity += e.Value.(*models.Order).VisibleQuantity()
+		}
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+func (book *PriceLevelOrderBook) front() *list.Element {
+	if e := book.marketOrders.Front(); e != nil {
+		return e
+	}
+
+	if level := book.levels.first(); level != nil {
+		return level.orders.Front()
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceLevelOrderBookIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		orders   []*models.Order
+		fn       func(*PriceLevelOrderBook)
+		length   int
+		levels   int
+		expected []int64
+	}{
+		{
+			name: "Add order twice",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			fn:       func(*PriceLevelOrderBook) {},
+			length:   1,
+			levels:   1,
+			expected: []int64{1},
+		},
+		{
+			name: "Dequeue order removes empty level",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 1},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			fn:       func(book *PriceLevelOrderBook) { book.Dequeue() },
+			length:   1,
+			levels:   1,
+			expected: []int64{1},
+		},
+		{
+			name: "Dequeue order keeps level",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 2},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			fn:       func(book *PriceLevelOrderBook) { book.Dequeue() },
+			length:   1,
+			levels:   1,
+			expected: []int64{2},
+		},
+		{
+			name: "Remove order removes empty level",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeLimit, Price: 1},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			fn:       func(book *PriceLevelOrderBook) { book.RemoveOrder(1) },
+			length:   1,
+			levels:   1,
+			expected: []int64{2},
+		},
+		{
+			name: "Remove market order",
+			orders: []*models.Order{
+				{ID: 1, PriceType: models.PriceTypeMarket},
+				{ID: 2, PriceType: models.PriceTypeLimit, Price: 2},
+			},
+			fn:       func(book *PriceLevelOrderBook) { book.RemoveOrder(1) },
+			length:   1,
+			levels:   1,
+			expected: []int64{2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			book := NewPriceLevelOrderBook(BuyPriceComparator)
+			for _, order := range test.orders {
+				book.AddOrder(order)
+			}
+
+			test.fn(book)
+			assert.Len(t, book.index, test.length)
+			assert.Equal(t, test.levels, levelCount(book.levels))
+			assert.Equal(t, test.expected, drain(book))
+		})
+	}
+}
+
+func levelCount(s *skipList) int {
+	var count int
+	for node := s.head.next[0]; node != nil; node = node.next[0] {
+		count++
+	}
+
+	return count
+}
+
+func TestPriceLevelOrderBookMatchesOrderBook(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+	for _, side := range []struct {
+		comparator      Comparator
+		priceComparator PriceComparator
+	}{
+		{comparator: BuyComparator, priceComparator: BuyPriceComparator},
+		{comparator: SellComparator, priceComparator: SellPriceComparator},
+	} {
+		book := NewOrderBook(side.comparator)
+		priceLevelBook := NewPriceLevelOrderBook(side.priceComparator)
+		for id := int64(1); id <= 500; id++ {
+			order := randomOrder(random, id)
+			book.AddOrder(order)
+			priceLevelBook.AddOrder(order)
+			if random.Intn(4) == 0 {
+				removeID := random.Int63n(id) + 1
+				book.RemoveOrder(removeID)
+				priceLevelBook.RemoveOrder(removeID)
+			}
+		}
+
+		assert.Equal(t, book.Depth(10), priceLevelBook.Depth(10))
+		assert.Equal(t, drain(book), drain(priceLevelBook))
+	}
+}
+
+func randomOrder(random *rand.Rand, id int64) *models.Order {
+	order := &models.Order{
+		ID:             id,
+		RemainQuantity: uint(random.Intn(10) + 1),
+		PriceType:      models.PriceTypeLimit,
+		Price:          models.Decimal(random.Intn(100)+1) * models.DecimalScale / 4,
+	}
+	if random.Intn(20) == 0 {
+		order.PriceType = models.PriceTypeMarket
+		order.Price = 0
+	}
+
+	return order
+}
+
+func benchmarkOrderBook(b *testing.B, newBook func() OrderBookInterface) {
+	for _, depth := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			random := rand.New(rand.NewSource(42))
+			book := newBook()
+			for id := 1; id <= depth; id++ {
+				book.AddOrder(randomOrder(random, int64(id)))
+			}
+
+			orders := make([]*models.Order, b.N)
+			for i := range orders {
+				orders[i] = randomOrder(random, int64(depth+i+1))
+			}
+
+			b.ResetTimer()
+			for _, order := range orders {
+				book.AddOrder(order)
+				book.Peek()
+				book.RemoveOrder(order.ID)
+			}
+		})
+	}
+}
+
+func BenchmarkOrderBook(b *testing.B) {
+	benchmarkOrderBook(b, func() OrderBookInterface {
+		return NewOrderBook(BuyComparator)
+	})
+}
+
+func BenchmarkPriceLevelOrderBook(b *testing.B) {
+	benchmarkOrderBook(b, func() OrderBookInterface {
+		return NewPriceLevelOrderBook(BuyPriceComparator)
+	})
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/dao"
+	"dealer/internal/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultQueryLimit  = 100
+	MaxQueryLimit      = 1000
+	DefaultDepthLevels = 10
+	TickerPeriod       = 24 * time.Hour
+)
+
+type QueryInterface interface {
+	GetOrder(context.Context, int64, int64) (*models.Order, error)
+	ListOrders(context.Context, *models.OrderQuery) ([]*models.Order, int64, error)
+	ListDeals(context.Context, int64, int64) ([]*models.Deal, error)
+	Depth(context.Context, string, int) (*models.Depth, error)
+	Ticker(context.Context, string, time.Time) (*models.Ticker, error)
+	ListCandles(context.Context, *models.CandleQuery) ([]*models.Candle, error)
+	ListBalances(context.Context, int64) ([]*models.Balance, error)
+	Book(context.Context, string) (*models.Book, error)
+}
+
+type Query struct {
+	db         *gorm.DB
+	orderDAO   dao.OrderInterface
+	dealDAO    dao.DealInterface
+	candleDAO  dao.CandleInterface
+	balanceDAO dao.BalanceInterface
+	marketData MarketDataInterface
+}
+
+var _ QueryInterface = (*Query)(nil)
+
+func NewQuery(db *gorm.DB, orderDAO dao.OrderInterface, dealDAO dao.DealInterface, candleDAO dao.CandleInterface, balanceDAO dao.BalanceInterface, marketData MarketDataInterface) *Query {
+	return &Query{
+		db:         db,
+		orderDAO:   orderDAO,
+		dealDAO:    dealDAO,
+		candleDAO:  candleDAO,
+		balanceDAO: balanceDAO,
+		marketData: marketData,
+	}
+}
+
+// GetOrder returns the order of the user.
+func (q *Query) GetOrder(ctx context.Context, userID, orderID int64) (*models.Order, error) {
+	order, err := q.orderDAO.Get(ctx, q.db, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil || order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// ListOrders returns a page of the orders matching the query and the cursor
+// of the next page, which is zero on the last page.
+func (q *Query) ListOrders(ctx context.Context, query *models.OrderQuery) ([]*models.Order, int64, error) {
+	if query.Status < 0 || query.Status > models.OrderStatusRejected {
+		return nil, 0, ErrInvalidStatus
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultQueryLimit
+	}
+	if query.Limit > MaxQueryLimit {
+		query.Limit = MaxQueryLimit
+	}
+
+	orders, err := q.orderDAO.List(ctx, q.db, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nextCursor int64
+	if len(orders) == query.Limit {
+		nextCursor = orders[len(orders)-1].ID
+	}
+
+	return orders, nextCursor, nil
+}
+
+// ListDeals returns the deals of the order of the user as either taker or
+// maker, ordered by deal ID.
+func (q *Query) ListDeals(ctx context.Context, userID, orderID int64) ([]*models.Deal, error) {
+	order, err := q.GetOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	takerDeals, err := q.dealDAO.List(ctx, q.db, &models.Deal{Symbol: order.Symbol, TakerOrderID: orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	makerDeals, err := q.dealDAO.List(ctx, q.db, &models.Deal{Symbol: order.Symbol, MakerOrderID: orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	deals := append(takerDeals, makerDeals...)
+	sort.Slice(deals, func(i, j int) bool {
+		return deals[i].ID < deals[j].ID
+	})
+
+	return deals, nil
+}
+
+// Depth returns the aggregated price levels of the last snapshot published by
+// the dealer. Levels beyond the depth kept by the market data are left out.
+func (q *Query) Depth(ctx context.Context, symbol string, levels int) (*models.Depth, error) {
+	if levels <= 0 {
+		levels = DefaultDepthLevels
+	}
+
+	return q.marketData.Depth(symbol, levels)
+}
+
+// Ticker returns the best prices and the last trading price of the symbol,
+// with the volume, high and low of the deals in the TickerPeriod before now.
+func (q *Query) Ticker(ctx context.Context, symbol string, now time.Time) (*models.Ticker, error) {
+	ticker, err := q.marketData.Ticker(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := q.dealDAO.Stats(ctx, q.db, symbol, now.Add(-TickerPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	ticker.Volume = stats.Volume
+	ticker.High = stats.High
+	ticker.Low = stats.Low
+	return ticker, nil
+}
+
+// ListCandles returns the candles of the interval opened in [From, To) by
+// open time.
+func (q *Query) ListCandles(ctx context.Context, query *models.CandleQuery) ([]*models.Candle, error) {
+	if !query.Interval.IsValid() {
+		return nil, ErrInvalidInterval
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultQueryLimit
+	}
+	if query.Limit > MaxQueryLimit {
+		query.Limit = MaxQueryLimit
+	}
+
+	return q.candleDAO.List(ctx, q.db, query)
+}
+
+func (q *Query) ListBalances(ctx context.Context, userID int64) ([]*models.Balance, error) {
+	return q.balanceDAO.List(ctx, q.db, userID)
+}
+
+// Book returns the resting orders of the symbol as stored in the DB, the
+// orders the dealer would recover into its books, in the order the books
+// keep them. The books of the dealer are never read.
+func (q *Query) Book(ctx context.Context, symbol string) (*models.Book, error) {
+	ticker, err := q.marketData.Ticker(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := q.orderDAO.ListOpenBySymbol(ctx, q.db, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	buyBook, sellBook := NewPriceLevelOrderBook(BuyPriceComparator), NewPriceLevelOrderBook(SellPriceComparator)
+	buyStopBook, sellStopBook := NewStopBook(BuyStopComparator), NewStopBook(SellStopComparator)
+	for _, order := range orders {
+		switch {
+		case order.OrderType == models.OrderTypeBuy && order.IsStop() && order.TriggeredAt == nil:
+			buyStopBook.AddOrder(order)
+		case order.OrderType == models.OrderTypeSell && order.IsStop() && order.TriggeredAt == nil:
+			sellStopBook.AddOrder(order)
+		case order.OrderType == models.OrderTypeBuy:
+			buyBook.AddOrder(order)
+		case order.OrderType == models.OrderTypeSell:
+			sellBook.AddOrder(order)
+		}
+	}
+
+	book := &models.Book{
+		Symbol:     symbol,
+		Phase:      ticker.Phase,
+		Bids:       []*models.Order{},
+		Asks:       []*models.Order{},
+		StopOrders: []*models.Order{},
+	}
+	buyBook.Range(func(order *models.Order) bool {
+		book.Bids = append(book.Bids, order)
+		return true
+	})
+	sellBook.Range(func(order *models.Order) bool {
+		book.Asks = append(book.Asks, order)
+		return true
+	})
+	for _, stopBook := range []StopBookInterface{buyStopBook, sellStopBook} {
+		stopBook.Range(func(order *models.Order) bool {
+			book.StopOrders = append(book.StopOrders, order)
+			return true
+		})
+	}
+
+	return book, nil
+}
@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockDAO "dealer/internal/mock/dao"
+	mockService "dealer/internal/mock/service"
+	"dealer/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+	ctrl           *gomock.Controller
+	db             *sql.DB
+	mockDB         sqlmock.Sqlmock
+	mockGormDB     *gorm.DB
+	mockOrderDAO   *mockDAO.MockOrderInterface
+	mockDealDAO    *mockDAO.MockDealInterface
+	mockCandleDAO  *mockDAO.MockCandleInterface
+	mockBalanceDAO *mockDAO.MockBalanceInterface
+	mockMarketData *mockService.MockMarketDataInterface
+	svc            *Query
+}
+
+func (t *QueryTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockOrderDAO = mockDAO.NewMockOrderInterface(t.ctrl)
+	t.mockDealDAO = mockDAO.NewMockDealInterface(t.ctrl)
+	t.mockCandleDAO = mockDAO.NewMockCandleInterface(t.ctrl)
+	t.mockMarketData = mockService.NewMockMarketDataInterface(t.ctrl)
+	t.mockBalanceDAO = mockDAO.NewMockBalanceInterface(t.ctrl)
+	t.svc = NewQuery(t.mockGormDB, t.mockOrderDAO, t.mockDealDAO, t.mockCandleDAO, t.mockBalanceDAO, t.mockMarketData)
+}
+
+func (t *QueryTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+func (t *QueryTestSuite) TestGetOrder() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.Order
+		err      error
+	}{
+		{
+			name: "Get order success",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD"}, nil)
+			},
+			expected: &models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD"},
+			err:      nil,
+		},
+		{
+			name: "Get order of another user",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 8, Symbol: "BTCUSD"}, nil)
+			},
+			expected: nil,
+			err:      ErrOrderNotFound,
+		},
+		{
+			name: "Get order not found",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(nil, nil)
+			},
+			expected: nil,
+			err:      ErrOrderNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			order, err := t.svc.GetOrder(context.Background(), 7, 1)
+			t.Equal(test.expected, order)
+			t.Equal(test.err, err)
+		})
+	}
+}
+
+func (t *QueryTestSuite) TestListOrders() {
+	tests := []struct {
+		name       string
+		query      *models.OrderQuery
+		fn         func()
+		expected   []*models.Order
+		nextCursor int64
+		hasError   bool
+	}{
+		{
+			name:  "List orders last page",
+			query: &models.OrderQuery{Status: models.OrderStatusNew},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.OrderQuery{Status: models.OrderStatusNew, Limit: DefaultQueryLimit}).
+					Return([]*models.Order{{ID: 1}}, nil)
+			},
+			expected:   []*models.Order{{ID: 1}},
+			nextCursor: 0,
+			hasError:   false,
+		},
+		{
+			name:  "List orders full page",
+			query: &models.OrderQuery{Cursor: 3, Limit: 2},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.OrderQuery{Cursor: 3, Limit: 2}).
+					Return([]*models.Order{{ID: 4}, {ID: 6}}, nil)
+			},
+			expected:   []*models.Order{{ID: 4}, {ID: 6}},
+			nextCursor: 6,
+			hasError:   false,
+		},
+		{
+			name:  "List orders limit capped",
+			query: &models.OrderQuery{Limit: MaxQueryLimit + 1},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.OrderQuery{Limit: MaxQueryLimit}).
+					Return(nil, nil)
+			},
+			expected: nil,
+			hasError: false,
+		},
+		{
+			name:     "List orders invalid status",
+			query:    &models.OrderQuery{Status: models.OrderStatusRejected + 1},
+			fn:       func() {},
+			expected: nil,
+			hasError: true,
+		},
+		{
+			name:  "List orders failed",
+			query: &models.OrderQuery{},
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.OrderQuery{Limit: DefaultQueryLimit}).
+					Return(nil, errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			orders, nextCursor, err := t.svc.ListOrders(context.Background(), test.query)
+			t.Equal(test.expected, orders)
+			t.Equal(test.nextCursor, nextCursor)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *QueryTestSuite) TestListDeals() {
+	tests := []struct {
+		name     string
+		fn       func()
+		expected []*models.Deal
+		hasError bool
+	}{
+		{
+			name: "List deals as taker and maker",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD"}, nil)
+				t.mockDealDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.Deal{Symbol: "BTCUSD", TakerOrderID: 1}).
+					Return([]*models.Deal{{ID: 3, TakerOrderID: 1}}, nil)
+				t.mockDealDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.Deal{Symbol: "BTCUSD", MakerOrderID: 1}).
+					Return([]*models.Deal{{ID: 2, MakerOrderID: 1}}, nil)
+			},
+			expected: []*models.Deal{{ID: 2, MakerOrderID: 1}, {ID: 3, TakerOrderID: 1}},
+			hasError: false,
+		},
+		{
+			name: "List deals order not found",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(nil, nil)
+			},
+			expected: nil,
+			hasError: true,
+		},
+		{
+			name: "List deals failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					Get(context.Background(), t.mockGormDB, int64(1)).
+					Return(&models.Order{ID: 1, UserID: 7, Symbol: "BTCUSD"}, nil)
+				t.mockDealDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.Deal{Symbol: "BTCUSD", TakerOrderID: 1}).
+					Return(nil, errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			deals, err := t.svc.ListDeals(context.Background(), 7, 1)
+			t.Equal(test.expected, deals)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
+
+func (t *QueryTestSuite) TestDepth() {
+	depth := &models.Depth{Bids: []*models.PriceLevel{{Price: 10, Quantity: 1, Count: 1}}}
+	tests := []struct {
+		name     string
+		levels   int
+		fn       func()
+		expected *models.Depth
+		hasError bool
+	}{
+		{
+			name:   "Depth with levels",
+			levels: 5,
+			fn: func() {
+				t.mockMarketData.EXPECT().Depth("BTCUSD", 5).Return(depth, nil)
+			},
+			expected: depth,
+			hasError: false,
+		},
+		{
+			name:   "Depth with default levels",
+			levels: 0,
+			fn: func() {
+				t.mockMarketData.EXPECT().Depth("BTCUSD", DefaultDepthLevels).Return(depth, nil)
+			},
+			expected: depth,
+			hasError: false,
+		},
+		{
+			name:   "Depth of unknown symbol",
+			levels: 5,
+			fn: func() {
+				t.mockMarketData.EXPECT().Depth("BTCUSD", 5).Return(nil, ErrUnknownSymbol)
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := t.svc.Depth(context.Background(), "BTCUSD", test.levels)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *QueryTestSuite) TestTicker() {
+	now := time.Date(2022, 8, 2, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		fn       func()
+		expected *models.Ticker
+		hasError bool
+	}{
+		{
+			name: "Ticker success",
+			fn: func() {
+				t.mockMarketData.EXPECT().Ticker("BTCUSD").Return(&models.Ticker{Symbol: "BTCUSD", BestBid: 9, BestAsk: 11, LastPrice: 10}, nil)
+				t.mockDealDAO.EXPECT().Stats(context.Background(), t.mockGormDB, "BTCUSD", from).Return(&models.DealStats{Volume: 5, High: 12, Low: 8}, nil)
+			},
+			expected: &models.Ticker{Symbol: "BTCUSD", BestBid: 9, BestAsk: 11, LastPrice: 10, Volume: 5, High: 12, Low: 8},
+			hasError: false,
+		},
+		{
+			name: "Ticker of unknown symbol",
+			fn: func() {
+				t.mockMarketData.EXPECT().Ticker("BTCUSD").Return(nil, ErrUnknownSymbol)
+			},
+			expected: nil,
+			hasError: true,
+		},
+		{
+			name: "Ticker stats failed",
+			fn: func() {
+				t.mockMarketData.EXPECT().Ticker("BTCUSD").Return(&models.Ticker{Symbol: "BTCUSD"}, nil)
+				t.mockDealDAO.EXPECT().Stats(context.Background(), t.mockGormDB, "BTCUSD", from).Return(nil, errors.New(""))
+			},
+			expected: nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := t.svc.Ticker(context.Background(), "BTCUSD", now)
+			t.Equal(test.hasError, err != nil)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *QueryTestSuite) TestListCandles() {
+	errSQL := errors.New("")
+	from := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		query    *models.CandleQuery
+		fn       func()
+		expected []*models.Candle
+		err      error
+	}{
+		{
+			name:  "List candles with default limit",
+			query: &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1m, From: &from},
+			fn: func() {
+				t.mockCandleDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1m, From: &from, Limit: DefaultQueryLimit}).
+					Return([]*models.Candle{{Symbol: "BTCUSD", Interval: models.CandleInterval1m, OpenTime: from}}, nil)
+			},
+			expected: []*models.Candle{{Symbol: "BTCUSD", Interval: models.CandleInterval1m, OpenTime: from}},
+			err:      nil,
+		},
+		{
+			name:  "List candles with limit capped",
+			query: &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1d, Limit: MaxQueryLimit + 1},
+			fn: func() {
+				t.mockCandleDAO.EXPECT().
+					List(context.Background(), t.mockGormDB, &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1d, Limit: MaxQueryLimit}).
+					Return(nil, nil)
+			},
+			expected: nil,
+			err:      nil,
+		},
+		{
+			name:     "List candles of invalid interval",
+			query:    &models.CandleQuery{Symbol: "BTCUSD", Interval: "2m"},
+			fn:       func() {},
+			expected: nil,
+			err:      ErrInvalidInterval,
+		},
+		{
+			name:  "List candles failed",
+			query: &models.CandleQuery{Symbol: "BTCUSD", Interval: models.CandleInterval1h},
+			fn: func() {
+				t.mockCandleDAO.EXPECT().List(context.Background(), t.mockGormDB, gomock.Any()).Return(nil, errSQL)
+			},
+			expected: nil,
+			err:      errSQL,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := t.svc.ListCandles(context.Background(), test.query)
+			t.Equal(test.err, err)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
+
+func (t *QueryTestSuite) TestListBalances() {
+	t.mockBalanceDAO.EXPECT().
+		List(context.Background(), t.mockGormDB, int64(7)).
+		Return([]*models.Balance{{UserID: 7, Asset: "USD", Available: 10}}, nil)
+
+	balances, err := t.svc.ListBalances(context.Background(), 7)
+	t.NoError(err)
+	t.Equal([]*models.Balance{{UserID: 7, Asset: "USD", Available: 10}}, balances)
+}
+
+func (t *QueryTestSuite) TestBook() {
+	triggeredAt := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*models.Order{
+		{ID: 1, Symbol: "BTCUSD", OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 99},
+		{ID: 2, Symbol: "BTCUSD", OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 100},
+		{ID: 3, Symbol: "BTCUSD", OrderType: models.OrderTypeSell, RemainQuantity: 1, PriceType: models.PriceTypeLimit, Price: 101},
+		{ID: 4, Symbol: "BTCUSD", OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeStopLimit, Price: 106, StopPrice: 105},
+		{ID: 5, Symbol: "BTCUSD", OrderType: models.OrderTypeBuy, RemainQuantity: 1, PriceType: models.PriceTypeStopLimit, Price: 98, StopPrice: 97, TriggeredAt: &triggeredAt},
+	}
+	ids := func(orders []*models.Order) []int64 {
+		ids := []int64{}
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+		return ids
+	}
+
+	t.mockMarketData.EXPECT().Ticker("BTCUSD").Return(&models.Ticker{Symbol: "BTCUSD", Phase: models.TradingPhaseHalted}, nil)
+	t.mockOrderDAO.EXPECT().ListOpenBySymbol(context.Background(), t.mockGormDB, "BTCUSD").Return(orders, nil)
+	book, err := t.svc.Book(context.Background(), "BTCUSD")
+	t.NoError(err)
+	t.Equal("BTCUSD", book.Symbol)
+	t.Equal(models.TradingPhaseHalted, book.Phase)
+	t.Equal([]int64{2, 1, 5}, ids(book.Bids))
+	t.Equal([]int64{3}, ids(book.Asks))
+	t.Equal([]int64{4}, ids(book.StopOrders))
+
+	t.mockMarketData.EXPECT().Ticker("UNKNOWN").Return(nil, ErrUnknownSymbol)
+	_, err = t.svc.Book(context.Background(), "UNKNOWN")
+	t.Equal(ErrUnknownSymbol, err)
+
+	t.mockMarketData.EXPECT().Ticker("BTCUSD").Return(&models.Ticker{Symbol: "BTCUSD"}, nil)
+	t.mockOrderDAO.EXPECT().ListOpenBySymbol(context.Background(), t.mockGormDB, "BTCUSD").Return(nil, errors.New(""))
+	_, err = t.svc.Book(context.Background(), "BTCUSD")
+	t.Error(err)
+}
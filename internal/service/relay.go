@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/dao"
+	"dealer/internal/logger"
+	"dealer/internal/sdk"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/gorm"
+)
+
+// sequenceHeader carries the sequence of the outbox row a message is published
+// from, which the dealer uses to skip the messages it has already applied.
+const sequenceHeader = "sequence"
+
+type OutboxRelayInterface interface {
+	Run(context.Context)
+	Relay(context.Context, time.Time) error
+}
+
+// OutboxRelay publishes the pending outbox messages to the order queue. The
+// IDs of the outbox rows are assigned at insert time, so a row with a lower ID
+// may commit after one with a higher ID. The relay therefore gives every
+// committed row the next sequence before publishing it, and publishes in the
+// order of the sequences, so a message is never published with a sequence
+// below one already published. Only one relay may run against the outbox. A
+// message is marked as sent only after the broker confirms it, and a message
+// that fails to publish stops the batch and is retried on the next tick with
+// the same sequence, so delivery is at least once.
+type OutboxRelay struct {
+	interval  time.Duration
+	batchSize int
+	ch        sdk.AMQPChannel
+	confirms  <-chan amqp.Confirmation
+	queueName string
+	db        *gorm.DB
+	outboxDAO dao.OutboxInterface
+}
+
+var _ OutboxRelayInterface = (*OutboxRelay)(nil)
+
+func NewOutboxRelay(interval time.Duration, batchSize int, ch sdk.AMQPChannel, queueName string, db *gorm.DB, outboxDAO dao.OutboxInterface) *OutboxRelay {
+	return &OutboxRelay{
+		interval:  interval,
+		batchSize: batchSize,
+		ch:        ch,
+		confirms:  ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+		queueName: queueName,
+		db:        db,
+		outboxDAO: outboxDAO,
+	}
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.Relay(ctx, now); err != nil {
+				logger.GetLogger().Error(err.Error())
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) Relay(ctx context.Context, now time.Time) error {
+	if err := r.assignSequences(ctx); err != nil {
+		return err
+	}
+
+	outboxes, err := r.outboxDAO.ListPending(ctx, r.db, r.batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, outbox := range outboxes {
+		publishing := amqp.Publishing{
+			Headers:     amqp.Table{sequenceHeader: outbox.Sequence},
+			ContentType: "application/json",
+			Body:        outbox.Payload,
+		}
+		if err := publish(ctx, r.ch, r.confirms, r.queueName, publishing); err != nil {
+			if err := r.outboxDAO.IncreaseAttempts(ctx, r.db, outbox.ID); err != nil {
+				logger.GetLogger().Error(err.Error())
+			}
+			return err
+		}
+
+		if err := r.outboxDAO.MarkSent(ctx, r.db, outbox.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assignSequences gives the committed outbox rows without a sequence the next
+// sequences in the order of their IDs.
+func (r *OutboxRelay) assignSequences(ctx context.Context) error {
+	tx := r.db.Begin()
+	outboxes, err := r.outboxDAO.ListUnsequenced(ctx, tx, r.batchSize)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if len(outboxes) == 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	sequence, err := r.outboxDAO.LastSequence(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, outbox := range outboxes {
+		sequence++
+		if err := r.outboxDAO.SetSequence(ctx, tx, outbox.ID, sequence); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// publish publishes a persistent message on a channel in confirm mode and
+// waits for the broker to confirm it. The channel must not be shared with
+// another publisher, since the confirmations are matched in order.
+func publish(ctx context.Context, ch sdk.AMQPChannel, confirms <-chan amqp.Confirmation, queueName string, publishing amqp.Publishing) error {
+	publishing.DeliveryMode = amqp.Persistent
+	if err := ch.PublishWithContext(ctx, "", queueName, false, false, publishing); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case confirmation, ok := <-confirms:
+		if !ok || !confirmation.Ack {
+			return ErrPublishNotConfirmed
+		}
+		return nil
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockDAO "dealer/internal/mock/dao"
+	mockSDK "dealer/internal/mock/sdk"
+	"dealer/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type OutboxRelayTestSuite struct {
+	suite.Suite
+	ctrl          *gomock.Controller
+	db            *sql.DB
+	mockDB        sqlmock.Sqlmock
+	mockGormDB    *gorm.DB
+	mockChannel   *mockSDK.MockAMQPChannel
+	confirms      chan amqp.Confirmation
+	mockOutboxDAO *mockDAO.MockOutboxInterface
+	svc           *OutboxRelay
+}
+
+func (t *OutboxRelayTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockChannel = mockSDK.NewMockAMQPChannel(t.ctrl)
+	t.confirms = make(chan amqp.Confirmation, 1)
+	t.mockChannel.EXPECT().NotifyPublish(gomock.Any()).Return(t.confirms)
+	t.mockOutboxDAO = mockDAO.NewMockOutboxInterface(t.ctrl)
+	t.svc = NewOutboxRelay(time.Second, 10, t.mockChannel, "name", t.mockGormDB, t.mockOutboxDAO)
+}
+
+func (t *OutboxRelayTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestOutboxRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxRelayTestSuite))
+}
+
+// confirm makes the broker confirm a publishing with ack.
+func (t *OutboxRelayTestSuite) confirm(ack bool) func(context.Context, string, string, bool, bool, amqp.Publishing) {
+	return func(context.Context, string, string, bool, bool, amqp.Publishing) {
+		t.confirms <- amqp.Confirmation{Ack: ack}
+	}
+}
+
+// expectSequenced expects the relay to find no outbox row without a sequence.
+func (t *OutboxRelayTestSuite) expectSequenced() {
+	t.mockDB.ExpectBegin()
+	t.mockOutboxDAO.EXPECT().ListUnsequenced(context.Background(), gomock.Any(), 10).Return(nil, nil)
+	t.mockDB.ExpectRollback()
+}
+
+func (t *OutboxRelayTestSuite) TestRelay() {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	outboxes := []*models.Outbox{
+		{ID: 1, Sequence: 3, Payload: []byte(`{"type":1}`)},
+		{ID: 2, Sequence: 4, Payload: []byte(`{"type":2}`)},
+	}
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Relay pending messages",
+			fn: func() {
+				t.expectSequenced()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return(outboxes, nil)
+				t.mockChannel.EXPECT().
+					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{Headers: amqp.Table{"sequence": outboxes[0].Sequence}, DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: outboxes[0].Payload}).
+					Do(t.confirm(true)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().MarkSent(context.Background(), t.mockGormDB, int64(1), now).Return(nil)
+				t.mockChannel.EXPECT().
+					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{Headers: amqp.Table{"sequence": outboxes[1].Sequence}, DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: outboxes[1].Payload}).
+					Do(t.confirm(true)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().MarkSent(context.Background(), t.mockGormDB, int64(2), now).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Relay assigns sequences in the order rows become visible",
+			fn: func() {
+				// Row 5 commits after row 6 has been published with sequence 6.
+				late := &models.Outbox{ID: 5, Sequence: 7, Payload: []byte(`{"type":5}`)}
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().ListUnsequenced(context.Background(), gomock.Any(), 10).Return([]*models.Outbox{{ID: 5}}, nil)
+				t.mockOutboxDAO.EXPECT().LastSequence(context.Background(), gomock.Any()).Return(int64(6), nil)
+				t.mockOutboxDAO.EXPECT().SetSequence(context.Background(), gomock.Any(), int64(5), int64(7)).Return(nil)
+				t.mockDB.ExpectCommit()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return([]*models.Outbox{late}, nil)
+				t.mockChannel.EXPECT().
+					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{Headers: amqp.Table{"sequence": int64(7)}, DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: late.Payload}).
+					Do(t.confirm(true)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().MarkSent(context.Background(), t.mockGormDB, int64(5), now).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Relay list unsequenced messages failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().ListUnsequenced(context.Background(), gomock.Any(), 10).Return(nil, errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+		{
+			name: "Relay last sequence failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().ListUnsequenced(context.Background(), gomock.Any(), 10).Return([]*models.Outbox{{ID: 5}}, nil)
+				t.mockOutboxDAO.EXPECT().LastSequence(context.Background(), gomock.Any()).Return(int64(0), errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+		{
+			name: "Relay set sequence failed",
+			fn: func() {
+				t.mockDB.ExpectBegin()
+				t.mockOutboxDAO.EXPECT().ListUnsequenced(context.Background(), gomock.Any(), 10).Return([]*models.Outbox{{ID: 5}}, nil)
+				t.mockOutboxDAO.EXPECT().LastSequence(context.Background(), gomock.Any()).Return(int64(6), nil)
+				t.mockOutboxDAO.EXPECT().SetSequence(context.Background(), gomock.Any(), int64(5), int64(7)).Return(errors.New(""))
+				t.mockDB.ExpectRollback()
+			},
+			hasError: true,
+		},
+		{
+			name: "Relay no pending message",
+			fn: func() {
+				t.expectSequenced()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return(nil, nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Relay list pending messages failed",
+			fn: func() {
+				t.expectSequenced()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return(nil, errors.New(""))
+			},
+			hasError: true,
+		},
+		{
+			name: "Relay publish failed stops the batch",
+			fn: func() {
+				t.expectSequenced()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return(outboxes, nil)
+				t.mockChannel.EXPECT().
+					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{Headers: amqp.Table{"sequence": outboxes[0].Sequence}, DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: outboxes[0].Payload}).
+					Return(errors.New(""))
+				t.mockOutboxDAO.EXPECT().IncreaseAttempts(context.Background(), t.mockGormDB, int64(1)).Return(nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Relay not confirmed stops the batch",
+			fn: func() {
+				t.expectSequenced()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return(outboxes, nil)
+				t.mockChannel.EXPECT().
+					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{Headers: amqp.Table{"sequence": outboxes[0].Sequence}, DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: outboxes[0].Payload}).
+					Do(t.confirm(false)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().IncreaseAttempts(context.Background(), t.mockGormDB, int64(1)).Return(nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Relay mark sent failed",
+			fn: func() {
+				t.expectSequenced()
+				t.mockOutboxDAO.EXPECT().ListPending(context.Background(), t.mockGormDB, 10).Return(outboxes, nil)
+				t.mockChannel.EXPECT().
+					PublishWithContext(context.Background(), "", "name", false, false, amqp.Publishing{Headers: amqp.Table{"sequence": outboxes[0].Sequence}, DeliveryMode: amqp.Persistent, ContentType: "application/json", Body: outboxes[0].Payload}).
+					Do(t.confirm(true)).
+					Return(nil)
+				t.mockOutboxDAO.EXPECT().MarkSent(context.Background(), t.mockGormDB, int64(1), now).Return(errors.New(""))
+			},
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.Relay(context.Background(), now)
+			t.Equal(test.hasError, err != nil)
+			t.NoError(t.mockDB.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/logger"
+	"dealer/internal/models"
+	"time"
+)
+
+type PhaseSchedulerInterface interface {
+	Run(context.Context)
+	Check(context.Context, time.Time) error
+}
+
+// PhaseScheduler moves every market to the trading phase of the schedule. It
+// publishes a phase once it differs from the last one published, starting
+// with the current phase of the schedule.
+type PhaseScheduler struct {
+	interval       time.Duration
+	schedule       *models.TradingSchedule
+	registry       InstrumentRegistryInterface
+	orderProcessor OrderProcessorInterface
+	published      map[string]models.TradingPhase
+}
+
+var _ PhaseSchedulerInterface = (*PhaseScheduler)(nil)
+
+func NewPhaseScheduler(interval time.Duration, schedule *models.TradingSchedule, registry InstrumentRegistryInterface, orderProcessor OrderProcessorInterface) *PhaseScheduler {
+	return &PhaseScheduler{
+		interval:       interval,
+		schedule:       schedule,
+		registry:       registry,
+		orderProcessor: orderProcessor,
+		published:      make(map[string]models.TradingPhase),
+	}
+}
+
+func (s *PhaseScheduler) Run(ctx context.Context) {
+	if err := s.Check(ctx, time.Now()); err != nil {
+		logger.GetLogger().Error(err.Error())
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Check(ctx, now); err != nil {
+				logger.GetLogger().Error(err.Error())
+			}
+		}
+	}
+}
+
+func (s *PhaseScheduler) Check(ctx context.Context, now time.Time) error {
+	phase := s.schedule.Phase(now)
+	for _, instrument := range s.registry.List() {
+		if s.published[instrument.Symbol] == phase {
+			continue
+		}
+
+		if err := s.orderProcessor.ChangePhase(ctx, instrument.Symbol, phase); err != nil {
+			return err
+		}
+		s.published[instrument.Symbol] = phase
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockService "dealer/internal/mock/service"
+	"dealer/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PhaseSchedulerTestSuite struct {
+	suite.Suite
+	ctrl               *gomock.Controller
+	mockOrderProcessor *mockService.MockOrderProcessorInterface
+	svc                *PhaseScheduler
+}
+
+var testSchedule = &models.TradingSchedule{
+	Location:       time.UTC,
+	PreOpen:        8 * time.Hour,
+	Auction:        8*time.Hour + 50*time.Minute,
+	Continuous:     9 * time.Hour,
+	ClosingAuction: 16*time.Hour + 50*time.Minute,
+	Close:          17 * time.Hour,
+}
+
+func (t *PhaseSchedulerTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	t.mockOrderProcessor = mockService.NewMockOrderProcessorInterface(t.ctrl)
+	registry := NewInstrumentRegistry([]*models.Instrument{{Symbol: "BTCUSD"}, {Symbol: "ETHUSD"}})
+	t.svc = NewPhaseScheduler(time.Second, testSchedule, registry, t.mockOrderProcessor)
+}
+
+func (t *PhaseSchedulerTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+}
+
+func TestPhaseSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(PhaseSchedulerTestSuite))
+}
+
+func (t *PhaseSchedulerTestSuite) TestCheck() {
+	auction := time.Date(2022, 8, 1, 8, 55, 0, 0, time.UTC)
+
+	t.mockOrderProcessor.EXPECT().ChangePhase(context.Background(), "BTCUSD", models.TradingPhaseAuction).Return(nil)
+	t.mockOrderProcessor.EXPECT().ChangePhase(context.Background(), "ETHUSD", models.TradingPhaseAuction).Return(errors.New(""))
+	t.Error(t.svc.Check(context.Background(), auction))
+
+	t.mockOrderProcessor.EXPECT().ChangePhase(context.Background(), "ETHUSD", models.TradingPhaseAuction).Return(nil)
+	t.NoError(t.svc.Check(context.Background(), auction))
+	t.NoError(t.svc.Check(context.Background(), auction.Add(time.Minute)))
+
+	t.mockOrderProcessor.EXPECT().ChangePhase(context.Background(), "BTCUSD", models.TradingPhaseContinuous).Return(nil)
+	t.mockOrderProcessor.EXPECT().ChangePhase(context.Background(), "ETHUSD", models.TradingPhaseContinuous).Return(nil)
+	t.NoError(t.svc.Check(context.Background(), auction.Add(5*time.Minute)))
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"sort"
+)
+
+// StopComparator reports whether a stop order at stop price p1 is triggered
+// before one at p2.
+type StopComparator func(models.Decimal, models.Decimal) bool
+
+var BuyStopComparator StopComparator = func(p1, p2 models.Decimal) bool {
+	return p1 < p2
+}
+
+var SellStopComparator StopComparator = func(p1, p2 models.Decimal) bool {
+	return p1 > p2
+}
+
+type StopBookInterface interface {
+	AddOrder(*models.Order)
+	Get(int64) *models.Order
+	RemoveOrder(int64)
+	Trigger(models.Decimal) []*models.Order
+	Range(func(*models.Order) bool)
+}
+
+// StopBook holds the untriggered stop orders of one side, ordered by stop
+// price then by ID, so the triggered orders are always a prefix of the book.
+// The index finds an order by its ID.
+type StopBook struct {
+	orders     []*models.Order
+	index      map[int64]*models.Order
+	comparator StopComparator
+}
+
+var _ StopBookInterface = (*StopBook)(nil)
+
+func NewStopBook(comparator StopComparator) *StopBook {
+	return &StopBook{
+		index:      make(map[int64]*models.Order),
+		comparator: comparator,
+	}
+}
+
+func (book *StopBook) AddOrder(order *models.Order) {
+	if _, ok := book.index[order.ID]; ok {
+		return
+	}
+
+	i := book.search(order)
+	book.orders = append(book.orders, nil)
+	copy(book.orders[i+1:], book.orders[i:])
+	book.orders[i] = order
+	book.index[order.ID] = order
+}
+
+func (book *StopBook) Get(orderID int64) *models.Order {
+	return book.index[orderID]
+}
+
+func (book *StopBook) RemoveOrder(orderID int64) {
+	order, ok := book.index[orderID]
+	if !ok {
+		return
+	}
+	delete(book.index, orderID)
+
+	if i := book.search(order); i < len(book.orders) && book.orders[i] == order {
+		book.orders = append(book.orders[:i], book.orders[i+1:]...)
+	}
+}
+
+// search returns the position of the order in the book, or where it would be
+// inserted.
+func (book *StopBook) search(order *models.Order) int {
+	return sort.Search(len(book.orders), func(i int) bool {
+		o := book.orders[i]
+		if o.StopPrice != order.StopPrice {
+			return book.comparator(order.StopPrice, o.StopPrice)
+		}
+
+		return order.ID <= o.ID
+	})
+}
+
+// Trigger removes and returns the stop orders reached by the given price.
+func (book *StopBook) Trigger(price models.Decimal) []*models.Order {
+	n := sort.Search(len(book.orders), func(i int) bool {
+		return book.comparator(price, book.orders[i].StopPrice)
+	})
+	if n == 0 {
+		return nil
+	}
+
+	triggered := make([]*models.Order, n)
+	copy(triggered, book.orders[:n])
+	book.orders = book.orders[n:]
+	for _, order := range triggered {
+		delete(book.index, order.ID)
+	}
+	return triggered
+}
+
+func (book *StopBook) Range(fn func(*models.Order) bool) {
+	for _, order := range book.orders {
+		if !fn(order) {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopBookTrigger(t *testing.T) {
+	tests := []struct {
+		name       string
+		comparator StopComparator
+		orders     []*models.Order
+		price      models.Decimal
+		triggered  []int64
+		remain     []int64
+	}{
+		{
+			name:       "Buy stop lower stop price first",
+			comparator: BuyStopComparator,
+			orders: []*models.Order{
+				{ID: 1, StopPrice: 12},
+				{ID: 2, StopPrice: 10},
+				{ID: 3, StopPrice: 11},
+				{ID: 4, StopPrice: 13},
+			},
+			price:     12,
+			triggered: []int64{2, 3, 1},
+			remain:    []int64{4},
+		},
+		{
+			name:       "Sell stop higher stop price first",
+			comparator: SellStopComparator,
+			orders: []*models.Order{
+				{ID: 1, StopPrice: 10},
+				{ID: 2, StopPrice: 12},
+				{ID: 3, StopPrice: 9},
+			},
+			price:     10,
+			triggered: []int64{2, 1},
+			remain:    []int64{3},
+		},
+		{
+			name:       "Same stop price eariler first",
+			comparator: BuyStopComparator,
+			orders: []*models.Order{
+				{ID: 2, StopPrice: 10},
+				{ID: 1, StopPrice: 10},
+				{ID: 3, StopPrice: 10},
+			},
+			price:     10,
+			triggered: []int64{1, 2, 3},
+		},
+		{
+			name:       "Nothing triggered",
+			comparator: BuyStopComparator,
+			orders: []*models.Order{
+				{ID: 1, StopPrice: 10},
+				{ID: 1, StopPrice: 10},
+			},
+			price:  9,
+			remain: []int64{1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			book := NewStopBook(test.comparator)
+			for _, order := range test.orders {
+				book.AddOrder(order)
+			}
+
+			var triggered []int64
+			for _, order := range book.Trigger(test.price) {
+				triggered = append(triggered, order.ID)
+			}
+			assert.Equal(t, test.triggered, triggered)
+			assert.Equal(t, test.remain, stopOrderIDs(book))
+		})
+	}
+}
+
+func TestStopBookRemoveOrder(t *testing.T) {
+	book := NewStopBook(BuyStopComparator)
+	book.AddOrder(&models.Order{ID: 1, StopPrice: 10})
+	book.AddOrder(&models.Order{ID: 2, StopPrice: 11})
+	book.AddOrder(&models.Order{ID: 3, StopPrice: 10})
+	book.AddOrder(&models.Order{ID: 4, StopPrice: 10})
+	book.RemoveOrder(3)
+	book.RemoveOrder(5)
+	assert.Equal(t, []int64{1, 4, 2}, stopOrderIDs(book))
+	assert.Nil(t, book.Get(3))
+	assert.Equal(t, int64(4), book.Get(4).ID)
+}
+
+func TestStopBookGetTriggered(t *testing.T) {
+	book := NewStopBook(SellStopComparator)
+	book.AddOrder(&models.Order{ID: 1, StopPrice: 10})
+	book.AddOrder(&models.Order{ID: 2, StopPrice: 8})
+	book.Trigger(9)
+	assert.Nil(t, book.Get(1))
+	assert.Equal(t, int64(2), book.Get(2).ID)
+
+	book.AddOrder(&models.Order{ID: 1, StopPrice: 10})
+	assert.Equal(t, []int64{1, 2}, stopOrderIDs(book))
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"dealer/internal/dao"
+	"dealer/internal/logger"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExpirySweeperInterface interface {
+	Run(context.Context)
+	Sweep(context.Context, time.Time) error
+}
+
+// ExpirySweeper cancels Good-Til-Date orders whose expiry time has passed,
+// and resumes the markets whose halt cooldown has passed. The messages go
+// through the order processor so the dealer applies them in queue order.
+type ExpirySweeper struct {
+	interval       time.Duration
+	db             *gorm.DB
+	orderDAO       dao.OrderInterface
+	marketStateDAO dao.MarketStateInterface
+	orderProcessor OrderProcessorInterface
+}
+
+var _ ExpirySweeperInterface = (*ExpirySweeper)(nil)
+
+func NewExpirySweeper(interval time.Duration, db *gorm.DB, orderDAO dao.OrderInterface, marketStateDAO dao.MarketStateInterface, orderProcessor OrderProcessorInterface) *ExpirySweeper {
+	return &ExpirySweeper{
+		interval:       interval,
+		db:             db,
+		orderDAO:       orderDAO,
+		marketStateDAO: marketStateDAO,
+		orderProcessor: orderProcessor,
+	}
+}
+
+func (s *ExpirySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.Sweep(ctx, now); err != nil {
+				logger.GetLogger().Error(err.Error())
+			}
+		}
+	}
+}
+
+// Sweep cancels the expired orders and resumes the markets. A failure does
+// not hold back the rest of the sweep, and the first error is returned.
+func (s *ExpirySweeper) Sweep(ctx context.Context, now time.Time) error {
+	cancelErr := s.cancelExpired(ctx, now)
+	if err := s.resumeMarkets(ctx, now); err != nil && cancelErr == nil {
+		return err
+	}
+
+	return cancelErr
+}
+
+// cancelExpired skips the orders closed since they were listed.
+func (s *ExpirySweeper) cancelExpired(ctx context.Context, now time.Time) error {
+	orders, err := s.orderDAO.ListExpired(ctx, s.db, now)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, order := range orders {
+		err := s.orderProcessor.CancelOrder(ctx, order.UserID, order.Symbol, order.ID)
+		if err == nil || errors.Is(err, ErrOrderClosed) || errors.Is(err, ErrOrderNotFound) {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (s *ExpirySweeper) resumeMarkets(ctx context.Context, now time.Time) error {
+	states, err := s.marketStateDAO.ListResumable(ctx, s.db, now)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, state := range states {
+		if err := s.orderProcessor.ResumeMarket(ctx, state.Symbol, state.ResumeAt); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockDAO "dealer/internal/mock/dao"
+	mockService "dealer/internal/mock/service"
+	"dealer/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type ExpirySweeperTestSuite struct {
+	suite.Suite
+	ctrl               *gomock.Controller
+	db                 *sql.DB
+	mockDB             sqlmock.Sqlmock
+	mockGormDB         *gorm.DB
+	mockOrderDAO       *mockDAO.MockOrderInterface
+	mockStateDAO       *mockDAO.MockMarketStateInterface
+	mockOrderProcessor *mockService.MockOrderProcessorInterface
+	svc                *ExpirySweeper
+}
+
+func (t *ExpirySweeperTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockOrderDAO = mockDAO.NewMockOrderInterface(t.ctrl)
+	t.mockStateDAO = mockDAO.NewMockMarketStateInterface(t.ctrl)
+	t.mockOrderProcessor = mockService.NewMockOrderProcessorInterface(t.ctrl)
+	t.svc = NewExpirySweeper(time.Second, t.mockGormDB, t.mockOrderDAO, t.mockStateDAO, t.mockOrderProcessor)
+}
+
+func (t *ExpirySweeperTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestExpirySweeperTestSuite(t *testing.T) {
+	suite.Run(t, new(ExpirySweeperTestSuite))
+}
+
+func (t *ExpirySweeperTestSuite) TestSweep() {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		fn       func()
+		hasError bool
+	}{
+		{
+			name: "Sweep expired orders",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return([]*models.Order{{ID: 1, UserID: 3, Symbol: "BTCUSD"}, {ID: 2, UserID: 4, Symbol: "ETHUSD"}}, nil)
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(3), "BTCUSD", int64(1)).Return(nil)
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(4), "ETHUSD", int64(2)).Return(nil)
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return(nil, nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Sweep no expired order",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return(nil, nil)
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return(nil, nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Sweep halted markets",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return(nil, nil)
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return([]*models.MarketState{{Symbol: "BTCUSD", Phase: models.TradingPhaseHalted, ResumeAt: &now}}, nil)
+				t.mockOrderProcessor.EXPECT().ResumeMarket(context.Background(), "BTCUSD", &now).Return(nil)
+			},
+			hasError: false,
+		},
+		{
+			name: "Sweep list halted markets failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return(nil, nil)
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return(nil, errors.New(""))
+			},
+			hasError: true,
+		},
+		{
+			name: "Sweep list expired orders failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return(nil, errors.New(""))
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return([]*models.MarketState{{Symbol: "BTCUSD", Phase: models.TradingPhaseHalted, ResumeAt: &now}}, nil)
+				t.mockOrderProcessor.EXPECT().ResumeMarket(context.Background(), "BTCUSD", &now).Return(nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Sweep cancel order failed",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return([]*models.Order{{ID: 1, UserID: 3, Symbol: "BTCUSD"}, {ID: 2, UserID: 4, Symbol: "ETHUSD"}}, nil)
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(3), "BTCUSD", int64(1)).Return(errors.New(""))
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(4), "ETHUSD", int64(2)).Return(nil)
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return([]*models.MarketState{{Symbol: "BTCUSD", Phase: models.TradingPhaseHalted, ResumeAt: &now}}, nil)
+				t.mockOrderProcessor.EXPECT().ResumeMarket(context.Background(), "BTCUSD", &now).Return(nil)
+			},
+			hasError: true,
+		},
+		{
+			name: "Sweep skips closed orders",
+			fn: func() {
+				t.mockOrderDAO.EXPECT().
+					ListExpired(context.Background(), t.mockGormDB, now).
+					Return([]*models.Order{{ID: 1, UserID: 3, Symbol: "BTCUSD"}, {ID: 2, UserID: 4, Symbol: "ETHUSD"}, {ID: 3, UserID: 5, Symbol: "BTCUSD"}}, nil)
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(3), "BTCUSD", int64(1)).Return(ErrOrderClosed)
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(4), "ETHUSD", int64(2)).Return(ErrOrderNotFound)
+				t.mockOrderProcessor.EXPECT().CancelOrder(context.Background(), int64(5), "BTCUSD", int64(3)).Return(nil)
+				t.mockStateDAO.EXPECT().
+					ListResumable(context.Background(), t.mockGormDB, now).
+					Return([]*models.MarketState{{Symbol: "BTCUSD", Phase: models.TradingPhaseHalted, ResumeAt: &now}}, nil)
+				t.mockOrderProcessor.EXPECT().ResumeMarket(context.Background(), "BTCUSD", &now).Return(nil)
+			},
+			hasError: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			err := t.svc.Sweep(context.Background(), now)
+			t.Equal(test.hasError, err != nil)
+		})
+	}
+}
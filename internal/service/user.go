@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"dealer/internal/dao"
+	"dealer/internal/models"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserServiceInterface interface {
+	Create(ctx context.Context, name string, admin bool) (*models.User, error)
+	Authenticate(ctx context.Context, req *models.SignedRequest, now time.Time) (*models.User, error)
+}
+
+// UserService issues API keys and authenticates the requests signed with
+// them. A request is signed with the hex encoded HMAC-SHA256 of its
+// timestamp, method, path and body under the API secret, and it is accepted
+// only within window of its timestamp.
+type UserService struct {
+	db      *gorm.DB
+	userDAO dao.UserInterface
+	window  time.Duration
+}
+
+var _ UserServiceInterface = (*UserService)(nil)
+
+func NewUserService(db *gorm.DB, userDAO dao.UserInterface, window time.Duration) *UserService {
+	return &UserService{
+		db:      db,
+		userDAO: userDAO,
+		window:  window,
+	}
+}
+
+// Create stores a user with a new random API key and secret.
+func (s *UserService) Create(ctx context.Context, name string, admin bool) (*models.User, error) {
+	apiKey, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	apiSecret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Name: name, APIKey: apiKey, APISecret: apiSecret, IsAdmin: admin}
+	if err := s.userDAO.Insert(ctx, s.db, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) Authenticate(ctx context.Context, req *models.SignedRequest, now time.Time) (*models.User, error) {
+	timestamp := time.UnixMilli(req.Timestamp)
+	if timestamp.Before(now.Add(-s.window)) || timestamp.After(now.Add(s.window)) {
+		return nil, ErrRequestExpired
+	}
+
+	user, err := s.userDAO.GetByAPIKey(ctx, s.db, req.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUnauthorized
+	}
+
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil || !hmac.Equal(signature, Sign(user.APISecret, req)) {
+		return nil, ErrUnauthorized
+	}
+
+	return user, nil
+}
+
+// Sign returns the signature of the request under the secret. The signed string
+// is the timestamp, the method, the path with the query string and the body
+// joined by newlines.
+func Sign(secret string, req *models.SignedRequest) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(req.Timestamp, 10) + "\n"))
+	mac.Write([]byte(req.Method + "\n"))
+	mac.Write([]byte(req.Path + "\n"))
+	mac.Write(req.Body)
+	return mac.Sum(nil)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
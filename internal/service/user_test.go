@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	mockDAO "dealer/internal/mock/dao"
+	"dealer/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type UserServiceTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	db          *sql.DB
+	mockDB      sqlmock.Sqlmock
+	mockGormDB  *gorm.DB
+	mockUserDAO *mockDAO.MockUserInterface
+	svc         *UserService
+}
+
+func (t *UserServiceTestSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	var err error
+	t.db, t.mockDB, err = sqlmock.New()
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockGormDB, err = gorm.Open(mysql.New(mysql.Config{
+		Conn:                      t.db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+
+	if err != nil {
+		t.Failf("err", "an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	t.mockUserDAO = mockDAO.NewMockUserInterface(t.ctrl)
+	t.svc = NewUserService(t.mockGormDB, t.mockUserDAO, 5*time.Second)
+}
+
+func (t *UserServiceTestSuite) TearDownTest() {
+	t.ctrl.Finish()
+	t.db.Close()
+}
+
+func TestUserServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UserServiceTestSuite))
+}
+
+func (t *UserServiceTestSuite) TestCreate() {
+	t.mockUserDAO.EXPECT().
+		Insert(context.Background(), t.mockGormDB, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *gorm.DB, user *models.User) error {
+			user.ID = 1
+			return nil
+		})
+	user, err := t.svc.Create(context.Background(), "alice", true)
+	t.NoError(err)
+	t.Equal(int64(1), user.ID)
+	t.Equal("alice", user.Name)
+	t.True(user.IsAdmin)
+	t.Len(user.APIKey, 32)
+	t.Len(user.APISecret, 64)
+
+	t.mockUserDAO.EXPECT().Insert(context.Background(), t.mockGormDB, gomock.Any()).Return(errors.New(""))
+	_, err = t.svc.Create(context.Background(), "bob", false)
+	t.Error(err)
+}
+
+func (t *UserServiceTestSuite) TestAuthenticate() {
+	errSQL := errors.New("")
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	user := &models.User{ID: 1, APIKey: "key", APISecret: "secret"}
+	signed := func(req *models.SignedRequest) *models.SignedRequest {
+		req.Signature = hex.EncodeToString(Sign("secret", req))
+		return req
+	}
+	tests := []struct {
+		name     string
+		req      *models.SignedRequest
+		fn       func()
+		expected *models.User
+		err      error
+	}{
+		{
+			name: "Authenticate success",
+			req:  signed(&models.SignedRequest{APIKey: "key", Timestamp: now.UnixMilli(), Method: "POST", Path: "/v1/order", Body: []byte(`{}`)}),
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "key").Return(user, nil)
+			},
+			expected: user,
+			err:      nil,
+		},
+		{
+			name: "Authenticate wrong signature",
+			req:  &models.SignedRequest{APIKey: "key", Timestamp: now.UnixMilli(), Method: "POST", Path: "/v1/order", Signature: "00"},
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "key").Return(user, nil)
+			},
+			expected: nil,
+			err:      ErrUnauthorized,
+		},
+		{
+			name: "Authenticate signature not hex",
+			req:  &models.SignedRequest{APIKey: "key", Timestamp: now.UnixMilli(), Signature: "zz"},
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "key").Return(user, nil)
+			},
+			expected: nil,
+			err:      ErrUnauthorized,
+		},
+		{
+			name: "Authenticate tampered body",
+			req: func() *models.SignedRequest {
+				req := signed(&models.SignedRequest{APIKey: "key", Timestamp: now.UnixMilli(), Method: "POST", Path: "/v1/order", Body: []byte(`{}`)})
+				req.Body = []byte(`{"quantity":1}`)
+				return req
+			}(),
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "key").Return(user, nil)
+			},
+			expected: nil,
+			err:      ErrUnauthorized,
+		},
+		{
+			name: "Authenticate moved field boundary",
+			req: func() *models.SignedRequest {
+				req := signed(&models.SignedRequest{APIKey: "key", Timestamp: now.UnixMilli(), Method: "POST", Path: "/v1/order", Body: []byte(`{}`)})
+				req.Method = "POS"
+				req.Path = "T/v1/order"
+				return req
+			}(),
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "key").Return(user, nil)
+			},
+			expected: nil,
+			err:      ErrUnauthorized,
+		},
+		{
+			name: "Authenticate unknown API key",
+			req:  signed(&models.SignedRequest{APIKey: "unknown", Timestamp: now.UnixMilli()}),
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "unknown").Return(nil, nil)
+			},
+			expected: nil,
+			err:      ErrUnauthorized,
+		},
+		{
+			name:     "Authenticate expired timestamp",
+			req:      signed(&models.SignedRequest{APIKey: "key", Timestamp: now.Add(-6 * time.Second).UnixMilli()}),
+			fn:       func() {},
+			expected: nil,
+			err:      ErrRequestExpired,
+		},
+		{
+			name:     "Authenticate future timestamp",
+			req:      signed(&models.SignedRequest{APIKey: "key", Timestamp: now.Add(6 * time.Second).UnixMilli()}),
+			fn:       func() {},
+			expected: nil,
+			err:      ErrRequestExpired,
+		},
+		{
+			name: "Authenticate get user failed",
+			req:  signed(&models.SignedRequest{APIKey: "key", Timestamp: now.UnixMilli()}),
+			fn: func() {
+				t.mockUserDAO.EXPECT().GetByAPIKey(context.Background(), t.mockGormDB, "key").Return(nil, errSQL)
+			},
+			expected: nil,
+			err:      errSQL,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func() {
+			test.fn()
+			actual, err := t.svc.Authenticate(context.Background(), test.req, now)
+			t.Equal(test.err, err)
+			t.Equal(test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"time"
+)
+
+func ValidateOrder(instrument *models.Instrument, order *models.Order, now time.Time) error {
+	switch order.OrderType {
+	case models.OrderTypeBuy, models.OrderTypeSell:
+	default:
+		return ErrInvalidOrderType
+	}
+
+	lotSize := instrument.LotSize
+	if lotSize == 0 {
+		lotSize = 1
+	}
+	if order.Quantity == 0 || order.Quantity%lotSize != 0 || !isValidQuantity(instrument, order.Quantity) {
+		return ErrInvalidQuantity
+	}
+
+	switch order.PriceType {
+	case models.PriceTypeLimit, models.PriceTypeStopLimit:
+		if !isValidPrice(instrument, order.Price) {
+			return ErrInvalidPrice
+		}
+	case models.PriceTypeMarket, models.PriceTypeStopMarket:
+	default:
+		return ErrInvalidPriceType
+	}
+
+	if order.IsStop() && !isValidPrice(instrument, order.StopPrice) {
+		return ErrInvalidStopPrice
+	}
+
+	// A market buy has no price to bound its cost, so it spends at most the
+	// budget it locks.
+	if isMarketBuy(order) {
+		if order.Budget <= 0 || instrument.MaxPrice != 0 && order.Budget > notional(instrument.MaxPrice, order.Quantity) {
+			return ErrInvalidBudget
+		}
+	} else if order.Budget != 0 {
+		return ErrInvalidBudget
+	}
+
+	switch order.TimeInForce {
+	case models.TimeInForceGTC, models.TimeInForceIOC, models.TimeInForceFOK:
+	case models.TimeInForceGTD:
+		if order.ExpireAt == nil || !order.ExpireAt.After(now) {
+			return ErrInvalidExpireAt
+		}
+	default:
+		return ErrInvalidTimeInForce
+	}
+
+	switch order.SelfTradePrevention {
+	case models.SelfTradePreventionCancelNewest, models.SelfTradePreventionCancelOldest,
+		models.SelfTradePreventionCancelBoth, models.SelfTradePreventionDecrementAndCancel:
+	default:
+		return ErrInvalidSelfTradePrevention
+	}
+
+	if order.IsIceberg() && !isValidDisplayQuantity(order, lotSize) {
+		return ErrInvalidDisplayQuantity
+	}
+
+	if order.PostOnly != 0 && !isValidPostOnly(order) {
+		return ErrInvalidPostOnly
+	}
+
+	if order.MinQuantity != 0 && (order.MinQuantity%lotSize != 0 || order.MinQuantity > order.Quantity || order.PostOnly != 0) {
+		return ErrInvalidMinQuantity
+	}
+
+	return nil
+}
+
+// ValidateAmendment checks the fields set by the amendment, a zero price or
+// quantity keeps the current value of the order.
+func ValidateAmendment(instrument *models.Instrument, amendment *models.Amendment) error {
+	if amendment.Price == 0 && amendment.Quantity == 0 {
+		return ErrEmptyAmendment
+	}
+
+	if amendment.Price != 0 && !isValidPrice(instrument, amendment.Price) {
+		return ErrInvalidPrice
+	}
+
+	lotSize := instrument.LotSize
+	if lotSize == 0 {
+		lotSize = 1
+	}
+	if amendment.Quantity%lotSize != 0 || !isValidQuantity(instrument, amendment.Quantity) {
+		return ErrInvalidQuantity
+	}
+
+	return nil
+}
+
+// isValidDisplayQuantity reports whether an iceberg order can rest in the
+// book with slices of its display quantity.
+func isValidDisplayQuantity(order *models.Order, lotSize uint) bool {
+	if order.MatchPriceType() != models.PriceTypeLimit {
+		return false
+	}
+	if order.TimeInForce != models.TimeInForceGTC && order.TimeInForce != models.TimeInForceGTD {
+		return false
+	}
+
+	return order.DisplayQuantity%lotSize == 0 && order.DisplayQuantity < order.Quantity
+}
+
+// isValidPostOnly reports whether a post-only order has a known mode and can
+// rest in the book.
+func isValidPostOnly(order *models.Order) bool {
+	if order.PostOnly != models.PostOnlyReject && order.PostOnly != models.PostOnlyReprice {
+		return false
+	}
+
+	return order.MatchPriceType() == models.PriceTypeLimit &&
+		(order.TimeInForce == models.TimeInForceGTC || order.TimeInForce == models.TimeInForceGTD)
+}
+
+func isMarketBuy(order *models.Order) bool {
+	return order.OrderType == models.OrderTypeBuy && order.MatchPriceType() == models.PriceTypeMarket
+}
+
+func isValidPrice(instrument *models.Instrument, price models.Decimal) bool {
+	return price > 0 && (instrument.TickSize <= 0 || price%instrument.TickSize == 0) &&
+		(instrument.MaxPrice == 0 || price <= instrument.MaxPrice)
+}
+
+func isValidQuantity(instrument *models.Instrument, quantity uint) bool {
+	return instrument.MaxQuantity == 0 || quantity <= instrument.MaxQuantity
+}
@@ -0,0 +1,511 @@
+package service
+
+import (
+	"dealer/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOrder(t *testing.T) {
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	instrument := &models.Instrument{
+		Symbol:      "BTCUSD",
+		TickSize:    1000000,
+		LotSize:     5,
+		MaxPrice:    models.NewDecimalFromInt(1000),
+		MaxQuantity: 1000,
+	}
+
+	tests := []struct {
+		name     string
+		order    *models.Order
+		expected error
+	}{
+		{
+			name: "Valid limit order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            10,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid market order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeSell,
+				Quantity:            5,
+				PriceType:           models.PriceTypeMarket,
+				TimeInForce:         models.TimeInForceIOC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid stop limit order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            5,
+				PriceType:           models.PriceTypeStopLimit,
+				Price:               12000000,
+				StopPrice:           11000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+			},
+			expected: nil,
+		},
+		{
+			name: "Stop market order without stop price",
+			order: &models.Order{
+				OrderType:   models.OrderTypeSell,
+				Quantity:    5,
+				PriceType:   models.PriceTypeStopMarket,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidStopPrice,
+		},
+		{
+			name: "Stop price not a multiple of tick size",
+			order: &models.Order{
+				OrderType:   models.OrderTypeSell,
+				Quantity:    5,
+				PriceType:   models.PriceTypeStopMarket,
+				StopPrice:   11500000,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidStopPrice,
+		},
+		{
+			name: "Stop limit order without price",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeStopLimit,
+				StopPrice:   11000000,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidPrice,
+		},
+		{
+			name: "Invalid order type",
+			order: &models.Order{
+				Quantity:    5,
+				PriceType:   models.PriceTypeMarket,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidOrderType,
+		},
+		{
+			name: "Quantity zero",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				PriceType:   models.PriceTypeMarket,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidQuantity,
+		},
+		{
+			name: "Quantity not multiple of lot size",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    7,
+				PriceType:   models.PriceTypeMarket,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidQuantity,
+		},
+		{
+			name: "Price not multiple of tick size",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeLimit,
+				Price:       12500000,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidPrice,
+		},
+		{
+			name: "Price above max price",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeLimit,
+				Price:       models.NewDecimalFromInt(1001),
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidPrice,
+		},
+		{
+			name: "Stop price above max price",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeStopMarket,
+				StopPrice:   models.NewDecimalFromInt(1001),
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidStopPrice,
+		},
+		{
+			name: "Valid stop market buy",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            5,
+				PriceType:           models.PriceTypeStopMarket,
+				StopPrice:           11000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				Budget:              models.NewDecimalFromInt(5000),
+			},
+			expected: nil,
+		},
+		{
+			name: "Market buy without budget",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeMarket,
+				TimeInForce: models.TimeInForceIOC,
+			},
+			expected: ErrInvalidBudget,
+		},
+		{
+			name: "Market buy budget above max price times quantity",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeMarket,
+				TimeInForce: models.TimeInForceIOC,
+				Budget:      models.NewDecimalFromInt(5001),
+			},
+			expected: ErrInvalidBudget,
+		},
+		{
+			name: "Limit order with budget",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeLimit,
+				Price:       12000000,
+				TimeInForce: models.TimeInForceGTC,
+				Budget:      models.NewDecimalFromInt(100),
+			},
+			expected: ErrInvalidBudget,
+		},
+		{
+			name: "Quantity above max quantity",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    1005,
+				PriceType:   models.PriceTypeLimit,
+				Price:       12000000,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidQuantity,
+		},
+		{
+			name: "Price zero",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeLimit,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidPrice,
+		},
+		{
+			name: "Invalid price type",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidPriceType,
+		},
+		{
+			name: "Valid good til date",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            5,
+				PriceType:           models.PriceTypeMarket,
+				TimeInForce:         models.TimeInForceGTD,
+				ExpireAt:            &future,
+				Budget:              models.NewDecimalFromInt(100),
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+			},
+			expected: nil,
+		},
+		{
+			name: "Good til date already expired",
+			order: &models.Order{
+				OrderType:   models.OrderTypeBuy,
+				Quantity:    5,
+				PriceType:   models.PriceTypeMarket,
+				TimeInForce: models.TimeInForceGTD,
+				ExpireAt:    &now,
+				Budget:      models.NewDecimalFromInt(100),
+			},
+			expected: ErrInvalidExpireAt,
+		},
+		{
+			name: "Invalid time in force",
+			order: &models.Order{
+				OrderType: models.OrderTypeBuy,
+				Quantity:  5,
+				PriceType: models.PriceTypeMarket,
+				Budget:    models.NewDecimalFromInt(100),
+			},
+			expected: ErrInvalidTimeInForce,
+		},
+		{
+			name: "Valid decrement and cancel",
+			order: &models.Order{
+				OrderType:           models.OrderTypeSell,
+				Quantity:            5,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionDecrementAndCancel,
+			},
+			expected: nil,
+		},
+		{
+			name: "Valid iceberg order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				DisplayQuantity:     5,
+			},
+			expected: nil,
+		},
+		{
+			name: "Display quantity not less than quantity",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				DisplayQuantity:     20,
+			},
+			expected: ErrInvalidDisplayQuantity,
+		},
+		{
+			name: "Display quantity not multiple of lot size",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				DisplayQuantity:     7,
+			},
+			expected: ErrInvalidDisplayQuantity,
+		},
+		{
+			name: "Iceberg market order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeSell,
+				Quantity:            20,
+				PriceType:           models.PriceTypeMarket,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				DisplayQuantity:     5,
+			},
+			expected: ErrInvalidDisplayQuantity,
+		},
+		{
+			name: "Iceberg IOC order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeSell,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceIOC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				DisplayQuantity:     5,
+			},
+			expected: ErrInvalidDisplayQuantity,
+		},
+		{
+			name: "Valid post-only order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				PostOnly:            models.PostOnlyReprice,
+			},
+			expected: nil,
+		},
+		{
+			name: "Invalid post-only mode",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				PostOnly:            3,
+			},
+			expected: ErrInvalidPostOnly,
+		},
+		{
+			name: "Post-only IOC order",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceIOC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				PostOnly:            models.PostOnlyReject,
+			},
+			expected: ErrInvalidPostOnly,
+		},
+		{
+			name: "Valid minimum quantity",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				MinQuantity:         10,
+			},
+			expected: nil,
+		},
+		{
+			name: "Minimum quantity greater than quantity",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				MinQuantity:         25,
+			},
+			expected: ErrInvalidMinQuantity,
+		},
+		{
+			name: "Minimum quantity not multiple of lot size",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				MinQuantity:         7,
+			},
+			expected: ErrInvalidMinQuantity,
+		},
+		{
+			name: "Minimum quantity with post-only",
+			order: &models.Order{
+				OrderType:           models.OrderTypeBuy,
+				Quantity:            20,
+				PriceType:           models.PriceTypeLimit,
+				Price:               12000000,
+				TimeInForce:         models.TimeInForceGTC,
+				SelfTradePrevention: models.SelfTradePreventionCancelNewest,
+				PostOnly:            models.PostOnlyReject,
+				MinQuantity:         10,
+			},
+			expected: ErrInvalidMinQuantity,
+		},
+		{
+			name: "Invalid self-trade prevention",
+			order: &models.Order{
+				OrderType:   models.OrderTypeSell,
+				Quantity:    5,
+				PriceType:   models.PriceTypeLimit,
+				Price:       12000000,
+				TimeInForce: models.TimeInForceGTC,
+			},
+			expected: ErrInvalidSelfTradePrevention,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ValidateOrder(instrument, test.order, now))
+		})
+	}
+}
+
+func TestValidateAmendment(t *testing.T) {
+	instrument := &models.Instrument{
+		Symbol:      "BTCUSD",
+		TickSize:    1000000,
+		LotSize:     5,
+		MaxPrice:    models.NewDecimalFromInt(1000),
+		MaxQuantity: 1000,
+	}
+
+	tests := []struct {
+		name      string
+		amendment *models.Amendment
+		expected  error
+	}{
+		{
+			name:      "Valid price amendment",
+			amendment: &models.Amendment{Price: 12000000},
+			expected:  nil,
+		},
+		{
+			name:      "Valid quantity amendment",
+			amendment: &models.Amendment{Quantity: 10},
+			expected:  nil,
+		},
+		{
+			name:      "Empty amendment",
+			amendment: &models.Amendment{},
+			expected:  ErrEmptyAmendment,
+		},
+		{
+			name:      "Price not a multiple of tick size",
+			amendment: &models.Amendment{Price: 12500000},
+			expected:  ErrInvalidPrice,
+		},
+		{
+			name:      "Quantity not a multiple of lot size",
+			amendment: &models.Amendment{Quantity: 7},
+			expected:  ErrInvalidQuantity,
+		},
+		{
+			name:      "Price above max price",
+			amendment: &models.Amendment{Price: models.NewDecimalFromInt(1001)},
+			expected:  ErrInvalidPrice,
+		},
+		{
+			name:      "Quantity above max quantity",
+			amendment: &models.Amendment{Quantity: 1005},
+			expected:  ErrInvalidQuantity,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ValidateAmendment(instrument, test.amendment))
+		})
+	}
+}
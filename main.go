@@ -6,9 +6,11 @@ import (
 	"dealer/internal/dao"
 	"dealer/internal/handler"
 	"dealer/internal/models"
-	"dealer/internal/sdk"
 	"dealer/internal/service"
-	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"time"
 
 	"dealer/internal/logger"
 	"fmt"
@@ -16,6 +18,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// defaultMaxPrice is the max price of the orders of an instrument without
+// one.
+var defaultMaxPrice = models.NewDecimalFromInt(1000000)
+
 func main() {
 	config, err := configmanager.Get()
 	if err != nil {
@@ -27,25 +33,127 @@ func main() {
 	l := logger.GetLogger()
 	defer l.Sync()
 
-	db, err := newMySQL(config.Database)
+	conn, err := newMessageQueue(config.MessageQueue)
+	if err != nil {
+		panic(err)
+	}
+
+	publishChannel, err := newPublishChannel(conn)
 	if err != nil {
 		panic(err)
 	}
 
-	ch, err := newMessageQueue(config.MessageQueue)
+	db, err := newMySQL(config.Database)
 	if err != nil {
 		panic(err)
 	}
 
 	orderDAO := dao.NewOrder()
 	dealDAO := dao.NewDeal()
-	orderProcessor := service.NewOrderProcessor(ch, config.MessageQueue.QueueName, db, orderDAO)
-	dealer := service.NewDealer(db, orderDAO, dealDAO)
-	h := handler.NewHandler(orderProcessor)
+	amendmentDAO := dao.NewAmendment()
+	cancellationDAO := dao.NewCancellation()
+	candleDAO := dao.NewCandle()
+	marketStateDAO := dao.NewMarketState()
+	outboxDAO := dao.NewOutbox()
+	userDAO := dao.NewUser()
+	balanceDAO := dao.NewBalance()
+	users := service.NewUserService(db, userDAO, config.Auth.Window)
+	ledger := service.NewLedger(db, balanceDAO, dao.NewLedger())
+
+	if len(os.Args) > 1 {
+		deadLetter := service.NewDeadLetter(publishChannel, config.MessageQueue.QueueName, config.MessageQueue.DeadLetterQueue)
+		candleBackfill := service.NewCandleBackfill(db, dealDAO, candleDAO, config.Candle.BackfillBatchSize)
+		if err := runAdmin(os.Args[1:], deadLetter, candleBackfill, users, ledger); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	var instruments []*models.Instrument
+	for _, instrument := range config.Instruments {
+		tickSize, err := models.ParseDecimal(instrument.TickSize)
+		if err != nil {
+			panic(fmt.Errorf("invalid tick size of %s: %w", instrument.Symbol, err))
+		}
+
+		var referencePrice, maxSlippage models.Decimal
+		if instrument.ReferencePrice != "" {
+			if referencePrice, err = models.ParseDecimal(instrument.ReferencePrice); err != nil || referencePrice < 0 {
+				panic(fmt.Errorf("invalid reference price of %s: %q", instrument.Symbol, instrument.ReferencePrice))
+			}
+		}
+		if instrument.MaxSlippage != "" {
+			if maxSlippage, err = models.ParseDecimal(instrument.MaxSlippage); err != nil || maxSlippage < 0 || maxSlippage >= models.DecimalScale {
+				panic(fmt.Errorf("invalid max slippage of %s: %q", instrument.Symbol, instrument.MaxSlippage))
+			}
+		}
+
+		priceBand, err := newPriceBand(instrument.PriceBand)
+		if err != nil {
+			panic(fmt.Errorf("invalid price band of %s: %w", instrument.Symbol, err))
+		}
 
-	if err := startConsumer(ch, config.MessageQueue.QueueName, dealer); err != nil {
+		maxPrice, maxQuantity, err := newOrderLimits(instrument)
+		if err != nil {
+			panic(fmt.Errorf("invalid order limits of %s: %w", instrument.Symbol, err))
+		}
+		if referencePrice > maxPrice {
+			panic(fmt.Errorf("reference price of %s is above the max price", instrument.Symbol))
+		}
+
+		instruments = append(instruments, &models.Instrument{
+			Symbol:         instrument.Symbol,
+			BaseAsset:      instrument.BaseAsset,
+			QuoteAsset:     instrument.QuoteAsset,
+			TickSize:       tickSize,
+			LotSize:        instrument.LotSize,
+			PriceBand:      priceBand,
+			ReferencePrice: referencePrice,
+			MaxSlippage:    maxSlippage,
+			MaxPrice:       maxPrice,
+			MaxQuantity:    maxQuantity,
+		})
+	}
+
+	feeSchedule, err := newFeeSchedule(config.Fee)
+	if err != nil {
+		panic(err)
+	}
+
+	registry := service.NewInstrumentRegistry(instruments)
+	orderProcessor := service.NewOrderProcessor(db, orderDAO, outboxDAO, registry, ledger)
+	relay := service.NewOutboxRelay(config.Outbox.Interval, config.Outbox.BatchSize, publishChannel, config.MessageQueue.QueueName, db, outboxDAO)
+	marketData := service.NewMarketData(registry)
+	fees := service.NewFeeCalculator(db, dealDAO, registry, feeSchedule, config.Fee.RefreshInterval)
+	dealer := service.NewDealer(db, orderDAO, dealDAO, amendmentDAO, cancellationDAO, candleDAO, marketStateDAO, ledger, fees, marketData, config.MarketData.Depth, registry)
+	consumeChannel, err := conn.Channel()
+	if err != nil {
 		panic(err)
 	}
+	consumer := service.NewConsumer(consumeChannel, config.MessageQueue.QueueName, config.Consumer.Prefetch, config.Consumer.MaxAttempts, config.Consumer.Backoff, config.Consumer.MaxBackoff, dealer)
+	sweeper := service.NewExpirySweeper(config.Sweeper.Interval, db, orderDAO, marketStateDAO, orderProcessor)
+	query := service.NewQuery(db, orderDAO, dealDAO, candleDAO, balanceDAO, marketData)
+	h := handler.NewHandler(orderProcessor, query, registry, marketData, users, config.MarketData.BufferSize)
+
+	if err := dealer.Recover(context.Background()); err != nil {
+		panic(err)
+	}
+
+	go func() {
+		if err := consumer.Run(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+	go relay.Run(context.Background())
+	go sweeper.Run(context.Background())
+	if config.Schedule.Enabled {
+		schedule, err := newTradingSchedule(config.Schedule)
+		if err != nil {
+			panic(err)
+		}
+		go service.NewPhaseScheduler(config.Schedule.Interval, schedule, registry, orderProcessor).Run(context.Background())
+	}
 
 	engine := gin.New()
 	handler.RegisterRoutes(engine, h)
@@ -53,26 +161,151 @@ func main() {
 	engine.Run(fmt.Sprintf(":%d", config.HTTPServer.Port))
 }
 
-func startConsumer(ch sdk.AMQPChannel, name string, dealer service.DealerInterface) error {
-	msgs, err := ch.Consume(name, "", true, false, false, false, nil)
+// newFeeSchedule parses the fee rates of the config, where a missing rate is
+// zero, and sorts the tiers by volume. A rate must be below 1, so a fee never
+// takes the whole amount of a deal.
+func newFeeSchedule(config configmanager.FeeConfig) (*models.FeeSchedule, error) {
+	parse := func(name, value string) (models.Decimal, error) {
+		if value == "" {
+			return 0, nil
+		}
+
+		d, err := models.ParseDecimal(value)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid fee %s %q", name, value)
+		}
+		return d, nil
+	}
+	parseRate := func(name, value string) (models.Decimal, error) {
+		d, err := parse(name, value)
+		if err == nil && d >= models.DecimalScale {
+			return 0, fmt.Errorf("invalid fee %s %q", name, value)
+		}
+		return d, err
+	}
+
+	var schedule models.FeeSchedule
+	var err error
+	if schedule.MakerRate, err = parseRate("makerRate", config.MakerRate); err != nil {
+		return nil, err
+	}
+	if schedule.TakerRate, err = parseRate("takerRate", config.TakerRate); err != nil {
+		return nil, err
+	}
+
+	for _, tier := range config.Tiers {
+		var t models.FeeTier
+		if t.Volume, err = parse("tier volume", tier.Volume); err != nil {
+			return nil, err
+		}
+		if t.MakerRate, err = parseRate("tier makerRate", tier.MakerRate); err != nil {
+			return nil, err
+		}
+		if t.TakerRate, err = parseRate("tier takerRate", tier.TakerRate); err != nil {
+			return nil, err
+		}
+		schedule.Tiers = append(schedule.Tiers, t)
+	}
+
+	sort.Slice(schedule.Tiers, func(i, j int) bool {
+		return schedule.Tiers[i].Volume < schedule.Tiers[j].Volume
+	})
+	return &schedule, nil
+}
+
+// newPriceBand parses the bands of an instrument, where a missing band is
+// disabled. The breaker phase is a volatility auction unless it is halted.
+func newPriceBand(config configmanager.PriceBandConfig) (models.PriceBand, error) {
+	band := models.PriceBand{Breaker: models.TradingPhaseVolatilityAuction, Cooldown: config.Cooldown}
+	for _, width := range []struct {
+		name  string
+		value string
+		band  *models.Decimal
+	}{
+		{"static", config.Static, &band.Static},
+		{"dynamic", config.Dynamic, &band.Dynamic},
+	} {
+		if width.value == "" {
+			continue
+		}
+
+		d, err := models.ParseDecimal(width.value)
+		if err != nil || d < 0 || d >= models.DecimalScale {
+			return band, fmt.Errorf("invalid %s band %q", width.name, width.value)
+		}
+		*width.band = d
+	}
+
+	switch models.TradingPhase(config.Breaker) {
+	case "", models.TradingPhaseVolatilityAuction:
+	case models.TradingPhaseHalted:
+		band.Breaker = models.TradingPhaseHalted
+	default:
+		return band, fmt.Errorf("invalid breaker %q", config.Breaker)
+	}
+
+	return band, nil
+}
+
+// newOrderLimits parses the max price and max quantity of the orders of an
+// instrument. The max price defaults to defaultMaxPrice and the max quantity
+// to the most the max price can afford in a Decimal, and their product must
+// fit in a Decimal.
+func newOrderLimits(config configmanager.InstrumentConfig) (models.Decimal, uint, error) {
+	maxPrice := defaultMaxPrice
+	if config.MaxPrice != "" {
+		var err error
+		if maxPrice, err = models.ParseDecimal(config.MaxPrice); err != nil || maxPrice <= 0 {
+			return 0, 0, fmt.Errorf("invalid max price %q", config.MaxPrice)
+		}
+	}
+
+	maxQuantity := config.MaxQuantity
+	if maxQuantity == 0 {
+		maxQuantity = uint(math.MaxInt64 / maxPrice)
+	}
+
+	if _, err := maxPrice.Mul(maxQuantity); err != nil {
+		return 0, 0, fmt.Errorf("max price %s times max quantity %d: %w", maxPrice, maxQuantity, err)
+	}
+
+	return maxPrice, maxQuantity, nil
+}
+
+// newTradingSchedule parses the HH:MM start times of the phases in the
+// location of the config, which must follow the order of a trading day.
+func newTradingSchedule(config configmanager.ScheduleConfig) (*models.TradingSchedule, error) {
+	location, err := time.LoadLocation(config.Location)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid schedule location %q: %w", config.Location, err)
 	}
 
-	go func() {
-		for msg := range msgs {
-			var order *models.Order
-			if err := json.Unmarshal(msg.Body, &order); err != nil {
-				logger.GetLogger().Error(err.Error())
-				continue
-			}
+	var offsets []time.Duration
+	for _, start := range []struct{ name, value string }{
+		{"preOpen", config.PreOpen},
+		{"auction", config.Auction},
+		{"continuous", config.Continuous},
+		{"closingAuction", config.ClosingAuction},
+		{"close", config.Close},
+	} {
+		t, err := time.Parse("15:04", start.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s %q", start.name, start.value)
+		}
 
-			if err := dealer.ProcessOrder(context.Background(), order); err != nil {
-				logger.GetLogger().Error(err.Error())
-				continue
-			}
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if len(offsets) != 0 && offset < offsets[len(offsets)-1] {
+			return nil, fmt.Errorf("schedule %s %q is before the previous phase", start.name, start.value)
 		}
-	}()
+		offsets = append(offsets, offset)
+	}
 
-	return nil
+	return &models.TradingSchedule{
+		Location:       location,
+		PreOpen:        offsets[0],
+		Auction:        offsets[1],
+		Continuous:     offsets[2],
+		ClosingAuction: offsets[3],
+		Close:          offsets[4],
+	}, nil
 }